@@ -45,6 +45,7 @@ func NewConfig(region string) *AWS.Config {
 	return &AWS.Config{
 		Credentials: auth,
 		Region:      region,
+		HTTPClient:  HTTPClient(),
 	}
 }
 
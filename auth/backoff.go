@@ -0,0 +1,126 @@
+// Jittered backoff shared by the retries of all services
+
+package auth
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+const (
+	backoffConfigSectionName = "backoff"
+
+	backoffBaseConfigKey       = "backoff_base_ms"
+	backoffMaxConfigKey        = "backoff_max_ms"
+	backoffMultiplierConfigKey = "backoff_multiplier"
+	backoffJitterConfigKey     = "backoff_jitter"
+
+	defaultBackoffBase       = 100 * time.Millisecond
+	defaultBackoffMultiplier = 2
+)
+
+var (
+	defaultBackoff       *Backoff = nil
+	defaultBackoffLoaded bool
+)
+
+// Backoff is the strategy of the wait time before the retry
+type Backoff struct {
+	// wait time of the first retry, the default is 100ms
+	Base time.Duration
+
+	// upper limit of the wait time, 0 means no limit
+	Max time.Duration
+
+	// growth rate of the wait time on every retry, the default is 2
+	Multiplier float64
+
+	// ratio of the wait time randomly reduced [0, 1], 0 means no jitter
+	Jitter float64
+
+	// Func overrides all of the above parameters when it is set
+	Func func(attempt int) time.Duration
+}
+
+// Duration returns the wait time before the attempt-th retry (starts from 1)
+func (b *Backoff) Duration(attempt int) time.Duration {
+	if b.Func != nil {
+		return b.Func(attempt)
+	}
+
+	wait := b.Base
+	if wait <= 0 {
+		wait = defaultBackoffBase
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultBackoffMultiplier
+	}
+	for i := 1; i < attempt && (b.Max <= 0 || wait < b.Max); i++ {
+		next := time.Duration(float64(wait) * multiplier)
+		if next < wait {
+			// overflow
+			break
+		}
+		wait = next
+	}
+	if b.Max > 0 && wait > b.Max {
+		wait = b.Max
+	}
+
+	jitter := b.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	if jitter > 0 {
+		wait -= time.Duration(float64(wait) * jitter * rand.Float64())
+	}
+	return wait
+}
+
+// DefaultBackoff returns the backoff set by SetDefaultBackoff or the config,
+// returns nil to use the default retry rules of each operation when no parameter is set
+func DefaultBackoff() *Backoff {
+	if defaultBackoffLoaded {
+		return defaultBackoff
+	}
+	defaultBackoffLoaded = true
+
+	base, _ := strconv.Atoi(config.GetConfigValue(backoffConfigSectionName, backoffBaseConfigKey, "0"))
+	max, _ := strconv.Atoi(config.GetConfigValue(backoffConfigSectionName, backoffMaxConfigKey, "0"))
+	multiplier, _ := strconv.ParseFloat(config.GetConfigValue(backoffConfigSectionName, backoffMultiplierConfigKey, "0"), 64)
+	jitter, _ := strconv.ParseFloat(config.GetConfigValue(backoffConfigSectionName, backoffJitterConfigKey, "0"), 64)
+	if base <= 0 && max <= 0 && multiplier <= 0 && jitter <= 0 {
+		return nil
+	}
+
+	defaultBackoff = &Backoff{
+		Base:       time.Duration(base) * time.Millisecond,
+		Max:        time.Duration(max) * time.Millisecond,
+		Multiplier: multiplier,
+		Jitter:     jitter,
+	}
+	return defaultBackoff
+}
+
+// SetDefaultBackoff sets the backoff used by the clients created after this,
+// nil restores the backoff of the config
+func SetDefaultBackoff(b *Backoff) {
+	defaultBackoff = b
+	defaultBackoffLoaded = b != nil
+}
+
+// SetRetryRules sets the backoff to the retry rules of the service client,
+// nil keeps the current rules
+func SetRetryRules(svc *AWS.Service, b *Backoff) {
+	if svc == nil || b == nil {
+		return
+	}
+	svc.RetryRules = func(r *AWS.Request) time.Duration {
+		return b.Duration(int(r.RetryCount) + 1)
+	}
+}
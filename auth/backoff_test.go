@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+func TestBackoffDuration(t *testing.T) {
+	b := &Backoff{Base: 50 * time.Millisecond, Max: time.Second}
+	assert.Equal(t, 50*time.Millisecond, b.Duration(1))
+	assert.Equal(t, 100*time.Millisecond, b.Duration(2))
+	assert.Equal(t, 200*time.Millisecond, b.Duration(3))
+	assert.Equal(t, time.Second, b.Duration(100))
+
+	b = &Backoff{Base: 10 * time.Millisecond, Multiplier: 3}
+	assert.Equal(t, 90*time.Millisecond, b.Duration(3))
+	assert.True(t, b.Duration(1000) > 0)
+
+	// default base
+	b = &Backoff{}
+	assert.Equal(t, defaultBackoffBase, b.Duration(0))
+
+	b = &Backoff{Base: 100 * time.Millisecond, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		d := b.Duration(1)
+		assert.True(t, d > 50*time.Millisecond && d <= 100*time.Millisecond, d)
+	}
+
+	b = &Backoff{
+		Base: time.Hour,
+		Func: func(attempt int) time.Duration {
+			return time.Duration(attempt) * time.Millisecond
+		},
+	}
+	assert.Equal(t, 3*time.Millisecond, b.Duration(3))
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	defer config.SetDefaultConfig()
+	defer SetDefaultBackoff(nil)
+
+	// no config
+	SetDefaultBackoff(nil)
+	assert.Nil(t, DefaultBackoff())
+
+	config.SetConfig(testHTTPConfig{
+		"backoff_base_ms":    "20",
+		"backoff_max_ms":     "3000",
+		"backoff_multiplier": "1.5",
+		"backoff_jitter":     "0.2",
+	})
+	SetDefaultBackoff(nil)
+	b := DefaultBackoff()
+	assert.NotNil(t, b)
+	assert.Equal(t, 20*time.Millisecond, b.Base)
+	assert.Equal(t, 3*time.Second, b.Max)
+	assert.Equal(t, 1.5, b.Multiplier)
+	assert.Equal(t, 0.2, b.Jitter)
+
+	custom := &Backoff{Base: time.Second}
+	SetDefaultBackoff(custom)
+	assert.Equal(t, custom, DefaultBackoff())
+}
+
+func TestSetRetryRules(t *testing.T) {
+	svc := &AWS.Service{}
+	SetRetryRules(svc, nil)
+	assert.Nil(t, svc.RetryRules)
+
+	SetRetryRules(svc, &Backoff{Base: 10 * time.Millisecond})
+	assert.Equal(t, 10*time.Millisecond, svc.RetryRules(&AWS.Request{RetryCount: 0}))
+	assert.Equal(t, 40*time.Millisecond, svc.RetryRules(&AWS.Request{RetryCount: 2}))
+
+	// no panic
+	SetRetryRules(nil, &Backoff{})
+}
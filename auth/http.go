@@ -0,0 +1,54 @@
+// HTTP client for AWS requests
+
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+const (
+	httpConfigSectionName = "http"
+
+	maxIdleConnsConfigKey        = "max_idle_conns"
+	maxIdleConnsPerHostConfigKey = "max_idle_conns_per_host"
+	idleConnTimeoutConfigKey     = "idle_conn_timeout"
+)
+
+var (
+	httpClient       *http.Client = nil
+	httpClientLoaded bool
+)
+
+// HTTPClient returns the HTTP client with the tuned transport from the config,
+// returns nil to use the default client when no parameter is set.
+// the client is shared by all of the services to reuse the connections
+func HTTPClient() *http.Client {
+	if httpClientLoaded {
+		return httpClient
+	}
+	httpClientLoaded = true
+
+	maxIdle, _ := strconv.Atoi(config.GetConfigValue(httpConfigSectionName, maxIdleConnsConfigKey, "0"))
+	maxIdlePerHost, _ := strconv.Atoi(config.GetConfigValue(httpConfigSectionName, maxIdleConnsPerHostConfigKey, "0"))
+	idleTimeout, _ := strconv.Atoi(config.GetConfigValue(httpConfigSectionName, idleConnTimeoutConfigKey, "0"))
+	if maxIdle <= 0 && maxIdlePerHost <= 0 && idleTimeout <= 0 {
+		return nil
+	}
+
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if maxIdle > 0 {
+		tr.MaxIdleConns = maxIdle
+	}
+	if maxIdlePerHost > 0 {
+		tr.MaxIdleConnsPerHost = maxIdlePerHost
+	}
+	if idleTimeout > 0 {
+		tr.IdleConnTimeout = time.Duration(idleTimeout) * time.Second
+	}
+	httpClient = &http.Client{Transport: tr}
+	return httpClient
+}
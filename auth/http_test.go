@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+type testHTTPConfig map[string]string
+
+func (c testHTTPConfig) GetConfigValue(section, key, df string) string {
+	if v, ok := c[key]; ok {
+		return v
+	}
+	return df
+}
+
+func TestHTTPClient(t *testing.T) {
+	defer config.SetDefaultConfig()
+
+	// no config
+	httpClient, httpClientLoaded = nil, false
+	assert.Nil(t, HTTPClient())
+
+	config.SetConfig(testHTTPConfig{
+		"max_idle_conns":          "200",
+		"max_idle_conns_per_host": "50",
+		"idle_conn_timeout":       "30",
+	})
+	httpClient, httpClientLoaded = nil, false
+	c := HTTPClient()
+	assert.NotNil(t, c)
+	tr := c.Transport.(*http.Transport)
+	assert.Equal(t, 200, tr.MaxIdleConns)
+	assert.Equal(t, 50, tr.MaxIdleConnsPerHost)
+	assert.Equal(t, 30*time.Second, tr.IdleConnTimeout)
+
+	// shared client
+	assert.Equal(t, c, HTTPClient())
+	assert.Equal(t, c, NewConfig("region").HTTPClient)
+
+	httpClient, httpClientLoaded = nil, false
+	auth = nil
+}
@@ -0,0 +1,72 @@
+// Client-side rate limiting of AWS requests
+
+package auth
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
+	"golang.org/x/time/rate"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+const rateLimitConfigKey = "rate_limit"
+
+// NewRateLimiter creates the token bucket limiter of opsPerSec requests per second with the burst of opsPerSec,
+// returns nil (no limit) when opsPerSec <= 0
+func NewRateLimiter(opsPerSec int) *rate.Limiter {
+	if opsPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(opsPerSec), opsPerSec)
+}
+
+// ConfigRateLimiter creates the limiter from `rate_limit` (requests per second) of the service section in the config,
+// `rate_limit` of auth section is used when the service section does not have it
+func ConfigRateLimiter(section string) *rate.Limiter {
+	limit := config.GetConfigValue(authConfigSectionName, rateLimitConfigKey, "0")
+	opsPerSec, _ := strconv.Atoi(config.GetConfigValue(section, rateLimitConfigKey, limit))
+	return NewRateLimiter(opsPerSec)
+}
+
+// RateLimiterHolder holds the limiter of the client, the limiter can be replaced while the requests are sent.
+// the zero value holds no limiter
+type RateLimiterHolder struct {
+	mu      sync.RWMutex
+	limiter *rate.Limiter
+}
+
+// Set replaces the limiter, nil means no limit
+func (h *RateLimiterHolder) Set(l *rate.Limiter) {
+	h.mu.Lock()
+	h.limiter = l
+	h.mu.Unlock()
+}
+
+// Get returns the current limiter, use this as getLimiter of AddRateLimiter
+func (h *RateLimiterHolder) Get() *rate.Limiter {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.limiter
+}
+
+// AddRateLimiter waits for the token of the limiter from getLimiter before sending every request (including the retries) of the handlers,
+// the wait is cancelled by the context of the HTTP request. nil limiter means no limit
+func AddRateLimiter(h *AWS.Handlers, getLimiter func() *rate.Limiter) {
+	h.Sign.PushFront(func(r *AWS.Request) {
+		l := getLimiter()
+		if l == nil {
+			return
+		}
+		ctx := context.Background()
+		if r.HTTPRequest != nil {
+			ctx = r.HTTPRequest.Context()
+		}
+		if err := l.Wait(ctx); err != nil {
+			r.Error = err
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(0))
+	assert.Nil(t, NewRateLimiter(-1))
+
+	l := NewRateLimiter(10)
+	assert.Equal(t, rate.Limit(10), l.Limit())
+	assert.Equal(t, 10, l.Burst())
+}
+
+func TestConfigRateLimiter(t *testing.T) {
+	defer config.SetDefaultConfig()
+
+	config.SetConfig(testSectionConfig{})
+	assert.Nil(t, ConfigRateLimiter("dynamodb"))
+
+	config.SetConfig(testSectionConfig{
+		"auth.rate_limit":     "100",
+		"dynamodb.rate_limit": "20",
+	})
+	assert.Equal(t, rate.Limit(20), ConfigRateLimiter("dynamodb").Limit())
+	assert.Equal(t, rate.Limit(100), ConfigRateLimiter("s3").Limit())
+}
+
+func TestAddRateLimiter(t *testing.T) {
+	var l *rate.Limiter
+	h := AWS.Handlers{}
+	AddRateLimiter(&h, func() *rate.Limiter { return l })
+
+	// no limit
+	r := &AWS.Request{}
+	h.Sign.Run(r)
+	assert.Nil(t, r.Error)
+
+	l = rate.NewLimiter(rate.Limit(1), 1)
+	req, _ := http.NewRequest("GET", "http://localhost", nil)
+	r = &AWS.Request{HTTPRequest: req}
+	h.Sign.Run(r)
+	assert.Nil(t, r.Error)
+
+	// the wait for the next token is cancelled by the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = &AWS.Request{HTTPRequest: req.WithContext(ctx)}
+	h.Sign.Run(r)
+	assert.NotNil(t, r.Error)
+}
+
+func TestRateLimiterHolder(t *testing.T) {
+	var holder RateLimiterHolder
+	assert.Nil(t, holder.Get())
+
+	h := AWS.Handlers{}
+	AddRateLimiter(&h, holder.Get)
+
+	// replace the limiter while sending the requests
+	l := rate.NewLimiter(rate.Inf, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			h.Sign.Run(&AWS.Request{})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		holder.Set(l)
+		holder.Set(nil)
+	}
+	<-done
+
+	holder.Set(l)
+	assert.Equal(t, l, holder.Get())
+}
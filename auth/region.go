@@ -0,0 +1,97 @@
+// AWS region resolution
+
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+const (
+	regionConfigKey = "region"
+	defaultProfile  = "default"
+)
+
+// ErrRegionNotFound is returned when the region is not resolved from the config, env vars and the shared config file
+var ErrRegionNotFound = errors.New("[Auth] region is not found in the config, AWS_REGION, AWS_DEFAULT_REGION or the shared config file")
+
+// ResolveRegion resolves the region in the order of
+// 1. `region` of the service section in the config
+// 2. `region` of auth section in the config
+// 3. AWS_REGION env var
+// 4. AWS_DEFAULT_REGION env var
+// 5. `region` of the profile (AWS_PROFILE or default) in the shared config file (AWS_CONFIG_FILE or ~/.aws/config)
+// and returns ErrRegionNotFound when none of them has the region
+func ResolveRegion(section string) (string, error) {
+	region := config.GetConfigValue(authConfigSectionName, regionConfigKey, "")
+	if region = config.GetConfigValue(section, regionConfigKey, region); region != "" {
+		return region, nil
+	}
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(env); region != "" {
+			return region, nil
+		}
+	}
+	if region := sharedConfigRegion(sharedConfigPath(), os.Getenv("AWS_PROFILE")); region != "" {
+		return region, nil
+	}
+	return "", ErrRegionNotFound
+}
+
+// get the path of the shared config file, returns empty string when the home directory is unknown
+func sharedConfigPath() string {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".aws", "config")
+}
+
+// read the region of the profile from the shared config file, empty profile means default profile.
+// the section of the profile is `[default]` or `[profile name]`
+func sharedConfigRegion(path, profile string) string {
+	if path == "" {
+		return ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	if profile == "" {
+		profile = defaultProfile
+	}
+	inProfile := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(strings.TrimPrefix(strings.Trim(line, "[]"), "profile "))
+			inProfile = name == profile
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) == 2 && strings.TrimSpace(kv[0]) == regionConfigKey {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+const testSharedConfig = `# shared config
+[default]
+region = us-west-2
+
+[profile dev]
+output = json
+region=ap-northeast-1
+`
+
+func TestResolveRegion(t *testing.T) {
+	defer config.SetDefaultConfig()
+	os.Clearenv()
+	config.SetConfig(testSectionConfig{})
+
+	_, err := ResolveRegion("dynamodb")
+	assert.Equal(t, ErrRegionNotFound, err)
+
+	dir, err := ioutil.TempDir("", "aws-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(testSharedConfig), 0600))
+
+	os.Setenv("AWS_CONFIG_FILE", path)
+	region, err := ResolveRegion("dynamodb")
+	assert.Nil(t, err)
+	assert.Equal(t, "us-west-2", region)
+
+	os.Setenv("AWS_PROFILE", "dev")
+	region, _ = ResolveRegion("dynamodb")
+	assert.Equal(t, "ap-northeast-1", region)
+
+	os.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	region, _ = ResolveRegion("dynamodb")
+	assert.Equal(t, "eu-west-1", region)
+
+	os.Setenv("AWS_REGION", "eu-central-1")
+	region, _ = ResolveRegion("dynamodb")
+	assert.Equal(t, "eu-central-1", region)
+
+	config.SetConfig(testSectionConfig{
+		"auth.region":     "sa-east-1",
+		"dynamodb.region": "us-east-1",
+	})
+	region, _ = ResolveRegion("dynamodb")
+	assert.Equal(t, "us-east-1", region)
+	region, _ = ResolveRegion("s3")
+	assert.Equal(t, "sa-east-1", region)
+
+	os.Clearenv()
+}
+
+func TestSharedConfigRegion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(testSharedConfig), 0600))
+
+	assert.Equal(t, "us-west-2", sharedConfigRegion(path, ""))
+	assert.Equal(t, "ap-northeast-1", sharedConfigRegion(path, "dev"))
+	assert.Equal(t, "", sharedConfigRegion(path, "prod"))
+	assert.Equal(t, "", sharedConfigRegion(filepath.Join(dir, "non_exist"), ""))
+	assert.Equal(t, "", sharedConfigRegion("", ""))
+}
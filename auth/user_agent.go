@@ -0,0 +1,42 @@
+// User-Agent attribution for AWS requests
+
+package auth
+
+import (
+	"net/http"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+const userAgentSuffixConfigKey = "user_agent_suffix"
+
+// UserAgentSuffix returns the User-Agent suffix from the config of the service section,
+// the suffix of auth section is used when the service section does not have it
+func UserAgentSuffix(section string) string {
+	suffix := config.GetConfigValue(authConfigSectionName, userAgentSuffixConfigKey, "")
+	return config.GetConfigValue(section, userAgentSuffixConfigKey, suffix)
+}
+
+// AddUserAgentSuffix appends the suffix to the User-Agent header on every request of the handlers,
+// the handlers are owned by each service client and the suffix does not affect the other clients
+func AddUserAgentSuffix(h *AWS.Handlers, suffix string) {
+	if suffix == "" {
+		return
+	}
+	h.Build.PushBack(func(r *AWS.Request) {
+		appendUserAgent(r.HTTPRequest, suffix)
+	})
+}
+
+// append the suffix to the User-Agent header separated by a space
+func appendUserAgent(req *http.Request, suffix string) {
+	if req == nil {
+		return
+	}
+	ua := req.Header.Get("User-Agent")
+	if ua != "" {
+		ua += " "
+	}
+	req.Header.Set("User-Agent", ua+suffix)
+}
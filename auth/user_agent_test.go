@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+)
+
+type testSectionConfig map[string]string
+
+func (c testSectionConfig) GetConfigValue(section, key, df string) string {
+	if v, ok := c[section+"."+key]; ok {
+		return v
+	}
+	return df
+}
+
+func TestUserAgentSuffix(t *testing.T) {
+	defer config.SetDefaultConfig()
+
+	config.SetConfig(testSectionConfig{})
+	assert.Equal(t, "", UserAgentSuffix("dynamodb"))
+
+	config.SetConfig(testSectionConfig{
+		"auth.user_agent_suffix":     "my-service",
+		"dynamodb.user_agent_suffix": "my-batch",
+	})
+	assert.Equal(t, "my-batch", UserAgentSuffix("dynamodb"))
+	assert.Equal(t, "my-service", UserAgentSuffix("s3"))
+}
+
+func TestAppendUserAgent(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://localhost", nil)
+	appendUserAgent(req, "my-service")
+	assert.Equal(t, "my-service", req.Header.Get("User-Agent"))
+
+	req.Header.Set("User-Agent", "aws-sdk-go/0.6.0")
+	appendUserAgent(req, "my-service")
+	assert.Equal(t, "aws-sdk-go/0.6.0 my-service", req.Header.Get("User-Agent"))
+
+	// no panic
+	appendUserAgent(nil, "my-service")
+}
@@ -0,0 +1,89 @@
+// AWS error wrapper
+
+package awserror
+
+import (
+	"github.com/awslabs/aws-sdk-go/aws/awserr"
+)
+
+// AWSError is the error returned from AWS operation with the request ID
+type AWSError struct {
+	Service   string
+	Op        string
+	RequestID string
+	Err       error
+
+	// Sentinel is matched by errors.Is, used for the typed error of the service (e.g. not found)
+	Sentinel error
+}
+
+// New wraps the error returned from AWS operation, returns nil when the error is nil
+func New(service, op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*AWSError); ok {
+		return err
+	}
+	e := &AWSError{
+		Service: service,
+		Op:      op,
+		Err:     err,
+	}
+	if f, ok := err.(awserr.RequestFailure); ok {
+		e.RequestID = f.RequestID()
+	}
+	return e
+}
+
+// Error returns the error message with the operation and the request ID
+func (e *AWSError) Error() string {
+	msg := "[" + e.Service + "] " + e.Op + ": " + e.Err.Error()
+	if e.RequestID != "" {
+		msg += ", request_id=" + e.RequestID
+	}
+	return msg
+}
+
+// Unwrap returns the original error
+func (e *AWSError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports if the target is the sentinel error of this error
+func (e *AWSError) Is(target error) bool {
+	return e.Sentinel != nil && target == e.Sentinel
+}
+
+// Code returns the error code of AWS, returns empty string when the error is not from AWS
+func Code(err error) string {
+	if e, ok := err.(*AWSError); ok {
+		err = e.Err
+	}
+	if e, ok := err.(awserr.Error); ok {
+		return e.Code()
+	}
+	return ""
+}
+
+// Message returns the error message of AWS without the code, returns empty string when the error is not from AWS
+func Message(err error) string {
+	if e, ok := err.(*AWSError); ok {
+		err = e.Err
+	}
+	if e, ok := err.(awserr.Error); ok {
+		return e.Message()
+	}
+	return ""
+}
+
+// StatusCode returns the HTTP status code of AWS response, returns 0 when the error is not from AWS
+func StatusCode(err error) int {
+	if e, ok := err.(*AWSError); ok {
+		err = e.Err
+	}
+	if e, ok := err.(awserr.RequestFailure); ok {
+		return e.StatusCode()
+	}
+	return 0
+}
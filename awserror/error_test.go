@@ -0,0 +1,86 @@
+package awserror
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequestFailure struct {
+	code      string
+	status    int
+	requestID string
+}
+
+func (e testRequestFailure) Error() string     { return e.code + ": test error" }
+func (e testRequestFailure) Code() string      { return e.code }
+func (e testRequestFailure) Message() string   { return "test error" }
+func (e testRequestFailure) OrigErr() error    { return nil }
+func (e testRequestFailure) StatusCode() int   { return e.status }
+func (e testRequestFailure) RequestID() string { return e.requestID }
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New("DynamoDB", "PutItem", nil))
+
+	orig := testRequestFailure{"ValidationException", 400, "REQ123"}
+	err := New("DynamoDB", "PutItem", orig)
+	e, ok := err.(*AWSError)
+	assert.True(t, ok)
+	assert.Equal(t, "DynamoDB", e.Service)
+	assert.Equal(t, "PutItem", e.Op)
+	assert.Equal(t, "REQ123", e.RequestID)
+	assert.Equal(t, orig, e.Err)
+
+	// not wrapped twice
+	assert.Equal(t, err, New("DynamoDB", "Put", err))
+
+	// error without request ID
+	err = New("S3", "GetObject", errors.New("foo"))
+	assert.Equal(t, "", err.(*AWSError).RequestID)
+}
+
+func TestError(t *testing.T) {
+	err := New("DynamoDB", "PutItem", testRequestFailure{"ValidationException", 400, "REQ123"})
+	assert.Equal(t, "[DynamoDB] PutItem: ValidationException: test error, request_id=REQ123", err.Error())
+
+	err = New("S3", "GetObject", errors.New("foo"))
+	assert.Equal(t, "[S3] GetObject: foo", err.Error())
+}
+
+func TestUnwrap(t *testing.T) {
+	orig := errors.New("foo")
+	err := New("SQS", "SendMessageBatch", orig)
+	assert.Equal(t, orig, err.(*AWSError).Unwrap())
+}
+
+func TestIs(t *testing.T) {
+	sentinel := errors.New("not found")
+	err := New("SQS", "ReceiveMessage", errors.New("foo"))
+	assert.False(t, errors.Is(err, sentinel))
+
+	err.(*AWSError).Sentinel = sentinel
+	assert.True(t, errors.Is(err, sentinel))
+	assert.False(t, errors.Is(err, errors.New("not found")))
+}
+
+func TestCode(t *testing.T) {
+	orig := testRequestFailure{"ConditionalCheckFailedException", 400, "REQ123"}
+	assert.Equal(t, "ConditionalCheckFailedException", Code(orig))
+	assert.Equal(t, "ConditionalCheckFailedException", Code(New("DynamoDB", "PutItem", orig)))
+	assert.Equal(t, "", Code(errors.New("foo")))
+}
+
+func TestMessage(t *testing.T) {
+	orig := testRequestFailure{"ValidationException", 400, "REQ123"}
+	assert.Equal(t, "test error", Message(orig))
+	assert.Equal(t, "test error", Message(New("DynamoDB", "PutItem", orig)))
+	assert.Equal(t, "", Message(errors.New("foo")))
+}
+
+func TestStatusCode(t *testing.T) {
+	orig := testRequestFailure{"NotModified", 304, "REQ123"}
+	assert.Equal(t, 304, StatusCode(orig))
+	assert.Equal(t, 304, StatusCode(New("S3", "GetObject", orig)))
+	assert.Equal(t, 0, StatusCode(errors.New("foo")))
+}
@@ -0,0 +1,152 @@
+// Clients of the multiple services with the single config
+
+package awswrapper
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
+	"github.com/awslabs/aws-sdk-go/aws/credentials"
+	"golang.org/x/time/rate"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/dynamodb"
+	"github.com/evalphobia/aws-sdk-go-wrapper/s3"
+	"github.com/evalphobia/aws-sdk-go-wrapper/sns"
+	"github.com/evalphobia/aws-sdk-go-wrapper/sqs"
+)
+
+const (
+	authSectionName = "auth"
+	endpointRegion  = "us-east-1"
+)
+
+// Config is the config shared by the clients of all services
+type Config struct {
+	// Region is resolved by auth.ResolveRegion when it's empty
+	Region string
+
+	// the static credentials, the credentials of auth.Auth are used when AccessKey is empty
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// Endpoint is used for all services (e.g. the local emulator), S3 uses path-style with the endpoint
+	Endpoint string
+
+	// HTTPClient is used for all services, auth.HTTPClient is used when it's nil
+	HTTPClient *http.Client
+
+	// RateLimit is the max requests per second of all services in total, 0 means the rate limit of the config file
+	RateLimit int
+}
+
+// Clients is the holder of the service clients,
+// each client is created on the first call of the accessor and shares the credentials and the HTTP client
+type Clients struct {
+	conf    *AWS.Config
+	limiter *rate.Limiter
+
+	dynamodbOnce sync.Once
+	dynamodb     *dynamodb.AmazonDynamoDB
+	s3Once       sync.Once
+	s3           *s3.AmazonS3
+	sqsOnce      sync.Once
+	sqs          *sqs.AmazonSQS
+	snsOnce      sync.Once
+	sns          *sns.AmazonSNS
+}
+
+// New creates the holder of the service clients from the config
+func New(cfg Config) (*Clients, error) {
+	region := cfg.Region
+	if region == "" {
+		var err error
+		region, err = auth.ResolveRegion(authSectionName)
+		switch {
+		case err == nil:
+		case cfg.Endpoint != "":
+			region = endpointRegion
+		default:
+			return nil, err
+		}
+	}
+
+	var cred *credentials.Credentials
+	switch {
+	case cfg.AccessKey == "" && cfg.SecretKey == "":
+		cred = auth.Auth()
+	case cfg.AccessKey == "" || cfg.SecretKey == "":
+		return nil, errors.New("[AWS] both of AccessKey and SecretKey must be set")
+	default:
+		cred = credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, cfg.SessionToken)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = auth.HTTPClient()
+	}
+	return &Clients{
+		conf: &AWS.Config{
+			Credentials: cred,
+			Region:      region,
+			Endpoint:    cfg.Endpoint,
+			HTTPClient:  httpClient,
+		},
+		limiter: auth.NewRateLimiter(cfg.RateLimit),
+	}, nil
+}
+
+// get the copy of the shared config for the service
+func (c *Clients) awsConfig() *AWS.Config {
+	conf := *c.conf
+	return &conf
+}
+
+// DynamoDB returns the client of DynamoDB
+func (c *Clients) DynamoDB() *dynamodb.AmazonDynamoDB {
+	c.dynamodbOnce.Do(func() {
+		c.dynamodb = dynamodb.NewClientWithConfig(c.awsConfig())
+		if c.limiter != nil {
+			c.dynamodb.SetRateLimiter(c.limiter)
+		}
+	})
+	return c.dynamodb
+}
+
+// S3 returns the client of S3
+func (c *Clients) S3() *s3.AmazonS3 {
+	c.s3Once.Do(func() {
+		conf := c.awsConfig()
+		conf.S3ForcePathStyle = conf.Endpoint != ""
+		c.s3 = s3.NewClientWithConfig(conf)
+		if c.limiter != nil {
+			c.s3.SetRateLimiter(c.limiter)
+		}
+	})
+	return c.s3
+}
+
+// SQS returns the client of SQS
+func (c *Clients) SQS() *sqs.AmazonSQS {
+	c.sqsOnce.Do(func() {
+		c.sqs = sqs.NewClientWithConfig(c.awsConfig())
+		if c.limiter != nil {
+			c.sqs.SetRateLimiter(c.limiter)
+		}
+	})
+	return c.sqs
+}
+
+// SNS returns the client of SNS
+func (c *Clients) SNS() *sns.AmazonSNS {
+	c.snsOnce.Do(func() {
+		c.sns = sns.NewClientWithConfig(c.awsConfig())
+		if c.limiter != nil {
+			c.sns.SetRateLimiter(c.limiter)
+		}
+	})
+	return c.sns
+}
@@ -0,0 +1,86 @@
+package awswrapper
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setTestEnv() {
+	os.Clearenv()
+	os.Setenv("AWS_CONFIG_FILE", "/not-exist/config")
+}
+
+func TestNew(t *testing.T) {
+	setTestEnv()
+
+	c, err := New(Config{
+		Region:    "ap-northeast-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "ap-northeast-1", c.conf.Region)
+	assert.Equal(t, "", c.conf.Endpoint)
+	cred, err := c.conf.Credentials.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "access", cred.AccessKeyID)
+	assert.Equal(t, "secret", cred.SecretAccessKey)
+
+	// the region from the env var
+	os.Setenv("AWS_REGION", "us-west-2")
+	c, err = New(Config{})
+	assert.Nil(t, err)
+	assert.Equal(t, "us-west-2", c.conf.Region)
+}
+
+func TestNewError(t *testing.T) {
+	setTestEnv()
+
+	_, err := New(Config{})
+	assert.NotNil(t, err)
+
+	// the endpoint does not require the region
+	c, err := New(Config{Endpoint: "http://localhost:4566"})
+	assert.Nil(t, err)
+	assert.Equal(t, endpointRegion, c.conf.Region)
+
+	_, err = New(Config{Region: "us-east-1", AccessKey: "access"})
+	assert.NotNil(t, err)
+	_, err = New(Config{Region: "us-east-1", SecretKey: "secret"})
+	assert.NotNil(t, err)
+}
+
+func TestClients(t *testing.T) {
+	setTestEnv()
+
+	c, err := New(Config{
+		Region:    "us-east-1",
+		AccessKey: "access",
+		SecretKey: "secret",
+		Endpoint:  "http://localhost:4566",
+	})
+	assert.Nil(t, err)
+
+	// the client is created once
+	assert.NotNil(t, c.DynamoDB())
+	assert.True(t, c.DynamoDB() == c.DynamoDB())
+	assert.NotNil(t, c.S3())
+	assert.True(t, c.S3() == c.S3())
+	assert.NotNil(t, c.SQS())
+	assert.True(t, c.SQS() == c.SQS())
+	assert.NotNil(t, c.SNS())
+	assert.True(t, c.SNS() == c.SNS())
+
+	assert.Nil(t, c.limiter)
+	c, _ = New(Config{Region: "us-east-1", RateLimit: 10})
+	assert.NotNil(t, c.limiter)
+	assert.NotNil(t, c.SQS())
+
+	// the shared config is not changed by the service
+	assert.False(t, c.conf.S3ForcePathStyle)
+	conf := c.awsConfig()
+	assert.False(t, conf == c.conf)
+	assert.True(t, conf.Credentials == c.conf.Credentials)
+}
@@ -0,0 +1,479 @@
+// DynamoDB Batch operation
+
+package dynamodb
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+const (
+	batchGetMaxKeys    = 100
+	batchWriteMaxItems = 25
+	batchRetryWait     = 50 * time.Millisecond
+	batchRetryMaxWait  = 5 * time.Second
+
+	// default number of retries for the unprocessed items
+	defaultBatchMaxRetries = 10
+
+	// number of segments for parallel scan on DeleteAll
+	deleteAllSegments = 4
+)
+
+// ErrPartialResult is matched by errors.Is for PartialResultError
+var ErrPartialResult = errors.New("[DynamoDB] the batch operation has unprocessed items")
+
+// PartialResultError is returned when the batch operation gives up retrying the unprocessed items
+// by the max retries or the timeout, the results of the processed items are returned with this error
+type PartialResultError struct {
+	Table string
+	Op    string
+
+	// primary keys of the unprocessed items
+	UnprocessedKeys []map[string]interface{}
+}
+
+// Error returns the error message with the number of unprocessed keys
+func (e *PartialResultError) Error() string {
+	return "[DynamoDB] " + e.Op + ": unprocessed items remain, table=" + e.Table + ", keys=" + strconv.Itoa(len(e.UnprocessedKeys))
+}
+
+// Is reports if the target is ErrPartialResult
+func (e *PartialResultError) Is(target error) bool {
+	return target == ErrPartialResult
+}
+
+// SetBatchRetry sets the limits of retrying the unprocessed items of the batch operations,
+// maxRetries=0 uses the default value and timeout=0 means no deadline
+func (t *DynamoTable) SetBatchRetry(maxRetries int, timeout time.Duration) {
+	t.batchMaxRetries = maxRetries
+	t.batchTimeout = timeout
+}
+
+// get mapped-items with BatchGetItem operation,
+// the order of the results is not same as the keys.
+// the items are projected to the attrs and the primary keys when attrs are given.
+// PartialResultError is returned with the processed items when the retries are exhausted
+func (t *DynamoTable) BatchGet(keys []map[string]interface{}, attrs ...string) ([]map[string]interface{}, error) {
+	items, err := t.batchGetItems(keys, attrs)
+	if err != nil && !errors.Is(err, ErrPartialResult) {
+		return nil, err
+	}
+	return t.ConvertItemsToMapArray(items), err
+}
+
+// get mapped-items with BatchGetItem operation in the same order as the keys,
+// the result has nil for the key of missing item.
+// the items are projected to the attrs and the primary keys when attrs are given
+func (t *DynamoTable) BatchGetOrdered(keys []map[string]interface{}, attrs ...string) ([]map[string]interface{}, error) {
+	items, err := t.batchGetItems(keys, attrs)
+	if err != nil && !errors.Is(err, ErrPartialResult) {
+		return nil, err
+	}
+
+	index := make(map[string]*map[string]*SDK.AttributeValue, len(items))
+	for _, item := range items {
+		index[t.keyString(item)] = item
+	}
+	// the repeated keys get the item respectively
+	results := make([]map[string]interface{}, len(keys))
+	for i, key := range keys {
+		// the keys are already marshaled by batchGetItems
+		k, _ := t.marshalKey(key)
+		if item, ok := index[t.keyString(k)]; ok {
+			results[i] = t.options().unmarshal(item)
+		}
+	}
+	return results, err
+}
+
+// ExistsMany checks the existence of the keys with BatchGetItem operation projected to the primary keys,
+// the result maps the string of the key by KeyString to the presence.
+// PartialResultError is returned with the result of the processed keys when the retries are exhausted,
+// and the unprocessed keys are not contained in the result
+func (t *DynamoTable) ExistsMany(keys []map[string]interface{}) (map[string]bool, error) {
+	items, err := t.batchGetItems(keys, t.keyNames())
+	var partial *PartialResultError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+
+	unprocessed := make(map[string]bool)
+	if partial != nil {
+		for _, key := range partial.UnprocessedKeys {
+			unprocessed[t.KeyString(key)] = true
+		}
+	}
+	results := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if k := t.KeyString(key); !unprocessed[k] {
+			results[k] = false
+		}
+	}
+	for _, item := range items {
+		results[t.keyString(item)] = true
+	}
+	return results, err
+}
+
+// KeyString returns the string of the primary key values with the types in the key, like `"N:100","N:1"`,
+// it's used for the result of ExistsMany. returns empty string for the invalid key (e.g. invalid base64 string for the binary key)
+func (t *DynamoTable) KeyString(key map[string]interface{}) string {
+	k, err := t.marshalKey(key)
+	if err != nil {
+		return ""
+	}
+	return t.keyString(k)
+}
+
+// execute BatchGetItem operation for every 100 unique keys in parallel up to the max concurrency,
+// and retry unprocessed keys up to the limits
+func (t *DynamoTable) batchGetItems(keys []map[string]interface{}, attrs []string) ([]*map[string]*SDK.AttributeValue, error) {
+	// BatchGetItem rejects the request with the duplicate keys
+	unique, err := t.uniqueKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	var chunks [][]*map[string]*SDK.AttributeValue
+	for i := 0; i < len(unique); i += batchGetMaxKeys {
+		end := i + batchGetMaxKeys
+		if end > len(unique) {
+			end = len(unique)
+		}
+		chunks = append(chunks, unique[i:end])
+	}
+
+	projection := t.newBatchGetProjection(attrs)
+	deadline := t.batchDeadline()
+	results := make([][]*map[string]*SDK.AttributeValue, len(chunks))
+	unprocessedKeys := make([][]*map[string]*SDK.AttributeValue, len(chunks))
+	errs := make([]error, len(chunks))
+	t.parallel(len(chunks), func(i int) {
+		results[i], unprocessedKeys[i], errs[i] = t.batchGetChunk(chunks[i], projection, deadline)
+	})
+
+	var items, unprocessed []*map[string]*SDK.AttributeValue
+	for i := range chunks {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		items = append(items, results[i]...)
+		unprocessed = append(unprocessed, unprocessedKeys[i]...)
+	}
+	if len(unprocessed) != 0 {
+		return items, t.newPartialResultError("BatchGetItem", unprocessed)
+	}
+	return items, nil
+}
+
+// get the marshaled primary keys without the duplicates in order of the first appearance
+func (t *DynamoTable) uniqueKeys(keys []map[string]interface{}) ([]*map[string]*SDK.AttributeValue, error) {
+	seen := make(map[string]bool, len(keys))
+	var unique []*map[string]*SDK.AttributeValue
+	for _, key := range keys {
+		k, err := t.marshalKey(key)
+		if err != nil {
+			return nil, err
+		}
+		s := t.keyString(k)
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		unique = append(unique, k)
+	}
+	return unique, nil
+}
+
+// create the projection of the attributes and the primary keys for BatchGetItem,
+// the projection and the attribute names are attached to the request of the table. returns nil without attributes
+func (t *DynamoTable) newBatchGetProjection(attrs []string) *SDK.KeysAndAttributes {
+	if len(attrs) == 0 {
+		return nil
+	}
+	exp := t.options().newExpressionAttributes()
+	seen := make(map[string]bool)
+	var paths []string
+	for _, attr := range append(t.keyNames(), attrs...) {
+		if seen[attr] {
+			continue
+		}
+		seen[attr] = true
+		paths = append(paths, exp.path(attr))
+	}
+	return &SDK.KeysAndAttributes{
+		ProjectionExpression:     String(strings.Join(paths, ", ")),
+		ExpressionAttributeNames: exp.expressionNames(),
+	}
+}
+
+// execute BatchGetItem operation for the keys with the projection (optional),
+// and returns the items and the unprocessed keys
+func (t *DynamoTable) batchGetChunk(chunk []*map[string]*SDK.AttributeValue, projection *SDK.KeysAndAttributes, deadline time.Time) (items, unprocessed []*map[string]*SDK.AttributeValue, err error) {
+	if t.isDeadlineExceeded(deadline, 0) {
+		return nil, chunk, nil
+	}
+
+	ka := &SDK.KeysAndAttributes{
+		Keys:           chunk,
+		ConsistentRead: t.consistentRead(nil, nil),
+	}
+	if projection != nil {
+		ka.ProjectionExpression = projection.ProjectionExpression
+		ka.ExpressionAttributeNames = projection.ExpressionAttributeNames
+	}
+	requests := map[string]*SDK.KeysAndAttributes{
+		t.name: ka,
+	}
+	for retry := 0; ; retry++ {
+		res, err := t.db.client.BatchGetItem(&SDK.BatchGetItemInput{
+			RequestItems:           &requests,
+			ReturnConsumedCapacity: t.returnCapacity(nil),
+		})
+		if err != nil {
+			err = wrapError("BatchGetItem", err)
+			log.Error("[DynamoDB] Error in `BatchGetItem` operation, table="+t.name, err)
+			return nil, nil, err
+		}
+		if res.Responses != nil {
+			items = append(items, (*res.Responses)[t.name]...)
+		}
+		if res.UnprocessedKeys == nil || len(*res.UnprocessedKeys) == 0 {
+			return items, nil, nil
+		}
+		requests = *res.UnprocessedKeys
+		if !t.canRetryBatch(retry, deadline) {
+			if ka := requests[t.name]; ka != nil {
+				unprocessed = ka.Keys
+			}
+			return items, unprocessed, nil
+		}
+		time.Sleep(t.retryBackoff(retry))
+	}
+}
+
+// [CAUTION]
+// only used this for developing, this performs parallel scan for all keys and delete them with BatchWriteItem
+func (t *DynamoTable) DeleteAll() error {
+	_, err := t.DeleteAllCount()
+	return err
+}
+
+// delete all items in the table and returns the number of deleted items
+func (t *DynamoTable) DeleteAllCount() (int, error) {
+	counts := make([]int, deleteAllSegments)
+	errs := make([]error, deleteAllSegments)
+	t.parallel(deleteAllSegments, func(segment int) {
+		counts[segment], errs[segment] = t.deleteSegment(segment, deleteAllSegments)
+	})
+
+	var total int
+	var err error
+	for i := range counts {
+		total += counts[i]
+		if err == nil {
+			err = errs[i]
+		}
+	}
+	log.Info("[DynamoDB] DeleteAll, table="+t.name+", deleted=", total)
+	return total, err
+}
+
+// scan the keys in the segment and delete them for every 25 keys
+func (t *DynamoTable) deleteSegment(segment, total int) (int, error) {
+	attrs := t.options().newExpressionAttributes()
+	var names []string
+	for _, name := range t.keyNames() {
+		names = append(names, attrs.name(name))
+	}
+	in := &SDK.ScanInput{
+		TableName:                String(t.name),
+		ProjectionExpression:     String(strings.Join(names, ", ")),
+		ExpressionAttributeNames: attrs.expressionNames(),
+		Segment:                  Long(int64(segment)),
+		TotalSegments:            Long(int64(total)),
+	}
+
+	var count int
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		res, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		for i := 0; i < len(res.Items); i += batchWriteMaxItems {
+			end := i + batchWriteMaxItems
+			if end > len(res.Items) {
+				end = len(res.Items)
+			}
+			if err := t.batchDeleteKeys(res.Items[i:end]); err != nil {
+				return nil, false, err
+			}
+			count += end - i
+		}
+		next, done := nextPageKey(res.LastEvaluatedKey)
+		return next, done, nil
+	})
+	return count, err
+}
+
+// execute BatchWriteItem operation to delete the keys, and retry unprocessed items up to the limits
+func (t *DynamoTable) batchDeleteKeys(keys []*map[string]*SDK.AttributeValue) error {
+	var deletes []*SDK.WriteRequest
+	for _, key := range keys {
+		deletes = append(deletes, &SDK.WriteRequest{
+			DeleteRequest: &SDK.DeleteRequest{Key: key},
+		})
+	}
+	return t.batchWriteRequests(deletes)
+}
+
+// execute BatchWriteItem operation to put the items, and retry unprocessed items up to the limits
+func (t *DynamoTable) batchPutItems(items []*map[string]*SDK.AttributeValue) error {
+	var puts []*SDK.WriteRequest
+	for _, item := range items {
+		puts = append(puts, &SDK.WriteRequest{
+			PutRequest: &SDK.PutRequest{Item: item},
+		})
+	}
+	return t.batchWriteRequests(puts)
+}
+
+// execute BatchWriteItem operation for up to 25 write requests, and retry unprocessed items up to the limits
+func (t *DynamoTable) batchWriteRequests(writes []*SDK.WriteRequest) error {
+	requests := map[string][]*SDK.WriteRequest{
+		t.name: writes,
+	}
+	deadline := t.batchDeadline()
+	for retry := 0; ; retry++ {
+		res, err := t.db.client.BatchWriteItem(&SDK.BatchWriteItemInput{
+			RequestItems:           &requests,
+			ReturnConsumedCapacity: t.returnCapacity(nil),
+		})
+		t.invalidateWriteRequests(requests[t.name])
+		if err != nil {
+			err = wrapError("BatchWriteItem", err)
+			t.notifyThrottle("BatchWriteItem", nil, err)
+			log.Error("[DynamoDB] Error in `BatchWriteItem` operation, table="+t.name, err)
+			return err
+		}
+		if res.UnprocessedItems == nil || len(*res.UnprocessedItems) == 0 {
+			return nil
+		}
+		requests = *res.UnprocessedItems
+		if !t.canRetryBatch(retry, deadline) {
+			var unprocessed []*map[string]*SDK.AttributeValue
+			for _, req := range requests[t.name] {
+				switch {
+				case req.DeleteRequest != nil:
+					unprocessed = append(unprocessed, req.DeleteRequest.Key)
+				case req.PutRequest != nil:
+					unprocessed = append(unprocessed, req.PutRequest.Item)
+				}
+			}
+			return t.newPartialResultError("BatchWriteItem", unprocessed)
+		}
+		time.Sleep(t.retryBackoff(retry))
+	}
+}
+
+// invalidate the cached results of the partitions of the write requests
+func (t *DynamoTable) invalidateWriteRequests(writes []*SDK.WriteRequest) {
+	if t.cache == nil {
+		return
+	}
+	for _, req := range writes {
+		switch {
+		case req.DeleteRequest != nil:
+			t.invalidateCache(req.DeleteRequest.Key)
+		case req.PutRequest != nil:
+			t.invalidateCache(req.PutRequest.Item)
+		}
+	}
+}
+
+// get the deadline of the batch operation, zero time means no deadline
+func (t *DynamoTable) batchDeadline() time.Time {
+	if t.batchTimeout <= 0 {
+		return time.Time{}
+	}
+	return t.currentTime().Add(t.batchTimeout)
+}
+
+// check if the unprocessed items can be retried after the retry-th attempt within the limits
+func (t *DynamoTable) canRetryBatch(retry int, deadline time.Time) bool {
+	maxRetries := t.batchMaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultBatchMaxRetries
+	}
+	return retry < maxRetries && !t.isDeadlineExceeded(deadline, t.retryBackoff(retry))
+}
+
+// check if the deadline passes after the wait, zero deadline is never exceeded
+func (t *DynamoTable) isDeadlineExceeded(deadline time.Time, wait time.Duration) bool {
+	return !deadline.IsZero() && t.currentTime().Add(wait).After(deadline)
+}
+
+// create PartialResultError with the primary keys of unprocessed items
+func (t *DynamoTable) newPartialResultError(op string, keys []*map[string]*SDK.AttributeValue) error {
+	e := &PartialResultError{
+		Table: t.name,
+		Op:    op,
+	}
+	for _, key := range keys {
+		e.UnprocessedKeys = append(e.UnprocessedKeys, t.options().unmarshalSubset(key, t.keyNames()...))
+	}
+	log.Error("[DynamoDB] Error in `"+op+"` operation, table="+t.name, e)
+	return e
+}
+
+// get waiting time for retrying unprocessed items from the backoff of the client
+func (t *DynamoTable) retryBackoff(retry int) time.Duration {
+	if t.db == nil || t.db.backoff == nil {
+		return batchBackoff(retry)
+	}
+	return t.db.backoff.Duration(retry + 1)
+}
+
+// get default waiting time for retrying unprocessed items
+func batchBackoff(retry int) time.Duration {
+	wait := batchRetryWait << uint(retry)
+	if wait <= 0 || wait > batchRetryMaxWait {
+		return batchRetryMaxWait
+	}
+	return wait
+}
+
+// get the string expression of the primary key values in the item
+func (t *DynamoTable) keyString(item *map[string]*SDK.AttributeValue) string {
+	var keys []string
+	for _, name := range t.keyNames() {
+		keys = append(keys, strconv.Quote(attributeValueString((*item)[name])))
+	}
+	return strings.Join(keys, ",")
+}
+
+// get the string expression of the scalar AttributeValue
+func attributeValueString(v *SDK.AttributeValue) string {
+	switch {
+	case v == nil:
+		return ""
+	case v.S != nil:
+		return "S:" + *v.S
+	case v.N != nil:
+		return "N:" + *v.N
+	case v.B != nil:
+		return "B:" + base64.StdEncoding.EncodeToString(v.B)
+	}
+	return ""
+}
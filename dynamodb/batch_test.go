@@ -0,0 +1,278 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+)
+
+func TestBatchGet(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+	putTestTable(tbl, 100, 2)
+
+	keys := []map[string]interface{}{
+		{"id": 100, "time": 1},
+		{"id": 100, "time": 2},
+		{"id": 100, "time": 3},
+	}
+	results, err := tbl.BatchGet(keys)
+	if err != nil {
+		t.Errorf("error on BatchGet, %s", err.Error())
+	}
+	if len(results) != 2 {
+		t.Errorf("error on BatchGet, %v", results)
+	}
+}
+
+func TestBatchGetOrdered(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+	putTestTable(tbl, 100, 2)
+
+	keys := []map[string]interface{}{
+		{"id": 100, "time": 2},
+		{"id": 100, "time": 3},
+		{"id": 100, "time": 1},
+	}
+	results, err := tbl.BatchGetOrdered(keys)
+	if err != nil {
+		t.Errorf("error on BatchGetOrdered, %s", err.Error())
+	}
+	if len(results) != 3 {
+		t.Errorf("error on BatchGetOrdered, %v", results)
+	}
+	if results[0]["time"] != 2 || results[1] != nil || results[2]["time"] != 1 {
+		t.Errorf("error on BatchGetOrdered, %v", results)
+	}
+
+	// the repeated key is requested once and every position has the item
+	keys = append(keys, map[string]interface{}{"id": 100, "time": 2})
+	results, err = tbl.BatchGetOrdered(keys)
+	if err != nil {
+		t.Errorf("error on BatchGetOrdered with the repeated key, %s", err.Error())
+	}
+	if len(results) != 4 || results[0]["time"] != 2 || results[3]["time"] != 2 {
+		t.Errorf("error on BatchGetOrdered with the repeated key, %v", results)
+	}
+}
+
+func TestBatchGetProjection(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+
+	keys := []map[string]interface{}{
+		{"id": 100, "time": 1},
+	}
+	results, err := tbl.BatchGetOrdered(keys, "lsi_key")
+	if err != nil {
+		t.Errorf("error on BatchGetOrdered, %s", err.Error())
+	}
+	if len(results) != 1 || results[0]["lsi_key"] != "lsi_value" {
+		t.Errorf("error on BatchGetOrdered, %v", results)
+	}
+
+	results, err = tbl.BatchGet(keys, "time")
+	if err != nil {
+		t.Errorf("error on BatchGet, %s", err.Error())
+	}
+	if len(results) != 1 || results[0]["lsi_key"] != nil {
+		t.Errorf("error on BatchGet, non-projected attribute is returned, %v", results)
+	}
+}
+
+func TestExistsMany(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+	putTestTable(tbl, 100, 2)
+
+	keys := []map[string]interface{}{
+		{"id": 100, "time": 1},
+		{"id": 100, "time": 2},
+		{"id": 100, "time": 3},
+	}
+	results, err := tbl.ExistsMany(keys)
+	if err != nil {
+		t.Errorf("error on ExistsMany, %s", err.Error())
+	}
+	if len(results) != 3 || !results[tbl.KeyString(keys[0])] || !results[tbl.KeyString(keys[1])] || results[tbl.KeyString(keys[2])] {
+		t.Errorf("error on ExistsMany, %v", results)
+	}
+}
+
+func TestTableKeyString(t *testing.T) {
+	tbl := getTestCacheTable()
+	if s := tbl.KeyString(map[string]interface{}{"id": 100, "time": 1, "name": "foo"}); s != `"N:100","N:1"` {
+		t.Errorf("error on KeyString, %s", s)
+	}
+}
+
+func TestUniqueKeys(t *testing.T) {
+	tbl := getTestCacheTable()
+	keys := []map[string]interface{}{
+		{"id": 100, "time": 2},
+		{"id": 100, "time": 1},
+		{"id": 100, "time": 2, "name": "foo"},
+		{"id": "100", "time": 2},
+	}
+	unique, err := tbl.uniqueKeys(keys)
+	if err != nil || len(unique) != 3 {
+		t.Fatalf("error on uniqueKeys, %v, %v", unique, err)
+	}
+	if *(*unique[0])["time"].N != "2" || *(*unique[1])["time"].N != "1" || *(*unique[2])["id"].S != "100" {
+		t.Errorf("error on uniqueKeys, %v", unique)
+	}
+}
+
+func TestNewBatchGetProjection(t *testing.T) {
+	tbl := getTestCacheTable()
+	if p := tbl.newBatchGetProjection(nil); p != nil {
+		t.Errorf("error on newBatchGetProjection, %v", p)
+	}
+
+	p := tbl.newBatchGetProjection([]string{"name", "time", "profile.age"})
+	if *p.ProjectionExpression != "#n0, #n1, #n2, #n3.#n4" {
+		t.Errorf("error on newBatchGetProjection, %s", *p.ProjectionExpression)
+	}
+	names := *p.ExpressionAttributeNames
+	if *names["#n0"] != "id" || *names["#n1"] != "time" || *names["#n2"] != "name" || *names["#n3"] != "profile" || *names["#n4"] != "age" {
+		t.Errorf("error on newBatchGetProjection, %v", names)
+	}
+}
+
+func TestBatchBackoff(t *testing.T) {
+	if batchBackoff(0) != batchRetryWait || batchBackoff(1) != 2*batchRetryWait {
+		t.Errorf("error on batchBackoff, %v, %v", batchBackoff(0), batchBackoff(1))
+	}
+	if batchBackoff(100) != batchRetryMaxWait {
+		t.Errorf("error on batchBackoff, %v", batchBackoff(100))
+	}
+	if batchBackoff(10) > 5*time.Second {
+		t.Errorf("error on batchBackoff, %v", batchBackoff(10))
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tbl := &DynamoTable{}
+	if tbl.retryBackoff(1) != batchBackoff(1) {
+		t.Errorf("error on retryBackoff, %v", tbl.retryBackoff(1))
+	}
+
+	tbl.db = &AmazonDynamoDB{}
+	tbl.db.backoff = &auth.Backoff{Base: time.Millisecond}
+	if tbl.retryBackoff(0) != time.Millisecond || tbl.retryBackoff(2) != 4*time.Millisecond {
+		t.Errorf("error on retryBackoff, %v, %v", tbl.retryBackoff(0), tbl.retryBackoff(2))
+	}
+}
+
+func TestCanRetryBatch(t *testing.T) {
+	tbl := &DynamoTable{}
+	if !tbl.canRetryBatch(defaultBatchMaxRetries-1, time.Time{}) || tbl.canRetryBatch(defaultBatchMaxRetries, time.Time{}) {
+		t.Errorf("error on canRetryBatch, default max retries is not used")
+	}
+
+	tbl.SetBatchRetry(2, 0)
+	if !tbl.canRetryBatch(1, time.Time{}) || tbl.canRetryBatch(2, time.Time{}) {
+		t.Errorf("error on canRetryBatch, max retries is not used")
+	}
+	if tbl.canRetryBatch(0, time.Now()) {
+		t.Errorf("error on canRetryBatch, deadline is not used")
+	}
+	if !tbl.canRetryBatch(0, time.Now().Add(time.Minute)) {
+		t.Errorf("error on canRetryBatch, retry is not allowed before the deadline")
+	}
+
+	tbl.SetBatchRetry(0, time.Minute)
+	if tbl.batchDeadline().IsZero() {
+		t.Errorf("error on batchDeadline, deadline is not set")
+	}
+
+	// the clock of the client is used
+	now := time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)
+	tbl.db = &AmazonDynamoDB{}
+	tbl.db.setNow(func() time.Time { return now })
+	deadline := tbl.batchDeadline()
+	if !deadline.Equal(now.Add(time.Minute)) {
+		t.Errorf("error on batchDeadline, actual=%s", deadline)
+	}
+	if !tbl.canRetryBatch(0, deadline) {
+		t.Errorf("error on canRetryBatch, retry is not allowed before the deadline")
+	}
+	now = now.Add(time.Minute)
+	if tbl.canRetryBatch(0, deadline) {
+		t.Errorf("error on canRetryBatch, retry is allowed after the deadline")
+	}
+}
+
+func TestNewPartialResultError(t *testing.T) {
+	tbl := &DynamoTable{
+		name: "foo",
+		table: &SDK.TableDescription{
+			KeySchema: NewKeySchema(NewHashKeyElement("id"), NewRangeKeyElement("time")),
+		},
+	}
+	err := tbl.newPartialResultError("BatchGetItem", []*map[string]*SDK.AttributeValue{
+		Marshal(map[string]interface{}{"id": 100, "time": 1}),
+	})
+	if !errors.Is(err, ErrPartialResult) {
+		t.Errorf("error on newPartialResultError, %v", err)
+	}
+	e, ok := err.(*PartialResultError)
+	if !ok || e.Table != "foo" || e.Op != "BatchGetItem" || len(e.UnprocessedKeys) != 1 {
+		t.Fatalf("error on newPartialResultError, %+v", err)
+	}
+	if key := e.UnprocessedKeys[0]; key["id"] != 100 || key["time"] != 1 {
+		t.Errorf("error on newPartialResultError, key=%v", key)
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	tbl := getTestTable()
+	key1 := tbl.keyString(Marshal(map[string]interface{}{"id": 100, "time": 1, "foo": "bar"}))
+	key2 := tbl.keyString(Marshal(map[string]interface{}{"id": 100, "time": 1}))
+	key3 := tbl.keyString(Marshal(map[string]interface{}{"id": "100", "time": 1}))
+	if key1 != key2 || key1 == key3 {
+		t.Errorf("error on keyString, %s, %s, %s", key1, key2, key3)
+	}
+}
+
+func TestDeleteAllCount(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+
+	// empty table
+	count, err := tbl.DeleteAllCount()
+	if err != nil {
+		t.Errorf("error on DeleteAllCount, %s", err.Error())
+	}
+	if count != 0 {
+		t.Errorf("error on DeleteAllCount, count=%d", count)
+	}
+
+	for i := 1; i <= 30; i++ {
+		putTestTable(tbl, 100, i)
+	}
+	count, err = tbl.DeleteAllCount()
+	if err != nil {
+		t.Errorf("error on DeleteAllCount, %s", err.Error())
+	}
+	if count != 30 {
+		t.Errorf("error on DeleteAllCount, count=%d", count)
+	}
+
+	results, err := tbl.Scan()
+	if err != nil {
+		t.Errorf("error on Scan, %s", err.Error())
+	}
+	if len(results) != 0 {
+		t.Errorf("error on DeleteAllCount, %v", results)
+	}
+}
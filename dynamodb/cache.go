@@ -0,0 +1,245 @@
+// DynamoDB read-through cache for GetItem and Query
+
+package dynamodb
+
+import (
+	"container/list"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+const cacheKeyPrefix = "dynamodb:"
+
+// default max number of the entries of MemoryCache
+const memoryCacheDefaultMaxItems = 10000
+
+// e.g.) "#n0 = :v0" in KeyConditionExpression
+var keyConditionEQPattern = regexp.MustCompile(`([#\w]+)\s*=\s*(:\w+)`)
+
+// Cache is the backend of the read-through cache, like in-memory or Redis.
+// Get returns false when the key does not exist or is expired, and ttl=0 means no expiration
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// SetCache enables the read-through cache of GetOne and Query on the table (nil disables the cache),
+// the cached results are used until the ttl or the write to the same partition by this table.
+// the cache may return stale results within the ttl for the writes of the other processes which do not share the cache,
+// the writes by the other tables or AWS console, and the query of the index (the query of the index is not cached).
+// the entries of the old generations are not deleted on the write, use the cache with the ttl or the max size (e.g. MemoryCache)
+func (t *DynamoTable) SetCache(c Cache, ttl time.Duration) {
+	t.cache = c
+	t.cacheTTL = ttl
+}
+
+// get the cache key of the request in the partition, returns false when the request is not cacheable.
+// the key contains the generation of the partition which is changed on every write
+func (t *DynamoTable) cacheKey(op string, partition *SDK.AttributeValue, in interface{}) (string, bool) {
+	if t.cache == nil || partition == nil {
+		return "", false
+	}
+	req, err := json.Marshal(in)
+	if err != nil {
+		return "", false
+	}
+	return cacheKeyPrefix + t.name + ":" + t.partitionGeneration(partition) + ":" + op + ":" + hashHex(req), true
+}
+
+// get the current generation of the partition, the new generation is created when it does not exist
+func (t *DynamoTable) partitionGeneration(partition *SDK.AttributeValue) string {
+	key := t.generationKey(partition)
+	if v, ok := t.cache.Get(key); ok {
+		return string(v)
+	}
+	gen := newCacheGeneration()
+	t.cache.Set(key, []byte(gen), t.cacheTTL)
+	return gen
+}
+
+// get the cache key of the generation of the partition
+func (t *DynamoTable) generationKey(partition *SDK.AttributeValue) string {
+	b, _ := json.Marshal(partition)
+	return cacheKeyPrefix + t.name + ":gen:" + hashHex(b)
+}
+
+// invalidate the cached results of the partitions of the items by changing the generation
+func (t *DynamoTable) invalidateCache(items ...*map[string]*SDK.AttributeValue) {
+	if t.cache == nil {
+		return
+	}
+	hashKey := t.GetHashKeyName()
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if v, ok := (*item)[hashKey]; ok {
+			t.cache.Set(t.generationKey(v), []byte(newCacheGeneration()), t.cacheTTL)
+		}
+	}
+}
+
+// get the cached items
+func (t *DynamoTable) loadCache(key string) ([]*map[string]*SDK.AttributeValue, bool) {
+	data, ok := t.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	var items []*map[string]*SDK.AttributeValue
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, false
+	}
+	return items, true
+}
+
+// save the items to the cache
+func (t *DynamoTable) storeCache(key string, items []*map[string]*SDK.AttributeValue) {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return
+	}
+	t.cache.Set(key, data, t.cacheTTL)
+}
+
+// get the hash key value of the query on the table, returns nil for the query of the index
+// or when the hash key condition is not found
+func (t *DynamoTable) queryPartitionValue(in *SDK.QueryInput) *SDK.AttributeValue {
+	hashKey := t.GetHashKeyName()
+	switch {
+	case in.IndexName != nil:
+		return nil
+	case in.KeyConditions != nil:
+		cond, ok := (*in.KeyConditions)[hashKey]
+		if !ok || cond.ComparisonOperator == nil || *cond.ComparisonOperator != ComparisonOperatorEQ || len(cond.AttributeValueList) == 0 {
+			return nil
+		}
+		return cond.AttributeValueList[0]
+	case in.KeyConditionExpression == nil || in.ExpressionAttributeValues == nil:
+		return nil
+	}
+
+	for _, m := range keyConditionEQPattern.FindAllStringSubmatch(*in.KeyConditionExpression, -1) {
+		name := m[1]
+		if in.ExpressionAttributeNames != nil {
+			if v, ok := (*in.ExpressionAttributeNames)[name]; ok && v != nil {
+				name = *v
+			}
+		}
+		if name == hashKey {
+			return (*in.ExpressionAttributeValues)[m[2]]
+		}
+	}
+	return nil
+}
+
+// get the hex string of sha256 hash
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// create the random generation of the partition
+func newCacheGeneration() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// MemoryCache is the in-memory Cache for the single process, the entries are limited by the max size with LRU eviction.
+// the write changes the generation of the partition and the old entries are no longer read,
+// they remain until the expiration or the eviction as the least recently used entries.
+// the evicted entry is read from DynamoDB again, so the max size does not cause the stale results
+type MemoryCache struct {
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	lru      *list.List
+	maxItems int
+
+	// clock for the expiration
+	now func() time.Time
+}
+
+type memoryCacheItem struct {
+	key    string
+	value  []byte
+	expiry time.Time
+}
+
+// NewMemoryCache creates new MemoryCache with the default max size (10000 entries)
+func NewMemoryCache() *MemoryCache {
+	return NewMemoryCacheWithSize(memoryCacheDefaultMaxItems)
+}
+
+// NewMemoryCacheWithSize creates new MemoryCache which keeps up to maxItems entries,
+// the least recently used entry is evicted when the cache is full (maxItems <= 0 means the default size)
+func NewMemoryCacheWithSize(maxItems int) *MemoryCache {
+	if maxItems <= 0 {
+		maxItems = memoryCacheDefaultMaxItems
+	}
+	return &MemoryCache{
+		items:    make(map[string]*list.Element),
+		lru:      list.New(),
+		maxItems: maxItems,
+		now:      time.Now,
+	}
+}
+
+// Get returns the value of the key, returns false when the key does not exist or is expired
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := elem.Value.(*memoryCacheItem)
+	if !item.expiry.IsZero() && c.now().After(item.expiry) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return item.value, true
+}
+
+// Set saves the value of the key with the ttl, ttl=0 means no expiration.
+// the least recently used entry is evicted when the cache is full
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := &memoryCacheItem{key: key, value: value}
+	if ttl > 0 {
+		item.expiry = c.now().Add(ttl)
+	}
+	if elem, ok := c.items[key]; ok {
+		elem.Value = item
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.lru.PushFront(item)
+	for c.lru.Len() > c.maxItems {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Len returns the number of the entries including the expired entries which are not evicted yet
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove the entry from the cache
+func (c *MemoryCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.items, elem.Value.(*memoryCacheItem).key)
+}
@@ -0,0 +1,164 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func getTestCacheTable() *DynamoTable {
+	return &DynamoTable{
+		name: "foo_table",
+		table: &SDK.TableDescription{
+			KeySchema: NewKeySchema(NewHashKeyElement("id"), NewRangeKeyElement("time")),
+		},
+	}
+}
+
+func TestGetOneCache(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 700, 1)
+	tbl.SetCache(NewMemoryCache(), time.Minute)
+	defer tbl.SetCache(nil, 0)
+
+	item, err := tbl.GetOne(700, 1)
+	if err != nil || item["id"] != 700 {
+		t.Errorf("error on GetOne with cache, %v, %v", item, err)
+	}
+	items, err := tbl.Get(700)
+	if err != nil || len(items) != 1 {
+		t.Errorf("error on Query with cache, %v, %v", items, err)
+	}
+
+	// the write invalidates the cache of the partition
+	putTestTable(tbl, 700, 2)
+	items, err = tbl.Get(700)
+	if err != nil || len(items) != 2 {
+		t.Errorf("error on Query with cache, the cache is not invalidated, %v, %v", items, err)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	tbl := getTestCacheTable()
+	in := &SDK.GetItemInput{
+		TableName: String("foo_table"),
+		Key:       Marshal(map[string]interface{}{"id": 1, "time": 2}),
+	}
+	if _, ok := tbl.cacheKey("GetItem", (*in.Key)["id"], in); ok {
+		t.Errorf("error on cacheKey, the cache is disabled")
+	}
+
+	tbl.SetCache(NewMemoryCache(), time.Minute)
+	key1, ok := tbl.cacheKey("GetItem", (*in.Key)["id"], in)
+	if !ok {
+		t.Fatalf("error on cacheKey")
+	}
+	if key2, _ := tbl.cacheKey("GetItem", (*in.Key)["id"], in); key1 != key2 {
+		t.Errorf("error on cacheKey, the key must be same for the same request, %s, %s", key1, key2)
+	}
+	if _, ok := tbl.cacheKey("GetItem", nil, in); ok {
+		t.Errorf("error on cacheKey, the request without partition is not cacheable")
+	}
+
+	tbl.storeCache(key1, []*map[string]*SDK.AttributeValue{in.Key})
+	if items, ok := tbl.loadCache(key1); !ok || len(items) != 1 || Unmarshal(items[0])["time"] != 2 {
+		t.Errorf("error on loadCache, %v", items)
+	}
+
+	// write to the other partition
+	tbl.invalidateCache(Marshal(map[string]interface{}{"id": 2}))
+	if key2, _ := tbl.cacheKey("GetItem", (*in.Key)["id"], in); key1 != key2 {
+		t.Errorf("error on invalidateCache, the other partition is invalidated")
+	}
+	// write to the partition
+	tbl.invalidateCache(Marshal(map[string]interface{}{"id": 1, "time": 3}))
+	if key2, _ := tbl.cacheKey("GetItem", (*in.Key)["id"], in); key1 == key2 {
+		t.Errorf("error on invalidateCache, the partition is not invalidated")
+	}
+}
+
+func TestQueryPartitionValue(t *testing.T) {
+	tbl := getTestCacheTable()
+
+	keyCond := NewFilterBuilder()
+	keyCond.AddEQ("id", 100)
+	keyCond.AddGT("time", 1)
+	in, _ := newExpressionQueryInput("foo_table", keyCond, nil)
+	if v := tbl.queryPartitionValue(in); v == nil || *v.N != "100" {
+		t.Errorf("error on queryPartitionValue, %v", v)
+	}
+
+	keys := map[string]*SDK.Condition{
+		"id": {
+			AttributeValueList: []*SDK.AttributeValue{createAttributeValue(200)},
+			ComparisonOperator: String(ComparisonOperatorEQ),
+		},
+	}
+	if v := tbl.queryPartitionValue(&SDK.QueryInput{KeyConditions: &keys}); v == nil || *v.N != "200" {
+		t.Errorf("error on queryPartitionValue, %v", v)
+	}
+
+	in.IndexName = String("index")
+	if v := tbl.queryPartitionValue(in); v != nil {
+		t.Errorf("error on queryPartitionValue, the query of the index is not cacheable, %v", v)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache()
+	c.Set("foo", []byte("bar"), 0)
+	if v, ok := c.Get("foo"); !ok || string(v) != "bar" {
+		t.Errorf("error on MemoryCache, %s", v)
+	}
+
+	c.Set("expired", []byte("bar"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := c.Get("expired"); ok {
+		t.Errorf("error on MemoryCache, the expired value is returned")
+	}
+	if _, ok := c.Get("non_exist"); ok {
+		t.Errorf("error on MemoryCache, the value which does not exist is returned")
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	c := NewMemoryCacheWithSize(2)
+	c.Set("a", []byte("1"), 0)
+	c.Set("b", []byte("2"), 0)
+	// a is used recently, b is evicted
+	c.Get("a")
+	c.Set("c", []byte("3"), 0)
+	if c.Len() != 2 {
+		t.Errorf("error on MemoryCache, len=%d", c.Len())
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("error on MemoryCache, the least recently used value is not evicted")
+	}
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("error on MemoryCache, %s", v)
+	}
+
+	// overwrite does not evict the other entry
+	c.Set("c", []byte("4"), 0)
+	if v, ok := c.Get("c"); !ok || string(v) != "4" || c.Len() != 2 {
+		t.Errorf("error on MemoryCache, %s, len=%d", v, c.Len())
+	}
+
+	// the expiration by the clock
+	now := time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	c.Set("ttl", []byte("5"), time.Minute)
+	if _, ok := c.Get("ttl"); !ok {
+		t.Errorf("error on MemoryCache, the value is expired before the ttl")
+	}
+	now = now.Add(time.Minute + time.Nanosecond)
+	if _, ok := c.Get("ttl"); ok {
+		t.Errorf("error on MemoryCache, the expired value is returned")
+	}
+
+	if c := NewMemoryCacheWithSize(0); c.maxItems != memoryCacheDefaultMaxItems {
+		t.Errorf("error on NewMemoryCacheWithSize, max=%d", c.maxItems)
+	}
+}
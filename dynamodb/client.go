@@ -4,20 +4,34 @@ package dynamodb
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	AWS "github.com/awslabs/aws-sdk-go/aws"
 	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+	"golang.org/x/time/rate"
 
 	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 )
 
 const (
+	serviceName               = "DynamoDB"
 	dynamodbConfigSectionName = "dynamodb"
 	defaultRegion             = "us-east-1"
 	defaultEndpoint           = "http://localhost:8000"
 	defaultTablePrefix        = "dev_"
+
+	tableStatusActive = "ACTIVE"
+
+	// polling interval for waiting the table status
+	tableWaitInterval    = 100 * time.Millisecond
+	tableWaitMaxInterval = 5 * time.Second
 )
 
 // wrapped struct for DynamoDB
@@ -25,32 +39,124 @@ type AmazonDynamoDB struct {
 	client      *SDK.DynamoDB
 	tables      map[string]*DynamoTable
 	writeTables map[string]bool
+
+	schemaCacheTTL     time.Duration
+	defaultShouldRetry func(*AWS.Request) bool
+	retryDefaultSaved  bool
+
+	// default throughput for CreateTable, used when the input does not have ProvisionedThroughput
+	defaultReadCapacity  int64
+	defaultWriteCapacity int64
+
+	// clock for the schema cache and TTL
+	now func() time.Time
+
+	throttleHandler ThrottleHandler
+
+	// backoff of the retries, nil means the default backoff of each operation
+	backoff *auth.Backoff
+
+	scanGuard ScanGuardMode
+
+	// client-side rate limit of the requests
+	limiter auth.RateLimiterHolder
+
+	// options of the conversion between the values and AttributeValue on the tables
+	marshalOptions *marshalOptions
 }
 
+// RetryClassifier decides if the error is retryable, in addition to the default retry rules
+type RetryClassifier func(err error) bool
+
 // Create new AmazonDynamoDB struct
 func NewClient() *AmazonDynamoDB {
-	d := &AmazonDynamoDB{}
-	d.tables = make(map[string]*DynamoTable)
-	d.writeTables = make(map[string]bool)
-
-	region := config.GetConfigValue(dynamodbConfigSectionName, "region", "")
+	region, regionErr := auth.ResolveRegion(dynamodbConfigSectionName)
 	awsConf := auth.NewConfig(region)
 	endpoint := config.GetConfigValue(dynamodbConfigSectionName, "endpoint", "")
 	switch {
 	case endpoint != "":
 		awsConf.Endpoint = endpoint
 	case region == "":
+		log.Warn("[DynamoDB] "+regionErr.Error()+", use the local endpoint", defaultEndpoint)
 		awsConf.Region = defaultRegion
 		awsConf.Endpoint = defaultEndpoint
 	}
+	return NewClientWithConfig(awsConf)
+}
+
+// NewClientWithConfig creates new AmazonDynamoDB struct with the AWS config,
+// the region, the endpoint and the credentials of the config file are not used
+func NewClientWithConfig(awsConf *AWS.Config) *AmazonDynamoDB {
+	d := &AmazonDynamoDB{}
+	d.tables = make(map[string]*DynamoTable)
+	d.writeTables = make(map[string]bool)
+	d.now = time.Now
+	d.marshalOptions = &marshalOptions{}
+
 	d.client = SDK.New(awsConf)
+	auth.AddUserAgentSuffix(&d.client.Handlers, auth.UserAgentSuffix(dynamodbConfigSectionName))
+	d.SetBackoff(auth.DefaultBackoff())
+	auth.AddRateLimiter(&d.client.Handlers, d.limiter.Get)
+	d.SetRateLimiter(auth.ConfigRateLimiter(dynamodbConfigSectionName))
+
+	// cache expiration for the table description, 0 means no expiration
+	ttl, _ := strconv.Atoi(config.GetConfigValue(dynamodbConfigSectionName, "schema_cache_ttl", "0"))
+	d.schemaCacheTTL = time.Duration(ttl) * time.Second
+
+	// default throughput for CreateTable, 0 means no default value
+	read, _ := strconv.ParseInt(config.GetConfigValue(dynamodbConfigSectionName, "default_read_capacity", "0"), 10, 64)
+	write, _ := strconv.ParseInt(config.GetConfigValue(dynamodbConfigSectionName, "default_write_capacity", "0"), 10, 64)
+	d.SetDefaultThroughput(read, write)
 	return d
 }
 
+// SetDefaultThroughput sets the default read/write capacity units for CreateTable,
+// it's used for the table and the global secondary indexes when the input does not have ProvisionedThroughput
+func (d *AmazonDynamoDB) SetDefaultThroughput(read, write int64) {
+	d.defaultReadCapacity = read
+	d.defaultWriteCapacity = write
+}
+
+// SetBackoff sets the backoff of the retries by the SDK and the retries of the unprocessed items of the batch operations,
+// nil restores the default backoff of the batch operations (the SDK keeps the current rules)
+func (d *AmazonDynamoDB) SetBackoff(b *auth.Backoff) {
+	d.backoff = b
+	auth.SetRetryRules(d.client.Service, b)
+}
+
+// SetRateLimiter sets the limiter to wait before sending every request of the client, nil means no limit.
+// share the limiter between the clients to limit the total requests
+func (d *AmazonDynamoDB) SetRateLimiter(l *rate.Limiter) {
+	d.limiter.Set(l)
+}
+
+// SetRetryClassifier sets the classifier to retry the errors which are not retried by default rules
+// (default rules like throttling and 5xx errors are always retried, nil classifier restores default)
+func (d *AmazonDynamoDB) SetRetryClassifier(fn RetryClassifier) {
+	if !d.retryDefaultSaved {
+		d.defaultShouldRetry = d.client.ShouldRetry
+		d.retryDefaultSaved = true
+	}
+	shouldRetry := d.defaultShouldRetry
+	if fn == nil {
+		d.client.ShouldRetry = shouldRetry
+		return
+	}
+	d.client.ShouldRetry = func(r *AWS.Request) bool {
+		if shouldRetry != nil && shouldRetry(r) {
+			return true
+		}
+		return r.Error != nil && fn(r.Error)
+	}
+}
+
 // Create new DynamoDB table
 func (d *AmazonDynamoDB) CreateTable(in *SDK.CreateTableInput) error {
+	in = d.applyDefaultThroughput(in)
+	d.invalidateTableCache(*in.TableName)
 	data, err := d.client.CreateTable(in)
 	if err != nil {
+		err = wrapError("CreateTable", err)
 		log.Error("[DynamoDB] Error on `CreateTable` operation, table="+*in.TableName, err)
 		return err
 	}
@@ -58,13 +164,40 @@ func (d *AmazonDynamoDB) CreateTable(in *SDK.CreateTableInput) error {
 	return nil
 }
 
+// create a copy of CreateTableInput with the default throughput for the table and global secondary indexes,
+// the original input is not changed
+func (d *AmazonDynamoDB) applyDefaultThroughput(in *SDK.CreateTableInput) *SDK.CreateTableInput {
+	if d.defaultReadCapacity <= 0 || d.defaultWriteCapacity <= 0 {
+		return in
+	}
+
+	c := *in
+	if c.ProvisionedThroughput == nil {
+		c.ProvisionedThroughput = NewProvisionedThroughput(d.defaultReadCapacity, d.defaultWriteCapacity)
+	}
+	if len(in.GlobalSecondaryIndexes) > 0 {
+		c.GlobalSecondaryIndexes = make([]*SDK.GlobalSecondaryIndex, len(in.GlobalSecondaryIndexes))
+		for i, gsi := range in.GlobalSecondaryIndexes {
+			if gsi.ProvisionedThroughput == nil {
+				idx := *gsi
+				idx.ProvisionedThroughput = NewProvisionedThroughput(d.defaultReadCapacity, d.defaultWriteCapacity)
+				gsi = &idx
+			}
+			c.GlobalSecondaryIndexes[i] = gsi
+		}
+	}
+	return &c
+}
+
 // Delete DynamoDB table
 func (d *AmazonDynamoDB) DeleteTable(name string) error {
 	in := &SDK.DeleteTableInput{
 		TableName: String(name),
 	}
+	d.invalidateTableCache(name)
 	data, err := d.client.DeleteTable(in)
 	if err != nil {
+		err = wrapError("DeleteTable", err)
 		log.Error("[DynamoDB] Error on `DeleteTable` operation, table="+*in.TableName, err)
 		return err
 	}
@@ -78,18 +211,66 @@ func (d *AmazonDynamoDB) DescribeTable(name string) (*SDK.TableDescription, erro
 		TableName: String(name),
 	})
 	if err != nil {
+		err = wrapError("DescribeTable", err)
 		return nil, err
 	}
 	return req.Table, nil
 }
 
+// wait until the table and all of the global secondary indexes become ACTIVE,
+// returns error with the last status when it's not ACTIVE until the timeout
+func (d *AmazonDynamoDB) WaitUntilTableActive(name string, timeout time.Duration) error {
+	deadline := d.currentTime().Add(timeout)
+	wait := tableWaitInterval
+	for {
+		desc, err := d.DescribeTable(name)
+		if err != nil {
+			log.Error("[DynamoDB] Error on `DescribeTable` operation, table="+name, err)
+			return err
+		}
+		status, ok := tableStatus(desc)
+		if ok {
+			return nil
+		}
+
+		if d.currentTime().Add(wait).After(deadline) {
+			return fmt.Errorf("[DynamoDB] timeout on waiting for the table to be ACTIVE, table=%s, status=%s", name, status)
+		}
+		time.Sleep(wait)
+		wait *= 2
+		if wait > tableWaitMaxInterval {
+			wait = tableWaitMaxInterval
+		}
+	}
+}
+
+// get the status of the table and indexes, and check if all of them are ACTIVE
+func tableStatus(desc *SDK.TableDescription) (string, bool) {
+	var status string
+	if desc.TableStatus != nil {
+		status = *desc.TableStatus
+	}
+	active := status == tableStatusActive
+	for _, idx := range desc.GlobalSecondaryIndexes {
+		var idxStatus string
+		if idx.IndexStatus != nil {
+			idxStatus = *idx.IndexStatus
+		}
+		if idxStatus != tableStatusActive {
+			active = false
+		}
+		status += ", " + *idx.IndexName + "=" + idxStatus
+	}
+	return status, active
+}
+
 // get the DynamoDB table
 func (d *AmazonDynamoDB) GetTable(table string) (*DynamoTable, error) {
 	tableName := GetTablePrefix() + table
 
 	// get the table from cache
 	t, ok := d.tables[tableName]
-	if ok {
+	if ok && !d.isTableCacheExpired(t) {
 		return t, nil
 	}
 
@@ -98,20 +279,56 @@ func (d *AmazonDynamoDB) GetTable(table string) (*DynamoTable, error) {
 	if err != nil {
 		return nil, err
 	}
-	t = &DynamoTable{
-		db:      d,
-		table:   desc,
-		name:    tableName,
-		indexes: make(map[string]*DynamoIndex),
+	// refresh the cached table to keep write spool
+	if !ok {
+		t = &DynamoTable{
+			db:   d,
+			name: tableName,
+		}
+		d.tables[tableName] = t
+	}
+	t.setDescription(desc, d.currentTime())
+	return t, nil
+}
+
+// InvalidateSchema discards the cached table description,
+// use this after the table schema is changed out of this client
+func (d *AmazonDynamoDB) InvalidateSchema(table string) {
+	d.invalidateTableCache(GetTablePrefix() + table)
+}
+
+// mark the cached table description to be reloaded on next GetTable
+func (d *AmazonDynamoDB) invalidateTableCache(name string) {
+	if t, ok := d.tables[name]; ok {
+		t.describedAt = time.Time{}
 	}
-	for _, idx := range desc.LocalSecondaryIndexes {
-		t.indexes[*idx.IndexName] = NewDynamoIndex(*idx.IndexName, indexTypeLSI, idx.KeySchema)
+}
+
+// check if the cached table description is expired or invalidated
+func (d *AmazonDynamoDB) isTableCacheExpired(t *DynamoTable) bool {
+	switch {
+	case t.describedAt.IsZero():
+		return true
+	case d.schemaCacheTTL > 0:
+		return d.currentTime().Sub(t.describedAt) > d.schemaCacheTTL
 	}
-	for _, idx := range desc.GlobalSecondaryIndexes {
-		t.indexes[*idx.IndexName] = NewDynamoIndex(*idx.IndexName, indexTypeGSI, idx.KeySchema)
+	return false
+}
+
+// get the current time from the clock of the client
+func (d *AmazonDynamoDB) currentTime() time.Time {
+	if d.now == nil {
+		return time.Now()
 	}
-	d.tables[tableName] = t
-	return t, nil
+	return d.now()
+}
+
+// get the marshal options of the client
+func (d *AmazonDynamoDB) options() *marshalOptions {
+	if d.marshalOptions == nil {
+		return defaultMarshalOptions
+	}
+	return d.marshalOptions
 }
 
 // add the table to write spool
@@ -148,9 +365,25 @@ func GetTablePrefix() string {
 
 // get the list of DynamoDB table
 func (d *AmazonDynamoDB) ListTables() ([]*string, error) {
-	res, err := d.client.ListTables(&SDK.ListTablesInput{})
+	var names []*string
+	in := &SDK.ListTablesInput{}
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartTableName, _ = token.(*string)
+		res, err := d.client.ListTables(in)
+		if err != nil {
+			return nil, false, wrapError("ListTables", err)
+		}
+		names = append(names, res.TableNames...)
+		last := res.LastEvaluatedTableName
+		return last, last == nil || *last == "", nil
+	})
 	if err != nil {
 		return make([]*string, 0, 0), err
 	}
-	return res.TableNames, nil
+	return names, nil
+}
+
+// wrap the error of the operation with the request ID
+func wrapError(op string, err error) error {
+	return awserror.New(serviceName, op, err)
 }
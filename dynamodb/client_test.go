@@ -1,10 +1,12 @@
 package dynamodb
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
 
+	AWS "github.com/awslabs/aws-sdk-go/aws"
 	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
 )
 
@@ -26,6 +28,42 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestSetRetryClassifier(t *testing.T) {
+	setTestEnv()
+
+	c := NewClient()
+	appErr := errors.New("app transient error")
+	req := &AWS.Request{Service: c.client.Service, Error: appErr}
+	if c.client.ShouldRetry != nil && c.client.ShouldRetry(req) {
+		t.Errorf("error on SetRetryClassifier, default rule retries %v", req.Error)
+	}
+
+	c.SetRetryClassifier(func(err error) bool {
+		return err == appErr
+	})
+	if !c.client.ShouldRetry(req) {
+		t.Errorf("error on SetRetryClassifier, classifier is not used")
+	}
+	req.Error = errors.New("other error")
+	if c.client.ShouldRetry(req) {
+		t.Errorf("error on SetRetryClassifier, %v", req.Error)
+	}
+
+	// the classifier replaces the previous one
+	c.SetRetryClassifier(func(err error) bool {
+		return false
+	})
+	req.Error = appErr
+	if c.client.ShouldRetry(req) {
+		t.Errorf("error on SetRetryClassifier, the previous classifier is used")
+	}
+
+	c.SetRetryClassifier(nil)
+	if c.client.ShouldRetry != nil && c.client.ShouldRetry(req) {
+		t.Errorf("error on SetRetryClassifier, classifier is not removed")
+	}
+}
+
 func TestCreateTable(t *testing.T) {
 	setTestEnv()
 
@@ -74,6 +112,44 @@ func TestDescribeTable(t *testing.T) {
 	}
 }
 
+func TestWaitUntilTableActive(t *testing.T) {
+	setTestEnv()
+
+	c := NewClient()
+	name := "foo_table"
+	in := getCreateTableInput(GetTablePrefix() + name)
+	createTable(c, in)
+
+	err := c.WaitUntilTableActive(*in.TableName, 10*time.Second)
+	if err != nil {
+		t.Errorf("error on WaitUntilTableActive, %s", err.Error())
+	}
+
+	err = c.WaitUntilTableActive("non_exist_table", time.Second)
+	if err == nil {
+		t.Errorf("error on WaitUntilTableActive, error must be returned")
+	}
+}
+
+func TestTableStatus(t *testing.T) {
+	desc := &SDK.TableDescription{
+		TableStatus: String("ACTIVE"),
+		GlobalSecondaryIndexes: []*SDK.GlobalSecondaryIndexDescription{
+			{IndexName: String("gsi1"), IndexStatus: String("CREATING")},
+		},
+	}
+	status, ok := tableStatus(desc)
+	if ok || status != "ACTIVE, gsi1=CREATING" {
+		t.Errorf("error on tableStatus, status=%s", status)
+	}
+
+	desc.GlobalSecondaryIndexes[0].IndexStatus = String("ACTIVE")
+	status, ok = tableStatus(desc)
+	if !ok || status != "ACTIVE, gsi1=ACTIVE" {
+		t.Errorf("error on tableStatus, status=%s", status)
+	}
+}
+
 func TestGetTable(t *testing.T) {
 	setTestEnv()
 
@@ -97,6 +173,115 @@ func TestGetTable(t *testing.T) {
 	}
 }
 
+func TestInvalidateSchema(t *testing.T) {
+	setTestEnv()
+
+	c := NewClient()
+	name := "foo_table"
+	createTable(c, getCreateTableInput(GetTablePrefix()+name))
+
+	tbl, err := c.GetTable(name)
+	if err != nil {
+		t.Errorf("error on GetTable, %s", err.Error())
+	}
+	describedAt := tbl.describedAt
+	if describedAt.IsZero() || c.isTableCacheExpired(tbl) {
+		t.Errorf("error on GetTable, %v", tbl)
+	}
+
+	item := NewItem()
+	item.AddAttribute("id", 100)
+	tbl.AddItem(item)
+
+	c.InvalidateSchema(name)
+	if !c.isTableCacheExpired(tbl) {
+		t.Errorf("error on InvalidateSchema, %v", tbl)
+	}
+
+	tbl2, err := c.GetTable(name)
+	if err != nil {
+		t.Errorf("error on GetTable, %s", err.Error())
+	}
+	if tbl2 != tbl || len(tbl2.writeItems) != 1 {
+		t.Errorf("error on GetTable, write spool is lost: %v", tbl2)
+	}
+	if c.isTableCacheExpired(tbl2) || !tbl2.describedAt.After(describedAt) {
+		t.Errorf("error on GetTable, %v", tbl2)
+	}
+}
+
+func TestIsTableCacheExpired(t *testing.T) {
+	setTestEnv()
+
+	c := NewClient()
+	now := time.Unix(1435000000, 0)
+	c.setNow(func() time.Time { return now })
+	tbl := &DynamoTable{describedAt: now}
+	if c.isTableCacheExpired(tbl) {
+		t.Errorf("error on isTableCacheExpired, ttl=%v", c.schemaCacheTTL)
+	}
+
+	// no expiration without ttl
+	now = now.Add(time.Hour)
+	if c.isTableCacheExpired(tbl) {
+		t.Errorf("error on isTableCacheExpired, ttl=%v", c.schemaCacheTTL)
+	}
+
+	c.schemaCacheTTL = time.Minute
+	if !c.isTableCacheExpired(tbl) {
+		t.Errorf("error on isTableCacheExpired, ttl=%v", c.schemaCacheTTL)
+	}
+	tbl.describedAt = now.Add(-time.Minute)
+	if c.isTableCacheExpired(tbl) {
+		t.Errorf("error on isTableCacheExpired, ttl=%v", c.schemaCacheTTL)
+	}
+	now = now.Add(time.Second)
+	if !c.isTableCacheExpired(tbl) {
+		t.Errorf("error on isTableCacheExpired, ttl=%v", c.schemaCacheTTL)
+	}
+}
+
+// set the clock of the client for testing
+func (d *AmazonDynamoDB) setNow(fn func() time.Time) {
+	d.now = fn
+}
+
+func TestApplyDefaultThroughput(t *testing.T) {
+	setTestEnv()
+
+	c := NewClient()
+	in := getCreateTableInput("foo_table")
+	in.ProvisionedThroughput = nil
+	in.GlobalSecondaryIndexes[0].ProvisionedThroughput = nil
+
+	// no default
+	out := c.applyDefaultThroughput(&in)
+	if out.ProvisionedThroughput != nil {
+		t.Errorf("error on applyDefaultThroughput, %v", out)
+	}
+
+	c.SetDefaultThroughput(3, 2)
+	out = c.applyDefaultThroughput(&in)
+	tp := out.ProvisionedThroughput
+	if tp == nil || *tp.ReadCapacityUnits != 3 || *tp.WriteCapacityUnits != 2 {
+		t.Errorf("error on applyDefaultThroughput, %v", tp)
+	}
+	tp = out.GlobalSecondaryIndexes[0].ProvisionedThroughput
+	if tp == nil || *tp.ReadCapacityUnits != 3 || *tp.WriteCapacityUnits != 2 {
+		t.Errorf("error on applyDefaultThroughput, %v", tp)
+	}
+	if in.ProvisionedThroughput != nil || in.GlobalSecondaryIndexes[0].ProvisionedThroughput != nil {
+		t.Errorf("error on applyDefaultThroughput, input must not be changed, %v", in)
+	}
+
+	// explicit throughput is used
+	in.ProvisionedThroughput = NewProvisionedThroughput(1, 1)
+	out = c.applyDefaultThroughput(&in)
+	if *out.ProvisionedThroughput.ReadCapacityUnits != 1 {
+		t.Errorf("error on applyDefaultThroughput, %v", out.ProvisionedThroughput)
+	}
+}
+
 func TestGetTablePrefix(t *testing.T) {
 	setTestEnv()
 
@@ -256,6 +441,38 @@ func getCreateHashTableInput(name string) SDK.CreateTableInput {
 	}
 }
 
+func getCreateStringRangeTableInput(name string) SDK.CreateTableInput {
+	pKey := NewKeySchema(
+		NewHashKeyElement("id"),
+		NewRangeKeyElement("name"))
+
+	attrs := NewAttributeDefinitions(
+		NewNumberAttribute("id"),
+		NewStringAttribute("name"))
+	return SDK.CreateTableInput{
+		TableName:             &name,
+		KeySchema:             pKey,
+		AttributeDefinitions:  attrs,
+		ProvisionedThroughput: NewProvisionedThroughput(1, 1),
+	}
+}
+
+func getCreateBinaryRangeTableInput(name string) SDK.CreateTableInput {
+	pKey := NewKeySchema(
+		NewHashKeyElement("id"),
+		NewRangeKeyElement("data"))
+
+	attrs := NewAttributeDefinitions(
+		NewNumberAttribute("id"),
+		NewByteAttribute("data"))
+	return SDK.CreateTableInput{
+		TableName:             &name,
+		KeySchema:             pKey,
+		AttributeDefinitions:  attrs,
+		ProvisionedThroughput: NewProvisionedThroughput(1, 1),
+	}
+}
+
 func resetTable(c *AmazonDynamoDB, name string) {
 	desc, _ := c.client.DescribeTable(&SDK.DescribeTableInput{
 		TableName: &name,
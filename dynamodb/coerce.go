@@ -0,0 +1,98 @@
+// DynamoDB type coercion for the untyped data
+
+package dynamodb
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// MarshalWithSchema converts the untyped data (e.g. decoded JSON) to DynamoDB Item data,
+// the attributes in schema are coerced to the type of "N", "S" or "BOOL" before marshaling,
+// like "42" to number and "true" to bool. returns error for the value which cannot be coerced.
+// nil value and the attributes not in schema are marshaled as they are
+func MarshalWithSchema(data map[string]interface{}, schema map[string]string) (*map[string]*SDK.AttributeValue, error) {
+	item := make(map[string]interface{}, len(data))
+	for key, val := range data {
+		typ, ok := schema[key]
+		if !ok || val == nil {
+			item[key] = val
+			continue
+		}
+		v, err := coerceValue(val, typ)
+		if err != nil {
+			return nil, fmt.Errorf("[DynamoDB] %s, attribute=%s", err.Error(), key)
+		}
+		item[key] = v
+	}
+	return MarshalWithError(item)
+}
+
+// coerce the value to the type of "N", "S" or "BOOL"
+func coerceValue(v Any, typ string) (Any, error) {
+	switch strings.ToUpper(typ) {
+	case "N":
+		return coerceNumber(v)
+	case "S":
+		return coerceString(v)
+	case "BOOL":
+		return coerceBool(v)
+	}
+	return nil, fmt.Errorf("unsupported schema type=%s", typ)
+}
+
+// coerce the value to int64 or float64
+func coerceNumber(v Any) (Any, error) {
+	switch t := v.(type) {
+	case int, int32, int64, uint, uint32, uint64, float32:
+		return t, nil
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			break
+		}
+		return t, nil
+	case string:
+		s := strings.TrimSpace(t)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot coerce the value to N, value=%v", v)
+}
+
+// coerce the value to string
+func coerceString(v Any) (Any, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32), nil
+	case int, int32, int64, uint, uint32, uint64:
+		return fmt.Sprint(t), nil
+	}
+	return nil, fmt.Errorf("cannot coerce the value to S, value=%v", v)
+}
+
+// coerce the value to bool, the string is parsed by strconv.ParseBool
+func coerceBool(v Any) (Any, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(t)); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot coerce the value to BOOL, value=%v", v)
+}
@@ -0,0 +1,67 @@
+package dynamodb
+
+import (
+	"testing"
+)
+
+func TestMarshalWithSchema(t *testing.T) {
+	data := map[string]interface{}{
+		"id":     "42",
+		"price":  "1.5",
+		"code":   float64(100),
+		"active": "true",
+		"memo":   "foo",
+		"empty":  nil,
+	}
+	schema := map[string]string{
+		"id":     "N",
+		"price":  "N",
+		"code":   "S",
+		"active": "BOOL",
+		"empty":  "N",
+	}
+	item, err := MarshalWithSchema(data, schema)
+	if err != nil {
+		t.Fatalf("error on MarshalWithSchema, %s", err.Error())
+	}
+	m := *item
+	if m["id"].N == nil || *m["id"].N != "42" {
+		t.Errorf("error on MarshalWithSchema, id=%+v", m["id"])
+	}
+	if m["price"].N == nil || *m["price"].N != "1.5" {
+		t.Errorf("error on MarshalWithSchema, price=%+v", m["price"])
+	}
+	if m["code"].S == nil || *m["code"].S != "100" {
+		t.Errorf("error on MarshalWithSchema, code=%+v", m["code"])
+	}
+	if m["active"].BOOL == nil || !*m["active"].BOOL {
+		t.Errorf("error on MarshalWithSchema, active=%+v", m["active"])
+	}
+	if m["memo"].S == nil || *m["memo"].S != "foo" {
+		t.Errorf("error on MarshalWithSchema, memo=%+v", m["memo"])
+	}
+	if m["empty"].NULL == nil || !*m["empty"].NULL {
+		t.Errorf("error on MarshalWithSchema, empty=%+v", m["empty"])
+	}
+}
+
+func TestMarshalWithSchemaError(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		typ   string
+	}{
+		{"abc", "N"},
+		{"NaN", "N"},
+		{true, "N"},
+		{"yes", "BOOL"},
+		{1, "BOOL"},
+		{[]string{"a"}, "S"},
+		{"foo", "SS"},
+	}
+	for _, tt := range tests {
+		_, err := MarshalWithSchema(map[string]interface{}{"attr": tt.value}, map[string]string{"attr": tt.typ})
+		if err == nil {
+			t.Errorf("error on MarshalWithSchema, value=%v, type=%s", tt.value, tt.typ)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// DynamoDB composite sort key for the single table design
+
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	compositeKeySeparator = "#"
+
+	// the width of the number to hold the max value of uint64
+	defaultCompositeKeyNumberWidth = 20
+
+	// the fixed width layout of the time, RFC3339Nano trims the trailing zeros and breaks the ordering
+	compositeKeyTimeLayout = "2006-01-02T15:04:05.000000000Z"
+)
+
+// CompositeKeyEncoder builds the composite key like `ORDER#2024-01-02T00:00:00.000000000Z#00000000000000000042`,
+// the lexicographic order of the key matches the logical order of the values.
+// the non-negative integer is zero-padded to the number width and the time is formatted in UTC with the fixed width.
+type CompositeKeyEncoder struct {
+	parts       []string
+	numberWidth int
+	err         error
+}
+
+// Create new CompositeKeyEncoder struct
+func NewCompositeKeyEncoder() *CompositeKeyEncoder {
+	return &CompositeKeyEncoder{
+		numberWidth: defaultCompositeKeyNumberWidth,
+	}
+}
+
+// SetNumberWidth sets the width of the zero-padded number (default is 20),
+// the same width must be used for all of the keys to compare
+func (e *CompositeKeyEncoder) SetNumberWidth(width int) {
+	e.numberWidth = width
+}
+
+// Add adds the value to the key, the value must be string without the separator, non-negative integer or time.Time
+func (e *CompositeKeyEncoder) Add(value interface{}) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		if strings.Contains(v, compositeKeySeparator) {
+			e.setError(errors.New("[DynamoDB] the value of the composite key cannot contain the separator, value=" + v))
+			return
+		}
+		s = v
+	case int, int8, int16, int32, int64:
+		n := reflect.ValueOf(v).Int()
+		if n < 0 {
+			e.setError(fmt.Errorf("[DynamoDB] the number of the composite key must not be negative, value=%d", n))
+			return
+		}
+		s = e.padNumber(strconv.FormatInt(n, 10))
+	case uint, uint8, uint16, uint32, uint64:
+		s = e.padNumber(strconv.FormatUint(reflect.ValueOf(v).Uint(), 10))
+	case time.Time:
+		s = v.UTC().Format(compositeKeyTimeLayout)
+	default:
+		e.setError(fmt.Errorf("[DynamoDB] unsupported type for the composite key, type=%T", value))
+		return
+	}
+	e.parts = append(e.parts, s)
+}
+
+// zero-pad the number to the width
+func (e *CompositeKeyEncoder) padNumber(s string) string {
+	if len(s) > e.numberWidth {
+		e.setError(fmt.Errorf("[DynamoDB] the number of the composite key is over the width, value=%s, width=%d", s, e.numberWidth))
+		return s
+	}
+	return strings.Repeat("0", e.numberWidth-len(s)) + s
+}
+
+// keep the first error
+func (e *CompositeKeyEncoder) setError(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// String returns the composite key
+func (e *CompositeKeyEncoder) String() string {
+	return strings.Join(e.parts, compositeKeySeparator)
+}
+
+// Error returns the first error of Add
+func (e *CompositeKeyEncoder) Error() error {
+	return e.err
+}
+
+// CompositeKeyDecoder reads the values of the composite key from CompositeKeyEncoder in order of Add
+type CompositeKeyDecoder struct {
+	parts []string
+	pos   int
+}
+
+// Create new CompositeKeyDecoder struct
+func NewCompositeKeyDecoder(key string) *CompositeKeyDecoder {
+	return &CompositeKeyDecoder{
+		parts: strings.Split(key, compositeKeySeparator),
+	}
+}
+
+// Len returns the number of the remaining values
+func (d *CompositeKeyDecoder) Len() int {
+	return len(d.parts) - d.pos
+}
+
+// get the next part of the key
+func (d *CompositeKeyDecoder) next() (string, error) {
+	if d.pos >= len(d.parts) {
+		return "", errors.New("[DynamoDB] no more value in the composite key")
+	}
+	s := d.parts[d.pos]
+	d.pos++
+	return s, nil
+}
+
+// Text reads the next value as string
+func (d *CompositeKeyDecoder) Text() (string, error) {
+	return d.next()
+}
+
+// Int reads the next value as the zero-padded integer
+func (d *CompositeKeyDecoder) Int() (int64, error) {
+	s, err := d.next()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// Uint reads the next value as the zero-padded unsigned integer
+func (d *CompositeKeyDecoder) Uint() (uint64, error) {
+	s, err := d.next()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// Time reads the next value as the time in UTC
+func (d *CompositeKeyDecoder) Time() (time.Time, error) {
+	s, err := d.next()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(compositeKeyTimeLayout, s)
+}
@@ -0,0 +1,107 @@
+package dynamodb
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCompositeKeyEncoder(t *testing.T) {
+	at := time.Date(2024, 1, 2, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	e := NewCompositeKeyEncoder()
+	e.SetNumberWidth(5)
+	e.Add("ORDER")
+	e.Add(at)
+	e.Add(42)
+	e.Add(uint8(7))
+	if e.Error() != nil {
+		t.Errorf("error on CompositeKeyEncoder, %s", e.Error().Error())
+	}
+	if key := e.String(); key != "ORDER#2024-01-02T00:00:00.000000000Z#00042#00007" {
+		t.Errorf("error on CompositeKeyEncoder, %s", key)
+	}
+
+	// the lexicographic order matches the logical order
+	var keys []string
+	for _, n := range []int{100, 9, 42} {
+		e := NewCompositeKeyEncoder()
+		e.Add("ORDER")
+		e.Add(n)
+		keys = append(keys, e.String())
+	}
+	sort.Strings(keys)
+	if keys[0] != "ORDER#00000000000000000009" || keys[2] != "ORDER#00000000000000000100" {
+		t.Errorf("error on CompositeKeyEncoder, %v", keys)
+	}
+	base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	t1 := NewCompositeKeyEncoder()
+	t1.Add(base)
+	t2 := NewCompositeKeyEncoder()
+	t2.Add(base.Add(100 * time.Millisecond))
+	if t1.String() >= t2.String() {
+		t.Errorf("error on CompositeKeyEncoder, %s, %s", t1.String(), t2.String())
+	}
+}
+
+func TestCompositeKeyEncoderError(t *testing.T) {
+	tests := []interface{}{
+		"A#B",
+		-1,
+		1.5,
+		true,
+		nil,
+	}
+	for _, v := range tests {
+		e := NewCompositeKeyEncoder()
+		e.Add(v)
+		if e.Error() == nil {
+			t.Errorf("error on CompositeKeyEncoder, error must be returned, value=%v", v)
+		}
+	}
+
+	e := NewCompositeKeyEncoder()
+	e.SetNumberWidth(2)
+	e.Add(100)
+	if e.Error() == nil {
+		t.Errorf("error on CompositeKeyEncoder, the number over the width must be error")
+	}
+}
+
+func TestCompositeKeyDecoder(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	e := NewCompositeKeyEncoder()
+	e.Add("ORDER")
+	e.Add(at)
+	e.Add(int64(42))
+	e.Add(uint64(18446744073709551615))
+
+	d := NewCompositeKeyDecoder(e.String())
+	if d.Len() != 4 {
+		t.Errorf("error on CompositeKeyDecoder, %d", d.Len())
+	}
+	s, err := d.Text()
+	if err != nil || s != "ORDER" {
+		t.Errorf("error on CompositeKeyDecoder, %s, %v", s, err)
+	}
+	tm, err := d.Time()
+	if err != nil || !tm.Equal(at) {
+		t.Errorf("error on CompositeKeyDecoder, %v, %v", tm, err)
+	}
+	n, err := d.Int()
+	if err != nil || n != 42 {
+		t.Errorf("error on CompositeKeyDecoder, %d, %v", n, err)
+	}
+	u, err := d.Uint()
+	if err != nil || u != 18446744073709551615 {
+		t.Errorf("error on CompositeKeyDecoder, %d, %v", u, err)
+	}
+	if _, err := d.Text(); err == nil || d.Len() != 0 {
+		t.Errorf("error on CompositeKeyDecoder, no more value must be error")
+	}
+
+	d = NewCompositeKeyDecoder("ORDER#foo")
+	d.Text()
+	if _, err := d.Int(); err == nil {
+		t.Errorf("error on CompositeKeyDecoder, invalid number must be error")
+	}
+}
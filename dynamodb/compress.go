@@ -0,0 +1,79 @@
+// DynamoDB attribute compression
+
+package dynamodb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// the prefix of the compressed binary(B) attribute
+const compressMarker = "\x00gz\x00"
+
+var (
+	// names of the attributes to compress
+	compressAttributes map[string]bool
+
+	// minimum size of the value to compress
+	compressThreshold int
+)
+
+// SetCompressAttributes sets the attributes whose string value is stored as gzip compressed binary(B),
+// the value smaller than threshold bytes is stored as it is. no names disables the compression.
+// the compressed attribute is decompressed on Unmarshal, but cannot be used in the expressions and conditions
+func SetCompressAttributes(threshold int, names ...string) {
+	compressThreshold = threshold
+	compressAttributes = nil
+	if len(names) == 0 {
+		return
+	}
+	compressAttributes = make(map[string]bool, len(names))
+	for _, name := range names {
+		compressAttributes[name] = true
+	}
+}
+
+// create compressed binary(B) AttributeValue when the attribute is set to compress,
+// returns false for the other attributes and the small value
+func compressAttributeValue(name string, v Any) (*SDK.AttributeValue, bool) {
+	if !compressAttributes[name] {
+		return nil, false
+	}
+	s, ok := v.(string)
+	if !ok || len(s) < compressThreshold {
+		return nil, false
+	}
+
+	buf := bytes.NewBufferString(compressMarker)
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return nil, false
+	}
+	if err := w.Close(); err != nil {
+		return nil, false
+	}
+	return &SDK.AttributeValue{
+		B: buf.Bytes(),
+	}, true
+}
+
+// retrieve the string value from the compressed binary(B) AttributeValue,
+// returns false when the value is not compressed
+func decompressAttributeValue(val *SDK.AttributeValue) (string, bool) {
+	if val == nil || !bytes.HasPrefix(val.B, []byte(compressMarker)) {
+		return "", false
+	}
+	r, err := gzip.NewReader(bytes.NewReader(val.B[len(compressMarker):]))
+	if err != nil {
+		return "", false
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,67 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetCompressAttributes(t *testing.T) {
+	SetCompressAttributes(10, "body")
+	defer SetCompressAttributes(0)
+
+	long := strings.Repeat("long text ", 100)
+	item := Marshal(map[string]interface{}{
+		"body":  long,
+		"title": long,
+	})
+	body := (*item)["body"]
+	if body.B == nil || body.S != nil || len(body.B) >= len(long) {
+		t.Errorf("error on compress, %v", body)
+	}
+	if title := (*item)["title"]; title.S == nil || *title.S != long {
+		t.Errorf("error on compress, non target attribute must not be compressed, %v", title)
+	}
+
+	data := Unmarshal(item)
+	if data["body"] != long || data["title"] != long {
+		t.Errorf("error on decompress, %v", data)
+	}
+	if subset := UnmarshalSubset(item, "body"); subset["body"] != long {
+		t.Errorf("error on decompress, %v", subset)
+	}
+
+	// small value
+	item = Marshal(map[string]interface{}{"body": "short"})
+	if body := (*item)["body"]; body.S == nil || *body.S != "short" {
+		t.Errorf("error on compress, small value must not be compressed, %v", body)
+	}
+
+	// DynamoItem
+	dItem := NewItem()
+	dItem.AddAttribute("body", long)
+	if dItem.data["body"].B == nil {
+		t.Errorf("error on compress, %v", dItem.data["body"])
+	}
+
+	// disabled, compressed value is still decompressed
+	SetCompressAttributes(0)
+	if data := Unmarshal(&dItem.data); data["body"] != long {
+		t.Errorf("error on decompress, %v", data)
+	}
+	item = Marshal(map[string]interface{}{"body": long})
+	if (*item)["body"].S == nil {
+		t.Errorf("error on compress, %v", (*item)["body"])
+	}
+}
+
+func TestDecompressAttributeValue(t *testing.T) {
+	if _, ok := decompressAttributeValue(createAttributeValue([]byte("foo"))); ok {
+		t.Errorf("error on decompressAttributeValue, binary without marker must not be decompressed")
+	}
+	if _, ok := decompressAttributeValue(createAttributeValue([]byte(compressMarker + "broken"))); ok {
+		t.Errorf("error on decompressAttributeValue, broken data must not be decompressed")
+	}
+	if _, ok := decompressAttributeValue(nil); ok {
+		t.Errorf("error on decompressAttributeValue, nil must not be decompressed")
+	}
+}
@@ -0,0 +1,41 @@
+// DynamoDB max-in-flight limit for the parallel operations
+
+package dynamodb
+
+import "sync"
+
+// default max number of the concurrent requests on the parallel operations
+const defaultConcurrency = 8
+
+// SetConcurrency sets the max number of the concurrent requests on the parallel operations of the table,
+// like the segments of DeleteAll, CopyTable and Sample, the shards of QueryAllShards and the chunks of BatchGet.
+// n=0 uses the default value
+func (t *DynamoTable) SetConcurrency(n int) {
+	t.concurrency = n
+}
+
+// get the max number of the concurrent requests
+func (t *DynamoTable) maxConcurrency() int {
+	if t.concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return t.concurrency
+}
+
+// execute fn for [0, n) in parallel up to the max concurrency, and wait for all of them
+func (t *DynamoTable) parallel(n int, fn func(i int)) {
+	sem := make(chan struct{}, t.maxConcurrency())
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,42 @@
+package dynamodb
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	tbl := &DynamoTable{}
+	if n := tbl.maxConcurrency(); n != defaultConcurrency {
+		t.Errorf("error on maxConcurrency, %d", n)
+	}
+
+	tbl.SetConcurrency(2)
+	var mu sync.Mutex
+	var running, maxRunning int
+	done := make([]bool, 10)
+	tbl.parallel(10, func(i int) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		done[i] = true
+		mu.Unlock()
+	})
+	if maxRunning > 2 {
+		t.Errorf("error on parallel, max running=%d", maxRunning)
+	}
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("error on parallel, %d is not executed", i)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// DynamoDB table copy for the migration
+
+package dynamodb
+
+import (
+	"sync"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// default number of segments for parallel scan on CopyTable
+const copyTableSegments = 4
+
+// CopyTableOption is the option of CopyTableWithOption
+type CopyTableOption struct {
+	// number of segments for parallel scan, the default is 4.
+	// the same number must be used to resume from the checkpoints
+	Segments int
+
+	// Progress is called with the checkpoint of the segment after every page is copied,
+	// the calls are serialized between the segments
+	Progress func(CopyCheckpoint)
+
+	// Resume is the last checkpoints of the segments from Progress, to resume the copy
+	Resume []CopyCheckpoint
+}
+
+// CopyCheckpoint is the resumable position of the segment of CopyTable
+type CopyCheckpoint struct {
+	Segment int
+
+	// number of items copied in the segment
+	Copied int
+
+	// LastKey is the last evaluated key of the scan, nil when the segment is not started
+	LastKey *map[string]*SDK.AttributeValue
+
+	// Done is true when all of the items in the segment are copied
+	Done bool
+}
+
+// CopyTable copies all of the items in src table to dst table by parallel scan and BatchWriteItem,
+// transform converts the item before the write (optional), and the item is skipped when it returns nil
+func (d *AmazonDynamoDB) CopyTable(src, dst string, transform func(map[string]interface{}) map[string]interface{}) error {
+	return d.CopyTableWithOption(src, dst, transform, CopyTableOption{})
+}
+
+// CopyTableWithOption performs CopyTable with the progress callback and the checkpoints to resume
+func (d *AmazonDynamoDB) CopyTableWithOption(src, dst string, transform func(map[string]interface{}) map[string]interface{}, opt CopyTableOption) error {
+	srcTable, err := d.GetTable(src)
+	if err != nil {
+		return err
+	}
+	dstTable, err := d.GetTable(dst)
+	if err != nil {
+		return err
+	}
+
+	segments := opt.Segments
+	if segments <= 0 {
+		segments = copyTableSegments
+	}
+	checkpoints := make([]CopyCheckpoint, segments)
+	for i := range checkpoints {
+		checkpoints[i].Segment = i
+	}
+	for _, cp := range opt.Resume {
+		if cp.Segment >= 0 && cp.Segment < segments {
+			checkpoints[cp.Segment] = cp
+		}
+	}
+
+	var mu sync.Mutex
+	report := func(cp CopyCheckpoint) {
+		if opt.Progress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		opt.Progress(cp)
+	}
+
+	errs := make([]error, segments)
+	srcTable.parallel(segments, func(segment int) {
+		errs[segment] = copySegment(srcTable, dstTable, transform, checkpoints[segment], segments, report)
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	log.Info("[DynamoDB] CopyTable, src="+srcTable.name+", dst="+dstTable.name+", segments=", segments)
+	return nil
+}
+
+// scan the items in the segment from the checkpoint and write them for every 25 items
+func copySegment(src, dst *DynamoTable, transform func(map[string]interface{}) map[string]interface{}, cp CopyCheckpoint, total int, report func(CopyCheckpoint)) error {
+	if cp.Done {
+		return nil
+	}
+	in := &SDK.ScanInput{
+		TableName:     String(src.name),
+		Segment:       Long(int64(cp.Segment)),
+		TotalSegments: Long(int64(total)),
+	}
+
+	return pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		if token == nil {
+			in.ExclusiveStartKey = cp.LastKey
+		}
+		res, err := src.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			src.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+src.name, err)
+			return nil, false, err
+		}
+
+		items := transformItems(src.options(), dst.options(), res.Items, transform)
+		for i := 0; i < len(items); i += batchWriteMaxItems {
+			end := i + batchWriteMaxItems
+			if end > len(items) {
+				end = len(items)
+			}
+			if err := dst.batchPutItems(items[i:end]); err != nil {
+				return nil, false, err
+			}
+		}
+
+		next, done := nextPageKey(res.LastEvaluatedKey)
+		cp.Copied += len(items)
+		cp.LastKey = res.LastEvaluatedKey
+		cp.Done = done
+		report(cp)
+		return next, done, nil
+	})
+}
+
+// apply the transform to the items, the item is removed when the transform returns nil.
+// the items are converted by the options of the source table and the results are converted by the options of the destination table
+func transformItems(src, dst *marshalOptions, items []*map[string]*SDK.AttributeValue, transform func(map[string]interface{}) map[string]interface{}) []*map[string]*SDK.AttributeValue {
+	if transform == nil {
+		return items
+	}
+	results := make([]*map[string]*SDK.AttributeValue, 0, len(items))
+	for _, item := range items {
+		v := transform(src.unmarshal(item))
+		if v == nil {
+			continue
+		}
+		results = append(results, dst.marshal(v))
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+package dynamodb
+
+import (
+	"fmt"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func TestCopyTable(t *testing.T) {
+	src := getTestTable()
+	src.DeleteAll()
+	for i := 1; i <= 30; i++ {
+		putTestTable(src, 100, i)
+	}
+	dst := getTestStringRangeTable()
+	dst.DeleteAll()
+
+	var copied int
+	err := src.db.CopyTableWithOption("foo_table", "foo_stringtable", func(item map[string]interface{}) map[string]interface{} {
+		if item["time"] == 30 {
+			return nil
+		}
+		return map[string]interface{}{
+			"id":   item["id"],
+			"name": fmt.Sprint(item["time"]),
+		}
+	}, CopyTableOption{
+		Progress: func(cp CopyCheckpoint) {
+			if cp.Done {
+				copied += cp.Copied
+			}
+		},
+	})
+	if err != nil {
+		t.Errorf("error on CopyTable, %s", err.Error())
+	}
+	if copied != 29 {
+		t.Errorf("error on CopyTable, copied=%d", copied)
+	}
+}
+
+func TestCopyTableResume(t *testing.T) {
+	c := getTestTable().db
+
+	called := false
+	err := c.CopyTableWithOption("foo_table", "foo_stringtable", nil, CopyTableOption{
+		Segments: 2,
+		Resume: []CopyCheckpoint{
+			{Segment: 0, Done: true},
+			{Segment: 1, Done: true},
+		},
+		Progress: func(cp CopyCheckpoint) {
+			called = true
+		},
+	})
+	if err != nil {
+		t.Errorf("error on CopyTable, %s", err.Error())
+	}
+	if called {
+		t.Errorf("error on CopyTable, completed segment is copied again")
+	}
+}
+
+func TestTransformItems(t *testing.T) {
+	items := []*map[string]*SDK.AttributeValue{
+		Marshal(map[string]interface{}{"id": 1}),
+		Marshal(map[string]interface{}{"id": 2}),
+	}
+	if results := transformItems(defaultMarshalOptions, defaultMarshalOptions, items, nil); len(results) != 2 || results[0] != items[0] {
+		t.Errorf("error on transformItems, %v", results)
+	}
+
+	results := transformItems(defaultMarshalOptions, defaultMarshalOptions, items, func(item map[string]interface{}) map[string]interface{} {
+		if item["id"] == 1 {
+			return nil
+		}
+		item["copied"] = true
+		return item
+	})
+	if len(results) != 1 {
+		t.Fatalf("error on transformItems, %v", results)
+	}
+	if v := (*results[0])["copied"]; v == nil || v.BOOL == nil || !*v.BOOL {
+		t.Errorf("error on transformItems, %v", *results[0])
+	}
+}
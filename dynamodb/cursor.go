@@ -0,0 +1,138 @@
+// DynamoDB opaque pagination cursor
+
+package dynamodb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// ErrInvalidCursor is returned when the cursor is malformed or the signature does not match
+var ErrInvalidCursor = errors.New("[DynamoDB] invalid cursor")
+
+var cursorSigningKey []byte
+
+// SetCursorSigningKey sets the key to sign the cursor with HMAC-SHA256,
+// the cursor without the valid signature is rejected by DecodeCursor. nil key disables the signing
+func SetCursorSigningKey(key []byte) {
+	cursorSigningKey = key
+}
+
+// the scalar key attribute in the cursor
+type cursorValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// EncodeCursor encodes the key (e.g. LastEvaluatedKey) to the opaque URL-safe string,
+// the attributes must be string, number or binary
+func EncodeCursor(key map[string]interface{}) (string, error) {
+	return encodeCursorKey(Marshal(key))
+}
+
+// DecodeCursor decodes the cursor from EncodeCursor to the key
+func DecodeCursor(cursor string) (map[string]interface{}, error) {
+	key, err := decodeCursorKey(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return Unmarshal(key), nil
+}
+
+// encode the key of AttributeValue to the cursor, `<base64 payload>[.<base64 signature>]`
+func encodeCursorKey(key *map[string]*SDK.AttributeValue) (string, error) {
+	if key == nil || len(*key) == 0 {
+		return "", nil
+	}
+	values := make(map[string]cursorValue, len(*key))
+	for name, v := range *key {
+		if v == nil || (v.S == nil && v.N == nil && v.B == nil) {
+			return "", errors.New("[DynamoDB] cursor key must be string, number or binary, name=" + name)
+		}
+		values[name] = cursorValue{S: v.S, N: v.N, B: v.B}
+	}
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	cursor := base64.RawURLEncoding.EncodeToString(payload)
+	if cursorSigningKey != nil {
+		cursor += "." + base64.RawURLEncoding.EncodeToString(signCursor(payload))
+	}
+	return cursor, nil
+}
+
+// decode the cursor to the key of AttributeValue, and verify the signature when the signing key is set
+func decodeCursorKey(cursor string) (*map[string]*SDK.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	parts := strings.Split(cursor, ".")
+	if len(parts) > 2 {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursorSigningKey != nil {
+		if len(parts) != 2 {
+			return nil, ErrInvalidCursor
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil || !hmac.Equal(sig, signCursor(payload)) {
+			log.Warn("[DynamoDB] the signature of the cursor does not match", cursor)
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	var values map[string]cursorValue
+	if err := json.Unmarshal(payload, &values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	key := make(map[string]*SDK.AttributeValue, len(values))
+	for name, v := range values {
+		key[name] = &SDK.AttributeValue{S: v.S, N: v.N, B: v.B}
+	}
+	return &key, nil
+}
+
+func signCursor(payload []byte) []byte {
+	h := hmac.New(sha256.New, cursorSigningKey)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// QueryPage gets mapped-items of the single page of Query operation from the cursor (empty cursor for the first page),
+// and returns the cursor of the next page, which is empty on the last page
+func (t *DynamoTable) QueryPage(in *SDK.QueryInput, cursor string) ([]map[string]interface{}, string, error) {
+	startKey, err := decodeCursorKey(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	q := *in
+	t.applyQueryDefaults(&q)
+	q.ExclusiveStartKey = startKey
+	req, err := t.db.client.Query(&q)
+	if err != nil {
+		err = wrapError("Query", err)
+		t.notifyThrottle("Query", nil, err)
+		log.Error("[DynamoDB] Error in `Query` operation, table="+t.name, err)
+		return nil, "", err
+	}
+	next, err := encodeCursorKey(req.LastEvaluatedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return t.ConvertItemsToMapArray(req.Items), next, nil
+}
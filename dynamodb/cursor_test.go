@@ -0,0 +1,89 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func TestCursor(t *testing.T) {
+	key := map[string]interface{}{"id": 100, "name": "foo", "data": []byte{0x01, 0x02}}
+	cursor, err := EncodeCursor(key)
+	if err != nil {
+		t.Errorf("error on EncodeCursor, %s", err.Error())
+	}
+	if strings.ContainsAny(cursor, "+/=") {
+		t.Errorf("error on EncodeCursor, not URL-safe, %s", cursor)
+	}
+
+	decoded, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Errorf("error on DecodeCursor, %s", err.Error())
+	}
+	if decoded["id"] != 100 || decoded["name"] != "foo" || string(decoded["data"].([]byte)) != "\x01\x02" {
+		t.Errorf("error on DecodeCursor, %v", decoded)
+	}
+
+	if c, _ := EncodeCursor(nil); c != "" {
+		t.Errorf("error on EncodeCursor, %s", c)
+	}
+	if _, err := EncodeCursor(map[string]interface{}{"flag": true}); err == nil {
+		t.Errorf("error on EncodeCursor, non-scalar key is accepted")
+	}
+	for _, c := range []string{"!!!", "e30", "a.b.c"} {
+		if _, err := DecodeCursor(c); err != ErrInvalidCursor {
+			t.Errorf("error on DecodeCursor, %s is accepted, %v", c, err)
+		}
+	}
+}
+
+func TestCursorSigning(t *testing.T) {
+	SetCursorSigningKey([]byte("secret"))
+	defer SetCursorSigningKey(nil)
+
+	cursor, err := EncodeCursor(map[string]interface{}{"id": 100})
+	if err != nil {
+		t.Errorf("error on EncodeCursor, %s", err.Error())
+	}
+	decoded, err := DecodeCursor(cursor)
+	if err != nil || decoded["id"] != 100 {
+		t.Errorf("error on DecodeCursor, %v, %v", decoded, err)
+	}
+
+	// forged payload
+	forged, _ := encodeCursorKey(Marshal(map[string]interface{}{"id": 200}))
+	forged = strings.Split(forged, ".")[0] + "." + strings.Split(cursor, ".")[1]
+	if _, err := DecodeCursor(forged); err != ErrInvalidCursor {
+		t.Errorf("error on DecodeCursor, forged cursor is accepted, %v", err)
+	}
+	// no signature
+	if _, err := DecodeCursor(strings.Split(cursor, ".")[0]); err != ErrInvalidCursor {
+		t.Errorf("error on DecodeCursor, unsigned cursor is accepted, %v", err)
+	}
+}
+
+func TestQueryPage(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 3; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	in := &SDK.QueryInput{
+		TableName:              String(tbl.name),
+		KeyConditionExpression: String("id = :id"),
+		ExpressionAttributeValues: &map[string]*SDK.AttributeValue{
+			":id": createAttributeValue(100),
+		},
+		Limit: Long(2),
+	}
+	items, cursor, err := tbl.QueryPage(in, "")
+	if err != nil || len(items) != 2 || cursor == "" {
+		t.Errorf("error on QueryPage, %v, %s, %v", items, cursor, err)
+	}
+	items, cursor, err = tbl.QueryPage(in, cursor)
+	if err != nil || len(items) != 1 {
+		t.Errorf("error on QueryPage, %v, %s, %v", items, cursor, err)
+	}
+}
@@ -0,0 +1,70 @@
+// DynamoDB date-only attribute
+
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// the zero-padded layout of the date, the lexicographic order matches the order of the dates
+const dateLayout = "2006-01-02"
+
+// Date is the calendar date without the time and the timezone (e.g. the date of birth),
+// it's stored as the string attribute like `2024-01-02` and can be used for the range of QueryBetween.
+// the year must be between 1 and 9999 to keep the lexicographic order
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate creates Date from the calendar date of the time in its own location,
+// the time is not converted to UTC (e.g. 2024-01-02T01:00:00+09:00 is 2024-01-02)
+func NewDate(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses the date string like `2024-01-02` of the attribute
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, errors.New("[DynamoDB] invalid date, value=" + s)
+	}
+	return NewDate(t), nil
+}
+
+// String returns the zero-padded date string like `2024-01-02`
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+// IsZero returns true when the date is not set
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Time returns the time of the beginning of the date in the location
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// Before returns true when the date is before u
+func (d Date) Before(u Date) bool {
+	return d.String() < u.String()
+}
+
+// AddDays returns the date after n days (before for the negative n)
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Time(time.UTC).AddDate(0, 0, n))
+}
+
+// check if the year of the date can be stored with the lexicographic order
+func (d Date) validate() error {
+	if d.Year < 1 || d.Year > 9999 {
+		return fmt.Errorf("[DynamoDB] the year of the date must be between 1 and 9999, year=%d", d.Year)
+	}
+	return nil
+}
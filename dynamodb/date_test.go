@@ -0,0 +1,65 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+
+	// the date in the location of the time
+	d := NewDate(time.Date(2024, 1, 2, 1, 0, 0, 0, jst))
+	if d.String() != "2024-01-02" {
+		t.Errorf("error on NewDate, %s", d.String())
+	}
+	parsed, err := ParseDate("2024-01-02")
+	if err != nil {
+		t.Errorf("error on ParseDate, %s", err.Error())
+	}
+	if parsed != d {
+		t.Errorf("error on ParseDate, %v", parsed)
+	}
+	if !d.Time(jst).Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, jst)) {
+		t.Errorf("error on Time, %v", d.Time(jst))
+	}
+
+	if d.AddDays(30).String() != "2024-02-01" || d.AddDays(-2).String() != "2023-12-31" {
+		t.Errorf("error on AddDays, %s, %s", d.AddDays(30), d.AddDays(-2))
+	}
+	if !d.Before(d.AddDays(1)) || d.Before(d) {
+		t.Errorf("error on Before")
+	}
+	if d.IsZero() || !(Date{}).IsZero() {
+		t.Errorf("error on IsZero")
+	}
+
+	for _, s := range []string{"2024-1-2", "2024/01/02", "2024-02-30", ""} {
+		if _, err := ParseDate(s); err == nil {
+			t.Errorf("error on ParseDate, %s is accepted", s)
+		}
+	}
+}
+
+func TestDateAttributeValue(t *testing.T) {
+	av, err := defaultMarshalOptions.newAttributeValue(Date{Year: 987, Month: time.March, Day: 4})
+	if err != nil {
+		t.Errorf("error on newAttributeValue, %s", err.Error())
+	}
+	if av.S == nil || *av.S != "0987-03-04" {
+		t.Errorf("error on newAttributeValue, %v", av)
+	}
+	if _, err := defaultMarshalOptions.newAttributeValue(Date{Year: 10000, Month: time.January, Day: 1}); err == nil {
+		t.Errorf("error on newAttributeValue, the year over 9999 is accepted")
+	}
+
+	// the range of the dates is compared as the string
+	lo := Date{Year: 999, Month: time.December, Day: 31}
+	hi := Date{Year: 2024, Month: time.January, Day: 2}
+	if err := defaultMarshalOptions.validateBetween(lo, hi); err != nil {
+		t.Errorf("error on validateBetween, %s", err.Error())
+	}
+	if err := defaultMarshalOptions.validateBetween(hi, lo); err == nil {
+		t.Errorf("error on validateBetween, the reversed range is accepted")
+	}
+}
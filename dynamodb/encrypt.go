@@ -0,0 +1,128 @@
+// DynamoDB client-side attribute encryption hooks
+
+package dynamodb
+
+import (
+	"bytes"
+	"errors"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// the prefix of the encrypted binary(B) attribute, followed by the type of the plaintext
+const encryptMarker = "\x00enc\x00"
+
+// types of the plaintext of the encrypted attribute
+const (
+	encryptTypeString = 's'
+	encryptTypeBinary = 'b'
+)
+
+// EncryptHook encrypts the plaintext of the attribute on write
+type EncryptHook func(attr string, plaintext []byte) ([]byte, error)
+
+// DecryptHook decrypts the ciphertext of the attribute on read
+type DecryptHook func(attr string, ciphertext []byte) ([]byte, error)
+
+var (
+	// names of the attributes to encrypt
+	encryptAttributes map[string]bool
+
+	encryptHook EncryptHook
+	decryptHook DecryptHook
+)
+
+// SetEncryptHooks sets the hooks for the client-side encryption of the attributes,
+// the string or []byte value of the named attribute is encrypted by encrypt and stored as binary(B) with the marker,
+// and the value with the marker is decrypted by decrypt on Unmarshal. no names disables the encryption on write.
+// the encrypted attribute is not compressed, and cannot be used in the keys, expressions and conditions
+func SetEncryptHooks(encrypt EncryptHook, decrypt DecryptHook, names ...string) {
+	encryptHook = encrypt
+	decryptHook = decrypt
+	encryptAttributes = nil
+	if len(names) == 0 {
+		return
+	}
+	encryptAttributes = make(map[string]bool, len(names))
+	for _, name := range names {
+		encryptAttributes[name] = true
+	}
+}
+
+// create the encrypted or compressed AttributeValue for the attribute set by SetEncryptHooks or SetCompressAttributes,
+// returns false for the other attributes
+func encodeAttributeValue(name string, v Any) (*SDK.AttributeValue, bool, error) {
+	if av, ok, err := encryptAttributeValue(name, v); ok {
+		return av, true, err
+	}
+	av, ok := compressAttributeValue(name, v)
+	return av, ok, nil
+}
+
+// create the AttributeValue same as encodeAttributeValue,
+// the empty AttributeValue is returned on the encryption error to avoid storing the plaintext
+func mustEncodeAttributeValue(name string, v Any) (*SDK.AttributeValue, bool) {
+	av, ok, err := encodeAttributeValue(name, v)
+	if err != nil {
+		log.Error("[DynamoDB] error on encrypting the attribute="+name, err.Error())
+		return &SDK.AttributeValue{}, true
+	}
+	return av, ok
+}
+
+// create encrypted binary(B) AttributeValue when the attribute is set to encrypt,
+// returns false for the other attributes and nil value
+func encryptAttributeValue(name string, v Any) (*SDK.AttributeValue, bool, error) {
+	if !encryptAttributes[name] || encryptHook == nil || v == nil {
+		return nil, false, nil
+	}
+
+	var typ byte
+	var plaintext []byte
+	switch t := v.(type) {
+	case string:
+		typ = encryptTypeString
+		plaintext = []byte(t)
+	case []byte:
+		typ = encryptTypeBinary
+		plaintext = t
+	default:
+		return nil, true, errors.New("[DynamoDB] the encrypted attribute must be string or []byte, attribute=" + name)
+	}
+
+	ciphertext, err := encryptHook(name, plaintext)
+	if err != nil {
+		return nil, true, err
+	}
+	buf := bytes.NewBufferString(encryptMarker)
+	buf.WriteByte(typ)
+	buf.Write(ciphertext)
+	return &SDK.AttributeValue{
+		B: buf.Bytes(),
+	}, true, nil
+}
+
+// retrieve the string or []byte value from the encrypted binary(B) AttributeValue,
+// returns false when the value is not encrypted, and nil value on the decryption error
+func decryptAttributeValue(name string, val *SDK.AttributeValue) (Any, bool) {
+	if val == nil || !bytes.HasPrefix(val.B, []byte(encryptMarker)) || len(val.B) <= len(encryptMarker) {
+		return nil, false
+	}
+	if decryptHook == nil {
+		log.Error("[DynamoDB] DecryptHook is not set for the encrypted attribute", name)
+		return nil, true
+	}
+
+	data := val.B[len(encryptMarker):]
+	plaintext, err := decryptHook(name, data[1:])
+	if err != nil {
+		log.Error("[DynamoDB] error on decrypting the attribute="+name, err.Error())
+		return nil, true
+	}
+	if data[0] == encryptTypeString {
+		return string(plaintext), true
+	}
+	return plaintext, true
+}
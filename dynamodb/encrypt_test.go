@@ -0,0 +1,88 @@
+package dynamodb
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// reverse the bytes as the test cipher
+func testReverseCipher(attr string, data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	for i, b := range data {
+		result[len(data)-1-i] = b
+	}
+	return result, nil
+}
+
+func TestSetEncryptHooks(t *testing.T) {
+	SetEncryptHooks(testReverseCipher, testReverseCipher, "secret", "key")
+	defer SetEncryptHooks(nil, nil)
+
+	item := Marshal(map[string]interface{}{
+		"secret": "password",
+		"key":    []byte("abc"),
+		"title":  "foo",
+		"empty":  nil,
+	})
+	secret := (*item)["secret"]
+	if secret.B == nil || secret.S != nil || !bytes.HasSuffix(secret.B, []byte("drowssap")) {
+		t.Errorf("error on encrypt, %v", secret)
+	}
+	if title := (*item)["title"]; title.S == nil || *title.S != "foo" {
+		t.Errorf("error on encrypt, non target attribute must not be encrypted, %v", title)
+	}
+
+	data := Unmarshal(item)
+	if data["secret"] != "password" || data["title"] != "foo" {
+		t.Errorf("error on decrypt, %v", data)
+	}
+	if b, ok := data["key"].([]byte); !ok || string(b) != "abc" {
+		t.Errorf("error on decrypt, %v", data["key"])
+	}
+
+	// DynamoItem
+	dItem := NewItem()
+	dItem.AddAttribute("secret", "password")
+	if v := Unmarshal(&dItem.data); v["secret"] != "password" {
+		t.Errorf("error on encrypt, %v", dItem.data["secret"])
+	}
+
+	// CSV
+	if row, _ := csvRow(*item, []string{"secret"}); row[0] != "password" {
+		t.Errorf("error on decrypt, %v", row)
+	}
+
+	// unsupported type
+	if _, err := MarshalWithError(map[string]interface{}{"secret": 1}); err == nil {
+		t.Errorf("error on encrypt, unsupported type must return error")
+	}
+	if v := (*Marshal(map[string]interface{}{"secret": 1}))["secret"]; v.N != nil || v.B != nil {
+		t.Errorf("error on encrypt, plaintext must not be stored, %v", v)
+	}
+}
+
+func TestEncryptHookError(t *testing.T) {
+	errEncrypt := errors.New("encrypt error")
+	SetEncryptHooks(func(string, []byte) ([]byte, error) {
+		return nil, errEncrypt
+	}, func(string, []byte) ([]byte, error) {
+		return nil, errEncrypt
+	}, "secret")
+	defer SetEncryptHooks(nil, nil)
+
+	if _, err := MarshalWithError(map[string]interface{}{"secret": "password"}); err != errEncrypt {
+		t.Errorf("error on encrypt, %v", err)
+	}
+	if v := (*Marshal(map[string]interface{}{"secret": "password"}))["secret"]; v.S != nil || v.B != nil {
+		t.Errorf("error on encrypt, plaintext must not be stored, %v", v)
+	}
+
+	v := createAttributeValue([]byte(encryptMarker + "sdrowssap"))
+	if data, ok := decryptAttributeValue("secret", v); !ok || data != nil {
+		t.Errorf("error on decrypt, %v", data)
+	}
+	if _, ok := decryptAttributeValue("secret", createAttributeValue([]byte("foo"))); ok {
+		t.Errorf("error on decrypt, binary without marker must not be decrypted")
+	}
+}
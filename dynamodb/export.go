@@ -0,0 +1,287 @@
+// DynamoDB JSON lines, CSV and JSON array export
+
+package dynamodb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// ExportJSONL writes the items to w in the DynamoDB JSON lines format, like `{"Item":{"id":{"N":"1"}}}` for each line.
+// the items are marshaled and written in sorted order of the keys, the output is always same for the same items
+func ExportJSONL(w io.Writer, items []map[string]interface{}) error {
+	return defaultMarshalOptions.exportJSONL(w, items)
+}
+
+// write the items in the DynamoDB JSON lines format by the options
+func (o *marshalOptions) exportJSONL(w io.Writer, items []map[string]interface{}) error {
+	bw := bufio.NewWriter(w)
+	for _, item := range items {
+		data, err := o.marshalItem(item, true)
+		if err != nil {
+			return err
+		}
+		line, err := encodeItemJSON(*data)
+		if err != nil {
+			return err
+		}
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// encode the item to DynamoDB JSON in sorted order of the attribute names
+func encodeItemJSON(item map[string]*SDK.AttributeValue) ([]byte, error) {
+	names := make([]string, 0, len(item))
+	for name := range item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := bytes.NewBufferString(`{"Item":{`)
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(attributeValueJSON(item[name]))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// convert AttributeValue to the value of DynamoDB JSON, like {"S": "foo"}
+// (json.Marshal writes the map keys in sorted order)
+func attributeValueJSON(v *SDK.AttributeValue) map[string]interface{} {
+	switch {
+	case v == nil:
+		return map[string]interface{}{"NULL": true}
+	case v.S != nil:
+		return map[string]interface{}{"S": *v.S}
+	case v.N != nil:
+		return map[string]interface{}{"N": *v.N}
+	case v.BOOL != nil:
+		return map[string]interface{}{"BOOL": *v.BOOL}
+	case v.NULL != nil:
+		return map[string]interface{}{"NULL": *v.NULL}
+	case v.B != nil:
+		return map[string]interface{}{"B": v.B}
+	case v.SS != nil:
+		return map[string]interface{}{"SS": v.SS}
+	case v.NS != nil:
+		return map[string]interface{}{"NS": v.NS}
+	case v.BS != nil:
+		return map[string]interface{}{"BS": v.BS}
+	case v.L != nil:
+		list := make([]interface{}, len(v.L))
+		for i, elem := range v.L {
+			list[i] = attributeValueJSON(elem)
+		}
+		return map[string]interface{}{"L": list}
+	case v.M != nil:
+		m := make(map[string]interface{}, len(*v.M))
+		for name, elem := range *v.M {
+			m[name] = attributeValueJSON(elem)
+		}
+		return map[string]interface{}{"M": m}
+	}
+	return map[string]interface{}{}
+}
+
+// ExportCSV scans the table and writes the columns of the items to w as CSV with the header row,
+// the rows are written for every page of Scan. the missing attribute is blank,
+// the number is written as it is stored, and the set, list and map are written in DynamoDB JSON same as ExportJSONL
+func (t *DynamoTable) ExportCSV(w io.Writer, columns []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	attrs := t.options().newExpressionAttributes()
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = attrs.name(column)
+	}
+	in := &SDK.ScanInput{
+		TableName: String(t.name),
+	}
+	if len(columns) != 0 {
+		in.ProjectionExpression = String(strings.Join(names, ", "))
+		in.ExpressionAttributeNames = attrs.expressionNames()
+	}
+
+	return pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		res, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			t.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		for _, item := range res.Items {
+			row, err := csvRow(*item, columns)
+			if err != nil {
+				return nil, false, err
+			}
+			if err := cw.Write(row); err != nil {
+				return nil, false, err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return nil, false, err
+		}
+		next, done := nextPageKey(res.LastEvaluatedKey)
+		return next, done, nil
+	})
+}
+
+// create the CSV row of the columns from the item
+func csvRow(item map[string]*SDK.AttributeValue, columns []string) ([]string, error) {
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		v, err := csvValue(column, item[column])
+		if err != nil {
+			return nil, err
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+// convert AttributeValue to the CSV field, the number keeps the precision of the stored string
+func csvValue(name string, v *SDK.AttributeValue) (string, error) {
+	if d, ok := decryptAttributeValue(name, v); ok {
+		if b, ok := d.([]byte); ok {
+			return base64.StdEncoding.EncodeToString(b), nil
+		}
+		s, _ := d.(string)
+		return s, nil
+	}
+	if s, ok := decompressAttributeValue(v); ok {
+		return s, nil
+	}
+	switch {
+	case v == nil, v.NULL != nil:
+		return "", nil
+	case v.S != nil:
+		return *v.S, nil
+	case v.N != nil:
+		return *v.N, nil
+	case v.BOOL != nil:
+		return strconv.FormatBool(*v.BOOL), nil
+	case v.B != nil:
+		return base64.StdEncoding.EncodeToString(v.B), nil
+	}
+	b, err := json.Marshal(attributeValueJSON(v))
+	return string(b), err
+}
+
+// ScanToJSON scans the table and streams the mapped-items to w as JSON array, like `[{"id":1},{"id":2}]`,
+// the items are written and flushed for every page of Scan without buffering all of the items
+// (http.Flusher is also flushed, e.g. http.ResponseWriter).
+// the closing bracket is written even when the scan is stopped by the error or the context,
+// so that w always has the well-formed array of the items written until then
+func (t *DynamoTable) ScanToJSON(ctx context.Context, w io.Writer) error {
+	aw := newJSONArrayWriter(w)
+	in := &SDK.ScanInput{
+		TableName: String(t.name),
+	}
+	err := pager.PaginateContext(ctx, func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		res, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			t.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		if err := aw.writeItems(ctx, res.Items); err != nil {
+			return nil, false, err
+		}
+		next, done := nextPageKey(res.LastEvaluatedKey)
+		return next, done, nil
+	})
+	if closeErr := aw.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writer of the JSON array of the mapped-items
+type jsonArrayWriter struct {
+	w       *bufio.Writer
+	flusher http.Flusher
+	count   int
+}
+
+// create jsonArrayWriter and write the opening bracket
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	aw := &jsonArrayWriter{
+		w: bufio.NewWriter(w),
+	}
+	aw.flusher, _ = w.(http.Flusher)
+	aw.w.WriteByte('[')
+	return aw
+}
+
+// write the mapped-items separated by commas and flush them,
+// stops writing when the context is done
+func (aw *jsonArrayWriter) writeItems(ctx context.Context, items []*map[string]*SDK.AttributeValue) error {
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := json.Marshal(Unmarshal(item))
+		if err != nil {
+			return err
+		}
+		if aw.count > 0 {
+			aw.w.WriteByte(',')
+		}
+		aw.w.Write(data)
+		aw.count++
+	}
+	return aw.flush()
+}
+
+// write the closing bracket and flush
+func (aw *jsonArrayWriter) close() error {
+	aw.w.WriteByte(']')
+	return aw.flush()
+}
+
+func (aw *jsonArrayWriter) flush() error {
+	if err := aw.w.Flush(); err != nil {
+		return err
+	}
+	if aw.flusher != nil {
+		aw.flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package dynamodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func TestExportJSONL(t *testing.T) {
+	items := []map[string]interface{}{
+		{"id": 1, "name": "foo", "tags": []string{"a", "b"}, "flag": true},
+		{"id": 2, "meta": map[string]interface{}{"z": 1, "a": "x"}, "list": []interface{}{"s", 3}},
+	}
+	expected := `{"Item":{"flag":{"BOOL":true},"id":{"N":"1"},"name":{"S":"foo"},"tags":{"SS":["a","b"]}}}` + "\n" +
+		`{"Item":{"id":{"N":"2"},"list":{"L":[{"S":"s"},{"N":"3"}]},"meta":{"M":{"a":{"S":"x"},"z":{"N":"1"}}}}}` + "\n"
+
+	for i := 0; i < 20; i++ {
+		var buf bytes.Buffer
+		if err := ExportJSONL(&buf, items); err != nil {
+			t.Fatalf("error on ExportJSONL, %s", err.Error())
+		}
+		if buf.String() != expected {
+			t.Fatalf("error on ExportJSONL, run=%d, actual=%s", i, buf.String())
+		}
+	}
+}
+
+func TestExportJSONLError(t *testing.T) {
+	o := &marshalOptions{strictTypes: true}
+
+	var buf bytes.Buffer
+	err := o.exportJSONL(&buf, []map[string]interface{}{
+		{"b": struct{}{}, "a": struct{}{}},
+	})
+	if err == nil || !strings.HasSuffix(err.Error(), "attribute=a") {
+		t.Errorf("error on ExportJSONL, the first key is not reported, %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("error on ExportJSONL, actual=%s", buf.String())
+	}
+}
+
+func TestItemKeys(t *testing.T) {
+	item := map[string]interface{}{"c": 1, "a": 2, "b": 3}
+	for i := 0; i < 20; i++ {
+		keys := itemKeys(item, true)
+		if len(keys) != 3 || keys[0] != "a" || keys[1] != "b" || keys[2] != "c" {
+			t.Fatalf("error on itemKeys, actual=%v", keys)
+		}
+	}
+	if keys := itemKeys(item, false); len(keys) != 3 {
+		t.Errorf("error on itemKeys, actual=%v", keys)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	tbl := getTestStringRangeTable()
+	tbl.DeleteAll()
+	item := NewItem()
+	item.AddAttribute("id", 100)
+	item.AddAttribute("name", "foo")
+	item.AddAttribute("tags", []string{"a", "b"})
+	tbl.AddItem(item)
+	tbl.Put()
+
+	var buf bytes.Buffer
+	err := tbl.ExportCSV(&buf, []string{"id", "name", "tags", "missing"})
+	if err != nil {
+		t.Errorf("error on ExportCSV, %s", err.Error())
+	}
+	expected := "id,name,tags,missing\n" + `100,foo,"{""SS"":[""a"",""b""]}",` + "\n"
+	if buf.String() != expected {
+		t.Errorf("error on ExportCSV, actual=%s", buf.String())
+	}
+}
+
+func TestCSVRow(t *testing.T) {
+	item := *Marshal(map[string]interface{}{
+		"s":    "foo",
+		"n":    "ignored",
+		"bool": true,
+		"null": nil,
+		"b":    []byte("foo"),
+		"m":    map[string]interface{}{"a": 1},
+	})
+	item["n"] = &SDK.AttributeValue{N: String("12345678901234567890.123")}
+
+	row, err := csvRow(item, []string{"s", "n", "bool", "null", "b", "m", "missing"})
+	if err != nil {
+		t.Fatalf("error on csvRow, %s", err.Error())
+	}
+	expected := []string{"foo", "12345678901234567890.123", "true", "", "Zm9v", `{"M":{"a":{"N":"1"}}}`, ""}
+	if !reflect.DeepEqual(expected, row) {
+		t.Errorf("error on csvRow, actual=%#v", row)
+	}
+}
+
+func TestScanToJSON(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 3; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	var buf bytes.Buffer
+	if err := tbl.ScanToJSON(context.Background(), &buf); err != nil {
+		t.Errorf("error on ScanToJSON, %s", err.Error())
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil || len(items) != 3 {
+		t.Errorf("error on ScanToJSON, actual=%s", buf.String())
+	}
+
+	// canceled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf.Reset()
+	if err := tbl.ScanToJSON(ctx, &buf); err != context.Canceled {
+		t.Errorf("error on ScanToJSON, %v", err)
+	}
+	if buf.String() != "[]" {
+		t.Errorf("error on ScanToJSON, actual=%s", buf.String())
+	}
+}
+
+func TestJSONArrayWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	aw := newJSONArrayWriter(w)
+	page := []*map[string]*SDK.AttributeValue{
+		Marshal(map[string]interface{}{"id": 1}),
+		Marshal(map[string]interface{}{"id": 2}),
+	}
+	if err := aw.writeItems(context.Background(), page); err != nil {
+		t.Fatalf("error on writeItems, %s", err.Error())
+	}
+	if w.Body.String() != `[{"id":1},{"id":2}` || !w.Flushed {
+		t.Errorf("error on writeItems, actual=%s", w.Body.String())
+	}
+
+	// stopped by the context, the array is still closed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := aw.writeItems(ctx, page); err != context.Canceled {
+		t.Errorf("error on writeItems, %v", err)
+	}
+	aw.close()
+	if w.Body.String() != `[{"id":1},{"id":2}]` {
+		t.Errorf("error on close, actual=%s", w.Body.String())
+	}
+}
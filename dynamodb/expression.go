@@ -0,0 +1,129 @@
+// DynamoDB Expression placeholders
+
+package dynamodb
+
+import (
+	"strconv"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	namePlaceholderPrefix  = "#n"
+	valuePlaceholderPrefix = ":v"
+
+	// the prefix of the literal attribute name
+	literalNameMarker = "\x00lit\x00"
+)
+
+// Literal marks the attribute name to be used as it is in the builders, instead of the document path.
+// use this for the name which contains dot or brackets, like `Literal("config.timeout")`.
+// (Marshal and Unmarshal always treat the attribute names as they are, and the marked name is not marked again)
+func Literal(name string) string {
+	if strings.HasPrefix(name, literalNameMarker) {
+		return name
+	}
+	return literalNameMarker + name
+}
+
+// expressionAttributes holds placeholders for ExpressionAttributeNames and ExpressionAttributeValues
+type expressionAttributes struct {
+	names  map[string]*string
+	values map[string]*SDK.AttributeValue
+	index  map[string]string
+
+	// options to convert the values
+	opts *marshalOptions
+}
+
+// Create new expressionAttributes struct
+func newExpressionAttributes() *expressionAttributes {
+	return defaultMarshalOptions.newExpressionAttributes()
+}
+
+// Create new expressionAttributes struct which converts the values by the options
+func (o *marshalOptions) newExpressionAttributes() *expressionAttributes {
+	return &expressionAttributes{
+		names:  make(map[string]*string),
+		values: make(map[string]*SDK.AttributeValue),
+		index:  make(map[string]string),
+		opts:   o,
+	}
+}
+
+// get the placeholder for the attribute name, same name uses same placeholder
+func (e *expressionAttributes) name(attr string) string {
+	attr = strings.TrimPrefix(attr, literalNameMarker)
+	if key, ok := e.index[attr]; ok {
+		return key
+	}
+	key := namePlaceholderPrefix + strconv.Itoa(len(e.names))
+	e.names[key] = String(attr)
+	e.index[attr] = key
+	return key
+}
+
+// get the placeholder for the document path of the attribute,
+// the nested attribute is separated by dot and the list element is specified by index (e.g. `items[0].sku` -> `#n0[0].#n1`),
+// the name marked by Literal is used as the single attribute name
+func (e *expressionAttributes) path(attr string) string {
+	if strings.HasPrefix(attr, literalNameMarker) {
+		return e.name(attr)
+	}
+	elems := strings.Split(attr, ".")
+	for i, elem := range elems {
+		elems[i] = e.pathElement(elem)
+	}
+	return strings.Join(elems, ".")
+}
+
+// get the placeholder for the element of document path with the list indexes,
+// the element of invalid format is used as the name as it is
+func (e *expressionAttributes) pathElement(elem string) string {
+	pos := strings.IndexByte(elem, '[')
+	if pos <= 0 {
+		return e.name(elem)
+	}
+
+	indexes := elem[pos:]
+	for rest := indexes; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 2 {
+			return e.name(elem)
+		}
+		if _, err := strconv.Atoi(rest[1:end]); err != nil {
+			return e.name(elem)
+		}
+		rest = rest[end+1:]
+	}
+	return e.name(elem[:pos]) + indexes
+}
+
+// get new placeholder for the value
+func (e *expressionAttributes) value(v Any) string {
+	return e.attributeValue(e.opts.createAttributeValue(v))
+}
+
+// get new placeholder for the AttributeValue
+func (e *expressionAttributes) attributeValue(v *SDK.AttributeValue) string {
+	key := valuePlaceholderPrefix + strconv.Itoa(len(e.values))
+	e.values[key] = v
+	return key
+}
+
+// get ExpressionAttributeNames, returns nil when no name is used
+func (e *expressionAttributes) expressionNames() *map[string]*string {
+	if len(e.names) == 0 {
+		return nil
+	}
+	return &e.names
+}
+
+// get ExpressionAttributeValues, returns nil when no value is used
+func (e *expressionAttributes) expressionValues() *map[string]*SDK.AttributeValue {
+	if len(e.values) == 0 {
+		return nil
+	}
+	return &e.values
+}
@@ -0,0 +1,84 @@
+package dynamodb
+
+import (
+	"testing"
+)
+
+func TestExpressionAttributesName(t *testing.T) {
+	e := newExpressionAttributes()
+	n1 := e.name("foo")
+	n2 := e.name("bar")
+	n3 := e.name("foo")
+	if n1 != "#n0" || n2 != "#n1" || n3 != n1 {
+		t.Errorf("error on name, %s, %s, %s", n1, n2, n3)
+	}
+	if len(e.names) != 2 || *e.names["#n1"] != "bar" {
+		t.Errorf("error on name, %v", e.names)
+	}
+}
+
+func TestExpressionAttributesValue(t *testing.T) {
+	e := newExpressionAttributes()
+	if e.expressionNames() != nil || e.expressionValues() != nil {
+		t.Errorf("error on newExpressionAttributes, %v", e)
+	}
+
+	v1 := e.value(1)
+	v2 := e.value(1)
+	if v1 != ":v0" || v2 != ":v1" {
+		t.Errorf("error on value, %s, %s", v1, v2)
+	}
+	values := e.expressionValues()
+	if values == nil || len(*values) != 2 || *(*values)[":v1"].N != "1" {
+		t.Errorf("error on value, %v", values)
+	}
+}
+
+func TestExpressionAttributesPath(t *testing.T) {
+	e := newExpressionAttributes()
+	tests := []struct {
+		attr     string
+		expected string
+	}{
+		{"foo", "#n0"},
+		{"foo.bar", "#n0.#n1"},
+		{"list[1]", "#n2[1]"},
+		{"list[1][2].foo", "#n2[1][2].#n0"},
+		{"list[x]", "#n3"},
+		{"[0]", "#n4"},
+	}
+	for _, tt := range tests {
+		p := e.path(tt.attr)
+		if p != tt.expected {
+			t.Errorf("error on path, attr=%s, actual=%s, expected=%s", tt.attr, p, tt.expected)
+		}
+	}
+	if *e.names["#n3"] != "list[x]" || *e.names["#n4"] != "[0]" {
+		t.Errorf("error on path, %v", e.names)
+	}
+}
+
+func TestExpressionAttributesLiteral(t *testing.T) {
+	e := newExpressionAttributes()
+	if p := e.path(Literal("config.timeout")); p != "#n0" {
+		t.Errorf("error on path with Literal, actual=%s", p)
+	}
+	if *e.names["#n0"] != "config.timeout" {
+		t.Errorf("error on path with Literal, %v", e.names)
+	}
+	if p := e.path("config.timeout"); p != "#n1.#n2" {
+		t.Errorf("error on path, actual=%s", p)
+	}
+	if n := e.name(Literal("config.timeout")); n != "#n0" {
+		t.Errorf("error on name with Literal, actual=%s", n)
+	}
+	if p := e.path(Literal(Literal("config.timeout"))); p != "#n0" {
+		t.Errorf("error on path with double Literal, actual=%s", p)
+	}
+
+	f := NewFilterBuilder()
+	f.AddEQ(Literal("items[0]"), 1)
+	if exp := f.Expression(); exp != "#n0 = :v0" || *f.attrs.names["#n0"] != "items[0]" {
+		t.Errorf("error on FilterBuilder with Literal, %s, %v", exp, f.attrs.names)
+	}
+}
@@ -0,0 +1,215 @@
+// DynamoDB ConditionExpression/FilterExpression builder
+
+package dynamodb
+
+import (
+	"errors"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// operators for expression
+var expressionOperators = map[string]string{
+	ComparisonOperatorEQ: "=",
+	ComparisonOperatorNE: "<>",
+	ComparisonOperatorGT: ">",
+	ComparisonOperatorLT: "<",
+	ComparisonOperatorGE: ">=",
+	ComparisonOperatorLE: "<=",
+}
+
+// FilterBuilder is a builder for ConditionExpression and FilterExpression,
+// all of the added conditions are combined with AND, use Or/And/Not to build the boolean tree.
+// the name of the condition accepts document path for the nested attribute (e.g. `profile.email`, `items[0].sku`)
+type FilterBuilder struct {
+	attrs      *expressionAttributes
+	conditions []string
+	err        error
+}
+
+// Create new FilterBuilder struct
+func NewFilterBuilder() *FilterBuilder {
+	return &FilterBuilder{
+		attrs: newExpressionAttributes(),
+	}
+}
+
+// NewFilterBuilder creates new FilterBuilder struct which converts the values by the options of the client (e.g. SetNumberCodec)
+func (t *DynamoTable) NewFilterBuilder() *FilterBuilder {
+	return &FilterBuilder{
+		attrs: t.options().newExpressionAttributes(),
+	}
+}
+
+// Create new FilterBuilder which shares the placeholders with the other builder,
+// use this to combine the expressions in single request (e.g. KeyConditionExpression and FilterExpression)
+func NewSharedFilterBuilder(f *FilterBuilder) *FilterBuilder {
+	return &FilterBuilder{
+		attrs: f.attrs,
+	}
+}
+
+// Add a EQUAL condition, `#n = :v`
+func (f *FilterBuilder) AddEQ(name string, value Any) {
+	f.addComparison(name, value, ComparisonOperatorEQ)
+}
+
+// Add a NOT EQUAL condition, `#n <> :v`
+func (f *FilterBuilder) AddNE(name string, value Any) {
+	f.addComparison(name, value, ComparisonOperatorNE)
+}
+
+// Add a GREATER THAN condition, `#n > :v`
+func (f *FilterBuilder) AddGT(name string, value Any) {
+	f.addComparison(name, value, ComparisonOperatorGT)
+}
+
+// Add a LESS THAN condition, `#n < :v`
+func (f *FilterBuilder) AddLT(name string, value Any) {
+	f.addComparison(name, value, ComparisonOperatorLT)
+}
+
+// Add a GREATER THAN or EQUAL condition, `#n >= :v`
+func (f *FilterBuilder) AddGE(name string, value Any) {
+	f.addComparison(name, value, ComparisonOperatorGE)
+}
+
+// Add a LESS THAN or EQUAL condition, `#n <= :v`
+func (f *FilterBuilder) AddLE(name string, value Any) {
+	f.addComparison(name, value, ComparisonOperatorLE)
+}
+
+// Add a BETWEEN condition, `#n BETWEEN :from AND :to`
+func (f *FilterBuilder) AddBetween(name string, from, to Any) {
+	cond := f.attrs.path(name) + " BETWEEN " + f.attrs.value(from) + " AND " + f.attrs.value(to)
+	f.add(cond)
+}
+
+// Add a begins_with condition, `begins_with(#n, :v)`
+func (f *FilterBuilder) AddBeginsWith(name, prefix string) {
+	f.add("begins_with(" + f.attrs.path(name) + ", " + f.attrs.value(prefix) + ")")
+}
+
+// Add a EXIST condition, `attribute_exists(#n)`
+func (f *FilterBuilder) AddExists(name string) {
+	f.add("attribute_exists(" + f.attrs.path(name) + ")")
+}
+
+// Add a NOT EXIST condition, `attribute_not_exists(#n)`
+func (f *FilterBuilder) AddNotExists(name string) {
+	f.add("attribute_not_exists(" + f.attrs.path(name) + ")")
+}
+
+// Add a size condition with the comparison operator (EQ, NE, GT, LT, GE or LE), `size(#n) < :v`,
+// the size is the length of the string or binary, or the number of the elements of the list, map or set
+func (f *FilterBuilder) AddSize(name, operator string, value Any) {
+	op, ok := expressionOperators[operator]
+	if !ok {
+		f.err = errors.New("[DynamoDB] unsupported operator for size, operator=" + operator)
+		return
+	}
+	f.add("size(" + f.attrs.path(name) + ") " + op + " " + f.attrs.value(value))
+}
+
+// Or adds the condition which combines the groups with OR, `((A1 AND A2) OR (B))`,
+// the groups must be created by NewSharedFilterBuilder with this builder and the empty group is ignored
+func (f *FilterBuilder) Or(groups ...*FilterBuilder) {
+	f.addGroups(groups, " OR ")
+}
+
+// And adds the condition which combines the groups with AND, `((A1 OR A2) AND (B))`,
+// the groups must be created by NewSharedFilterBuilder with this builder and the empty group is ignored
+func (f *FilterBuilder) And(groups ...*FilterBuilder) {
+	f.addGroups(groups, " AND ")
+}
+
+// Not adds the negated condition of the group, `NOT (A1 AND A2)`,
+// the group must be created by NewSharedFilterBuilder with this builder
+func (f *FilterBuilder) Not(group *FilterBuilder) {
+	if !f.isSharedGroup(group) || len(group.conditions) == 0 {
+		return
+	}
+	f.add("NOT (" + group.Expression() + ")")
+}
+
+// add the condition of the groups joined with the operator
+func (f *FilterBuilder) addGroups(groups []*FilterBuilder, operator string) {
+	var exprs []string
+	for _, group := range groups {
+		if !f.isSharedGroup(group) {
+			return
+		}
+		if len(group.conditions) != 0 {
+			exprs = append(exprs, "("+group.Expression()+")")
+		}
+	}
+	switch len(exprs) {
+	case 0:
+		return
+	case 1:
+		f.add(exprs[0])
+	default:
+		f.add("(" + strings.Join(exprs, operator) + ")")
+	}
+}
+
+// check if the group shares the placeholders with the builder, the error is set when it does not
+func (f *FilterBuilder) isSharedGroup(group *FilterBuilder) bool {
+	switch {
+	case group == nil:
+		return false
+	case group.err != nil:
+		f.err = group.err
+		return false
+	case group.attrs != f.attrs:
+		f.err = errors.New("[DynamoDB] the group must be created by NewSharedFilterBuilder with the builder")
+		return false
+	}
+	return true
+}
+
+// Error returns the error occurred while building the expression
+func (f *FilterBuilder) Error() error {
+	return f.err
+}
+
+// add comparison condition
+func (f *FilterBuilder) addComparison(name string, value Any, operator string) {
+	f.add(f.attrs.path(name) + " " + expressionOperators[operator] + " " + f.attrs.value(value))
+}
+
+// add a condition
+func (f *FilterBuilder) add(cond string) {
+	f.conditions = append(f.conditions, cond)
+}
+
+// Expression returns the expression joined with AND
+func (f *FilterBuilder) Expression() string {
+	return strings.Join(f.conditions, " AND ")
+}
+
+// Create new QueryInput from the key condition and the filter,
+// the filter must share the placeholders with the key condition
+func newExpressionQueryInput(table string, keyCond, filter *FilterBuilder) (*SDK.QueryInput, error) {
+	if keyCond.Error() != nil {
+		return nil, keyCond.Error()
+	}
+	in := &SDK.QueryInput{
+		TableName:                 String(table),
+		KeyConditionExpression:    String(keyCond.Expression()),
+		ExpressionAttributeNames:  keyCond.attrs.expressionNames(),
+		ExpressionAttributeValues: keyCond.attrs.expressionValues(),
+	}
+	if filter == nil || len(filter.conditions) == 0 {
+		return in, nil
+	}
+	if filter.Error() != nil {
+		return nil, filter.Error()
+	}
+	if filter.attrs != keyCond.attrs {
+		return nil, errors.New("[DynamoDB] the filter must be created by NewSharedFilterBuilder with the key condition")
+	}
+	in.FilterExpression = String(filter.Expression())
+	return in, nil
+}
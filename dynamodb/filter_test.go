@@ -0,0 +1,185 @@
+package dynamodb
+
+import (
+	"testing"
+)
+
+func TestFilterBuilderComparison(t *testing.T) {
+	f := NewFilterBuilder()
+	f.AddEQ("a", 1)
+	f.AddNE("b", 2)
+	f.AddGT("c", 3)
+	f.AddLT("d", 4)
+	f.AddGE("e", 5)
+	f.AddLE("a", 6)
+	exp := f.Expression()
+	if exp != "#n0 = :v0 AND #n1 <> :v1 AND #n2 > :v2 AND #n3 < :v3 AND #n4 >= :v4 AND #n0 <= :v5" {
+		t.Errorf("error on FilterBuilder, %s", exp)
+	}
+	if len(f.attrs.names) != 5 || len(f.attrs.values) != 6 {
+		t.Errorf("error on FilterBuilder, %v", f.attrs)
+	}
+	if *f.attrs.values[":v5"].N != "6" {
+		t.Errorf("error on FilterBuilder, %v", f.attrs.values[":v5"])
+	}
+}
+
+func TestFilterBuilderFunctions(t *testing.T) {
+	f := NewFilterBuilder()
+	f.AddExists("owner")
+	f.AddNotExists("deleted")
+	f.AddBeginsWith("name", "foo")
+	f.AddBetween("time", 1, 10)
+	exp := f.Expression()
+	if exp != "attribute_exists(#n0) AND attribute_not_exists(#n1) AND begins_with(#n2, :v0) AND #n3 BETWEEN :v1 AND :v2" {
+		t.Errorf("error on FilterBuilder, %s", exp)
+	}
+	if *f.attrs.values[":v0"].S != "foo" || *f.attrs.values[":v2"].N != "10" {
+		t.Errorf("error on FilterBuilder, %v", f.attrs.values)
+	}
+}
+
+func TestFilterBuilderSize(t *testing.T) {
+	f := NewFilterBuilder()
+	f.AddSize("items", ComparisonOperatorLT, 100)
+	f.AddSize("profile.name", ComparisonOperatorGE, 3)
+	exp := f.Expression()
+	if exp != "size(#n0) < :v0 AND size(#n1.#n2) >= :v1" {
+		t.Errorf("error on AddSize, %s", exp)
+	}
+	if *f.attrs.values[":v0"].N != "100" || f.Error() != nil {
+		t.Errorf("error on AddSize, %v, %v", f.attrs.values, f.Error())
+	}
+
+	f = NewFilterBuilder()
+	f.AddSize("items", "BEGINS_WITH", 1)
+	if f.Error() == nil || f.Expression() != "" {
+		t.Errorf("error on AddSize, unsupported operator is accepted, %s", f.Expression())
+	}
+}
+
+func TestNewSharedFilterBuilder(t *testing.T) {
+	keyCond := NewFilterBuilder()
+	keyCond.AddEQ("id", 100)
+	keyCond.AddGE("time", 1)
+
+	filter := NewSharedFilterBuilder(keyCond)
+	filter.AddEQ("lsi_key", "lsi_value")
+	filter.AddLT("time", 10)
+
+	if exp := keyCond.Expression(); exp != "#n0 = :v0 AND #n1 >= :v1" {
+		t.Errorf("error on NewSharedFilterBuilder, %s", exp)
+	}
+	if exp := filter.Expression(); exp != "#n2 = :v2 AND #n1 < :v3" {
+		t.Errorf("error on NewSharedFilterBuilder, %s", exp)
+	}
+
+	in, err := newExpressionQueryInput("foo_table", keyCond, filter)
+	if err != nil {
+		t.Errorf("error on newExpressionQueryInput, %s", err.Error())
+	}
+	if *in.KeyConditionExpression != keyCond.Expression() || *in.FilterExpression != filter.Expression() {
+		t.Errorf("error on newExpressionQueryInput, %v", in)
+	}
+	if len(*in.ExpressionAttributeNames) != 3 || len(*in.ExpressionAttributeValues) != 4 {
+		t.Errorf("error on newExpressionQueryInput, names=%v, values=%v", *in.ExpressionAttributeNames, *in.ExpressionAttributeValues)
+	}
+	if *(*in.ExpressionAttributeValues)[":v3"].N != "10" {
+		t.Errorf("error on newExpressionQueryInput, %v", *in.ExpressionAttributeValues)
+	}
+
+	// not shared filter
+	other := NewFilterBuilder()
+	other.AddEQ("lsi_key", "lsi_value")
+	_, err = newExpressionQueryInput("foo_table", keyCond, other)
+	if err == nil {
+		t.Errorf("error on newExpressionQueryInput, error must be returned for not shared filter")
+	}
+
+	// without filter
+	in, err = newExpressionQueryInput("foo_table", keyCond, nil)
+	if err != nil || in.FilterExpression != nil {
+		t.Errorf("error on newExpressionQueryInput, %v", in)
+	}
+}
+
+func TestFilterBuilderDocumentPath(t *testing.T) {
+	f := NewFilterBuilder()
+	f.AddExists("profile.email")
+	f.AddEQ("items[0].sku", "foo")
+	f.AddGT("profile.age", 20)
+	exp := f.Expression()
+	if exp != "attribute_exists(#n0.#n1) AND #n2[0].#n3 = :v0 AND #n0.#n4 > :v1" {
+		t.Errorf("error on FilterBuilder, %s", exp)
+	}
+	names := f.attrs.names
+	if len(names) != 5 || *names["#n0"] != "profile" || *names["#n1"] != "email" ||
+		*names["#n2"] != "items" || *names["#n3"] != "sku" || *names["#n4"] != "age" {
+		t.Errorf("error on FilterBuilder, %v", names)
+	}
+}
+
+func TestFilterBuilderBooleanTree(t *testing.T) {
+	f := NewFilterBuilder()
+	g1 := NewSharedFilterBuilder(f)
+	g1.AddEQ("a", "x")
+	g1.AddGT("b", 1)
+	g2 := NewSharedFilterBuilder(f)
+	g2.AddNotExists("c")
+	f.Or(g1, g2)
+	exp := f.Expression()
+	if exp != "((#n0 = :v0 AND #n1 > :v1) OR (attribute_not_exists(#n2)))" {
+		t.Errorf("error on FilterBuilder.Or, %s", exp)
+	}
+
+	// nested groups and NOT
+	f = NewFilterBuilder()
+	f.AddEQ("status", "active")
+	inner1 := NewSharedFilterBuilder(f)
+	inner1.AddEQ("a", 1)
+	inner2 := NewSharedFilterBuilder(f)
+	inner2.AddEQ("a", 2)
+	or := NewSharedFilterBuilder(f)
+	or.Or(inner1, inner2)
+	not := NewSharedFilterBuilder(f)
+	not.AddExists("deleted")
+	f.And(or, NewSharedFilterBuilder(f))
+	f.Not(not)
+	exp = f.Expression()
+	if exp != "#n0 = :v0 AND (((#n1 = :v1) OR (#n1 = :v2))) AND NOT (attribute_exists(#n2))" {
+		t.Errorf("error on FilterBuilder nested groups, %s", exp)
+	}
+	if f.Error() != nil {
+		t.Errorf("error on FilterBuilder nested groups, %s", f.Error().Error())
+	}
+
+	// placeholders are unique in the tree
+	if len(f.attrs.names) != 3 || len(f.attrs.values) != 3 {
+		t.Errorf("error on FilterBuilder nested groups, %v", f.attrs)
+	}
+	if *f.attrs.values[":v1"].N != "1" || *f.attrs.values[":v2"].N != "2" {
+		t.Errorf("error on FilterBuilder nested groups, %v", f.attrs.values)
+	}
+}
+
+func TestFilterBuilderGroupError(t *testing.T) {
+	f := NewFilterBuilder()
+	other := NewFilterBuilder()
+	other.AddEQ("a", 1)
+	f.Or(other)
+	if f.Error() == nil || f.Expression() != "" {
+		t.Errorf("error on FilterBuilder.Or, group without shared placeholders is accepted, %s", f.Expression())
+	}
+	if _, err := newExpressionQueryInput("table", f, nil); err == nil {
+		t.Errorf("error on newExpressionQueryInput, builder error is ignored")
+	}
+
+	// propagated from the nested group
+	parent := NewFilterBuilder()
+	group := NewSharedFilterBuilder(parent)
+	group.Not(other)
+	parent.And(group)
+	if parent.Error() == nil {
+		t.Errorf("error on FilterBuilder.And, error of the group is not propagated")
+	}
+}
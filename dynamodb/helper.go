@@ -3,9 +3,14 @@
 package dynamodb
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
 )
@@ -17,49 +22,274 @@ const (
 
 type Any interface{}
 
-// Create new AttributeValue from the type of value
+// marshalOptions is the options of the conversion between the values and AttributeValue,
+// AmazonDynamoDB has its own options and the package-level functions (e.g. Marshal and Unmarshal) use the default options
+type marshalOptions struct {
+	// store json.RawMessage as string(S) instead of map(M)
+	rawJSONAsString bool
+
+	// skip nil value instead of storing NULL
+	omitNilValue bool
+
+	// return error for unsupported type instead of using reflect
+	strictTypes bool
+
+	// marshal the attributes in sorted order of the keys
+	sortedKeys bool
+
+	// top-level attributes decoded as time.Duration
+	durationAttributes map[string]struct{}
+
+	// decode number(N) and number set(NS) as the stored string
+	numberAsString bool
+
+	// codec for number(N) attribute, nil means the default conversion
+	numberCodec NumberCodec
+}
+
+// the options of the package-level functions, it's never changed
+var defaultMarshalOptions = &marshalOptions{}
+
+// SetRawJSONAsString sets if json.RawMessage is stored as it is, into string(S) attribute on the tables of the client
+func (d *AmazonDynamoDB) SetRawJSONAsString(b bool) {
+	d.marshalOptions.rawJSONAsString = b
+}
+
+// SetOmitNilValue sets if the attribute of nil value is omitted, instead of storing NULL attribute on the tables of the client
+func (d *AmazonDynamoDB) SetOmitNilValue(b bool) {
+	d.marshalOptions.omitNilValue = b
+}
+
+// SetStrictTypes sets if the marshaling on the tables of the client is limited to the explicit types without reflect,
+// the operation returns error for the other types on strict mode
+func (d *AmazonDynamoDB) SetStrictTypes(b bool) {
+	d.marshalOptions.strictTypes = b
+}
+
+// SetSortedKeys sets if the attributes are marshaled in sorted order of the keys on the tables of the client,
+// the result item is same but the error for the multiple invalid attributes becomes deterministic
+func (d *AmazonDynamoDB) SetSortedKeys(b bool) {
+	d.marshalOptions.sortedKeys = b
+}
+
+// SetNumberAsString sets if the number(N) is decoded as the stored string without parsing on the tables of the client,
+// and the number set(NS) is decoded as []string. use this to keep the precision by the own decimal library
+func (d *AmazonDynamoDB) SetNumberAsString(b bool) {
+	d.marshalOptions.numberAsString = b
+}
+
+// SetDurationAttributes sets the top-level attributes which are decoded as time.Duration on the tables of the client.
+// time.Duration is always stored as number(N) of nanoseconds, and decoded as int without this setting
+func (d *AmazonDynamoDB) SetDurationAttributes(names ...string) {
+	m := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		m[name] = struct{}{}
+	}
+	d.marshalOptions.durationAttributes = m
+}
+
+// Create new AttributeValue from the type of value by the default options,
+// unsupported type is stored as empty AttributeValue
 func createAttributeValue(v Any) *SDK.AttributeValue {
+	return defaultMarshalOptions.createAttributeValue(v)
+}
+
+// Create new AttributeValue from the type of value,
+// unsupported type is stored as empty AttributeValue
+func (o *marshalOptions) createAttributeValue(v Any) *SDK.AttributeValue {
+	av, err := o.newAttributeValue(v)
+	if err != nil {
+		return &SDK.AttributeValue{}
+	}
+	return av
+}
+
+// Create new AttributeValue from the type of value,
+// returns error for unsupported type on strict mode
+func (o *marshalOptions) newAttributeValue(v Any) (*SDK.AttributeValue, error) {
 	switch t := v.(type) {
+	case nil:
+		return &SDK.AttributeValue{
+			NULL: Boolean(true),
+		}, nil
+	case json.RawMessage:
+		return o.createJSONAttributeValue(t), nil
+	case time.Duration:
+		return &SDK.AttributeValue{
+			N: String(strconv.FormatInt(int64(t), 10)),
+		}, nil
 	case string:
 		return &SDK.AttributeValue{
 			S: String(t),
+		}, nil
+	case Date:
+		if err := t.validate(); err != nil {
+			return nil, err
 		}
+		return &SDK.AttributeValue{
+			S: String(t.String()),
+		}, nil
 	case int, int32, int64, uint, uint32, uint64, float32, float64:
+		if n, ok := o.encodeNumber(t); ok {
+			return &SDK.AttributeValue{
+				N: String(n),
+			}, nil
+		}
 		return &SDK.AttributeValue{
 			N: String(fmt.Sprint(t)),
-		}
+		}, nil
 	case []byte:
 		return &SDK.AttributeValue{
 			B: t,
-		}
+		}, nil
 	case bool:
 		return &SDK.AttributeValue{
 			BOOL: Boolean(t),
-		}
+		}, nil
 	case []string:
 		return &SDK.AttributeValue{
 			SS: createPointerSliceString(t),
-		}
+		}, nil
 	case [][]byte:
 		return &SDK.AttributeValue{
 			BS: t,
-		}
-	case []int, []int32, []int64, []uint, []uint32, []uint64, []float32, []float64:
+		}, nil
+	case []int64:
+		return &SDK.AttributeValue{
+			NS: createPointerSliceInt64(t),
+		}, nil
+	case []int, []int32, []uint, []uint32, []uint64, []float32, []float64:
 		return &SDK.AttributeValue{
 			NS: MarshalStringSlice(t),
+		}, nil
+	case []interface{}:
+		list := make([]*SDK.AttributeValue, 0, len(t))
+		for _, elem := range t {
+			av, err := o.newAttributeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, av)
 		}
+		return &SDK.AttributeValue{
+			L: list,
+		}, nil
+	case map[string]interface{}:
+		m, err := o.marshalItem(t, o.sortedKeys)
+		if err != nil {
+			return nil, err
+		}
+		return &SDK.AttributeValue{
+			M: m,
+		}, nil
+	}
+
+	// custom number type (e.g. decimal) by the codec
+	if n, ok := o.encodeNumber(v); ok {
+		return &SDK.AttributeValue{
+			N: String(n),
+		}, nil
+	}
+
+	if o.strictTypes {
+		return nil, fmt.Errorf("[DynamoDB] unsupported type on strict mode, type=%T", v)
 	}
 
 	k := reflect.ValueOf(v)
 	switch {
-	case k.Kind() == reflect.Map:
+	case k.Kind() == reflect.Slice, k.Kind() == reflect.Array:
+		return o.createSliceAttributeValue(k), nil
+	}
+	return &SDK.AttributeValue{}, nil
+}
+
+// Create new AttributeValue for the flat value (string, int, int64, float64 and bool) without fmt,
+// returns false for the other types or when the number codec is set
+func (o *marshalOptions) createFlatAttributeValue(v Any) (*SDK.AttributeValue, bool) {
+	switch t := v.(type) {
+	case string:
+		return &SDK.AttributeValue{
+			S: String(t),
+		}, true
+	case bool:
 		return &SDK.AttributeValue{
-			M: Marshal(v.(map[string]interface{})),
+			BOOL: Boolean(t),
+		}, true
+	}
+
+	if o.numberCodec != nil {
+		return nil, false
+	}
+	var n string
+	switch t := v.(type) {
+	case int:
+		n = strconv.Itoa(t)
+	case int64:
+		n = strconv.FormatInt(t, 10)
+	case float64:
+		n = strconv.FormatFloat(t, 'g', -1, 64)
+	default:
+		return nil, false
+	}
+	return &SDK.AttributeValue{
+		N: String(n),
+	}, true
+}
+
+// Create new AttributeValue from the slice or array by the type of element
+func (o *marshalOptions) createSliceAttributeValue(k reflect.Value) *SDK.AttributeValue {
+	elem := k.Type().Elem()
+	switch elem.Kind() {
+	case reflect.String:
+		return &SDK.AttributeValue{
+			SS: MarshalStringSlice(k.Interface()),
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return &SDK.AttributeValue{
+			NS: MarshalStringSlice(k.Interface()),
+		}
+	case reflect.Uint8:
+		b := make([]byte, k.Len())
+		reflect.Copy(reflect.ValueOf(b), k)
+		return &SDK.AttributeValue{
+			B: b,
+		}
+	case reflect.Interface:
+		list := make([]interface{}, k.Len())
+		for i := range list {
+			list[i] = k.Index(i).Interface()
 		}
+		return o.newListAttributeValue(list)
 	}
 	return &SDK.AttributeValue{}
 }
 
+// Create new AttributeValue from JSON data,
+// JSON object is stored as map(M) and invalid JSON is stored as string(S)
+func (o *marshalOptions) createJSONAttributeValue(data json.RawMessage) *SDK.AttributeValue {
+	if !o.rawJSONAsString {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			return o.createAttributeValue(v)
+		}
+	}
+	return &SDK.AttributeValue{
+		S: String(string(data)),
+	}
+}
+
+// Create new List AttributeValue from values
+func (o *marshalOptions) newListAttributeValue(values []interface{}) *SDK.AttributeValue {
+	list := make([]*SDK.AttributeValue, 0, len(values))
+	for _, v := range values {
+		list = append(list, o.createAttributeValue(v))
+	}
+	return &SDK.AttributeValue{
+		L: list,
+	}
+}
+
 func createPointerSliceString(values []string) []*string {
 	var p []*string
 	for _, v := range values {
@@ -69,10 +299,32 @@ func createPointerSliceString(values []string) []*string {
 	return p
 }
 
-// Retrieve value from DynamoDB type
+// create []*string from int64 slice with full 64-bit precision
+func createPointerSliceInt64(values []int64) []*string {
+	p := make([]*string, len(values))
+	for i, v := range values {
+		str := strconv.FormatInt(v, 10)
+		p[i] = &str
+	}
+	return p
+}
+
+// Retrieve value from DynamoDB type by the default options
 func getItemValue(val *SDK.AttributeValue) Any {
+	return defaultMarshalOptions.getItemValue(val)
+}
+
+// Retrieve value from DynamoDB type
+func (o *marshalOptions) getItemValue(val *SDK.AttributeValue) Any {
 	switch {
+	case val.NULL != nil && *val.NULL:
+		return nil
+	case val.N != nil && o.numberAsString:
+		return *val.N
 	case val.N != nil:
+		if data, ok := o.decodeNumber(*val.N); ok {
+			return data
+		}
 		data, _ := strconv.Atoi(*val.N)
 		return data
 	case val.S != nil:
@@ -82,14 +334,15 @@ func getItemValue(val *SDK.AttributeValue) Any {
 	case len(val.B) > 0:
 		return val.B
 	case val.M != nil && len(*val.M) > 0:
-		return Unmarshal(val.M)
-	case len(val.NS) > 0:
-		var data []*int
-		for _, vString := range val.NS {
-			vInt, _ := strconv.Atoi(*vString)
-			data = append(data, &vInt)
+		return o.unmarshal(val.M)
+	case len(val.NS) > 0 && o.numberAsString:
+		data := make([]string, len(val.NS))
+		for i, vString := range val.NS {
+			data[i] = *vString
 		}
 		return data
+	case len(val.NS) > 0:
+		return getNumberSetValue(val.NS)
 	case len(val.SS) > 0:
 		var data []*string
 		for _, vString := range val.SS {
@@ -105,40 +358,193 @@ func getItemValue(val *SDK.AttributeValue) Any {
 	case len(val.L) > 0:
 		var data []interface{}
 		for _, vAny := range val.L {
-			data = append(data, getItemValue(vAny))
+			data = append(data, o.getItemValue(vAny))
 		}
 		return data
 	}
 	return nil
 }
 
+// Retrieve number set as []int64, or []float64 when the set contains non-integer number
+func getNumberSetValue(values []*string) Any {
+	data := make([]int64, 0, len(values))
+	for _, vString := range values {
+		vInt, err := strconv.ParseInt(*vString, 10, 64)
+		if err != nil {
+			return getFloatSetValue(values)
+		}
+		data = append(data, vInt)
+	}
+	return data
+}
+
+// Retrieve number set as []float64
+func getFloatSetValue(values []*string) []float64 {
+	data := make([]float64, 0, len(values))
+	for _, vString := range values {
+		vFloat, _ := strconv.ParseFloat(*vString, 64)
+		data = append(data, vFloat)
+	}
+	return data
+}
+
+// Retrieve value of the top-level attribute, the encrypted attribute is decrypted, the compressed attribute is decompressed
+// and the number of the duration attribute is decoded as time.Duration
+func (o *marshalOptions) getAttributeValue(key string, val *SDK.AttributeValue) Any {
+	if v, ok := decryptAttributeValue(key, val); ok {
+		return v
+	}
+	if s, ok := decompressAttributeValue(val); ok {
+		return s
+	}
+	if d, ok := o.getDurationValue(key, val); ok {
+		return d
+	}
+	return o.getItemValue(val)
+}
+
+// Retrieve nanoseconds of the number as time.Duration when the attribute is set by SetDurationAttributes
+func (o *marshalOptions) getDurationValue(key string, val *SDK.AttributeValue) (time.Duration, bool) {
+	if _, ok := o.durationAttributes[key]; !ok || val.N == nil {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(*val.N, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(n), true
+}
+
 // Convert DynamoDB Item to map data
 func Unmarshal(item *map[string]*SDK.AttributeValue) map[string]interface{} {
+	return defaultMarshalOptions.unmarshal(item)
+}
+
+// Unmarshal converts DynamoDB Item to map data by the options of the client
+func (d *AmazonDynamoDB) Unmarshal(item *map[string]*SDK.AttributeValue) map[string]interface{} {
+	return d.options().unmarshal(item)
+}
+
+// Convert DynamoDB Item to map data by the options
+func (o *marshalOptions) unmarshal(item *map[string]*SDK.AttributeValue) map[string]interface{} {
 	data := make(map[string]interface{})
 	if item == nil {
 		return data
 	}
 	for key, val := range *item {
-		data[key] = getItemValue(val)
+		data[key] = o.getAttributeValue(key, val)
+	}
+	return data
+}
+
+// Convert DynamoDB Item to map data with the specified attributes only,
+// the attribute which does not exist in the item is not contained in the result
+func UnmarshalSubset(item *map[string]*SDK.AttributeValue, keys ...string) map[string]interface{} {
+	return defaultMarshalOptions.unmarshalSubset(item, keys...)
+}
+
+// Convert DynamoDB Item to map data with the specified attributes only by the options
+func (o *marshalOptions) unmarshalSubset(item *map[string]*SDK.AttributeValue, keys ...string) map[string]interface{} {
+	data := make(map[string]interface{}, len(keys))
+	if item == nil {
+		return data
+	}
+	for _, key := range keys {
+		if val, ok := (*item)[key]; ok {
+			data[key] = o.getAttributeValue(key, val)
+		}
 	}
 	return data
 }
 
 // Convert map to DynamoDb Item data
 func Marshal(item map[string]interface{}) *map[string]*SDK.AttributeValue {
-	data := make(map[string]*SDK.AttributeValue)
+	return defaultMarshalOptions.marshal(item)
+}
+
+// Marshal converts map to DynamoDb Item data by the options of the client
+func (d *AmazonDynamoDB) Marshal(item map[string]interface{}) *map[string]*SDK.AttributeValue {
+	return d.options().marshal(item)
+}
+
+// Convert map to DynamoDb Item data by the options
+func (o *marshalOptions) marshal(item map[string]interface{}) *map[string]*SDK.AttributeValue {
+	data := make(map[string]*SDK.AttributeValue, len(item))
 	for key, val := range item {
-		data[key] = createAttributeValue(val)
+		if val == nil && o.omitNilValue {
+			continue
+		}
+		if av, ok := mustEncodeAttributeValue(key, val); ok {
+			data[key] = av
+			continue
+		}
+		if av, ok := o.createFlatAttributeValue(val); ok {
+			data[key] = av
+			continue
+		}
+		data[key] = o.createAttributeValue(val)
 	}
 	return &data
 }
 
+// Convert map to DynamoDb Item data, returns error for unsupported type on strict mode
+func MarshalWithError(item map[string]interface{}) (*map[string]*SDK.AttributeValue, error) {
+	return defaultMarshalOptions.marshalItem(item, false)
+}
+
+// MarshalWithError converts map to DynamoDb Item data by the options of the client,
+// returns error for unsupported type on strict mode (see SetStrictTypes)
+func (d *AmazonDynamoDB) MarshalWithError(item map[string]interface{}) (*map[string]*SDK.AttributeValue, error) {
+	o := d.options()
+	return o.marshalItem(item, o.sortedKeys)
+}
+
+// Convert map to DynamoDb Item data in sorted order of the keys when sorted is true
+func (o *marshalOptions) marshalItem(item map[string]interface{}, sorted bool) (*map[string]*SDK.AttributeValue, error) {
+	data := make(map[string]*SDK.AttributeValue, len(item))
+	for _, key := range itemKeys(item, sorted) {
+		val := item[key]
+		if val == nil && o.omitNilValue {
+			continue
+		}
+		if av, ok, err := encodeAttributeValue(key, val); ok {
+			if err != nil {
+				return nil, err
+			}
+			data[key] = av
+			continue
+		}
+		if av, ok := o.createFlatAttributeValue(val); ok {
+			data[key] = av
+			continue
+		}
+		av, err := o.newAttributeValue(val)
+		if err != nil {
+			return nil, errors.New(err.Error() + ", attribute=" + key)
+		}
+		data[key] = av
+	}
+	return &data, nil
+}
+
+// get the keys of the item, sorted when sorted is true
+func itemKeys(item map[string]interface{}, sorted bool) []string {
+	keys := make([]string, 0, len(item))
+	for key := range item {
+		keys = append(keys, key)
+	}
+	if sorted {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
 // Convert string slice to DynamoDb Item data
 func MarshalStringSlice(item Any) []*string {
 	var data []*string
 
 	switch reflect.TypeOf(item).Kind() {
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		val := reflect.ValueOf(item)
 		max := val.Len()
 		for i := 0; i < max; i++ {
@@ -201,19 +607,42 @@ func NewAttributeDefinitions(attr ...*SDK.AttributeDefinition) []*SDK.AttributeD
 	return attr
 }
 
-// Create new definition of table
+// aliases of the attribute type
+var attributeTypeAliases = map[string]string{
+	"string":  "S",
+	"number":  "N",
+	"binary":  "B",
+	"bool":    "BOOL",
+	"boolean": "BOOL",
+}
+
+// Create new definition of table,
+// the type is case-insensitive and accepts aliases (string, number, binary, bool, boolean).
+// returns empty definition for unknown type, use NewAttributeDefinitionE to get the error
 func NewAttributeDefinition(attrName, attrType string) *SDK.AttributeDefinition {
-	newAttr := &SDK.AttributeDefinition{}
-	var typ *string
-	switch attrType {
+	attr, err := NewAttributeDefinitionE(attrName, attrType)
+	if err != nil {
+		return &SDK.AttributeDefinition{}
+	}
+	return attr
+}
+
+// NewAttributeDefinitionE creates new definition of table same as NewAttributeDefinition,
+// and returns error for unknown type
+func NewAttributeDefinitionE(attrName, attrType string) (*SDK.AttributeDefinition, error) {
+	typ, ok := attributeTypeAliases[strings.ToLower(attrType)]
+	if !ok {
+		typ = strings.ToUpper(attrType)
+	}
+	switch typ {
 	case "S", "N", "B", "BOOL", "L", "M", "SS", "NS", "BS":
-		typ = String(attrType)
 	default:
-		return newAttr
+		return nil, errors.New("[DynamoDB] unknown attribute type, name=" + attrName + ", type=" + attrType)
 	}
-	newAttr.AttributeName = String(attrName)
-	newAttr.AttributeType = typ
-	return newAttr
+	return &SDK.AttributeDefinition{
+		AttributeName: String(attrName),
+		AttributeType: String(typ),
+	}, nil
 }
 
 // NewStringAttribute returns a table AttributeDefinition for string
@@ -2,13 +2,32 @@ package dynamodb
 
 import (
 	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"fmt"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
 )
 
 var _ = fmt.Sprint("")
 
+func TestNewListAttributeValue(t *testing.T) {
+	l := defaultMarshalOptions.newListAttributeValue([]interface{}{"foo", 99, true})
+	if len(l.L) != 3 || *l.L[0].S != "foo" || *l.L[1].N != "99" || *l.L[2].BOOL != true {
+		t.Errorf("error on newListAttributeValue, actual=%+v", l)
+	}
+
+	empty := defaultMarshalOptions.newListAttributeValue(nil)
+	if empty.L == nil || len(empty.L) != 0 {
+		t.Errorf("error on newListAttributeValue, actual=%+v", empty)
+	}
+}
+
 func TestCreateAttributeValue(t *testing.T) {
 	s := createAttributeValue("foo")
 	if *s.S != "foo" {
@@ -63,6 +82,184 @@ func TestCreateAttributeValue(t *testing.T) {
 	}
 }
 
+func TestCreateAttributeValueRawJSON(t *testing.T) {
+	raw := json.RawMessage(`{"id": 1, "name": "foo", "tags": ["a", "b"], "nested": {"ok": true}}`)
+	m := createAttributeValue(raw)
+	if m.M == nil {
+		t.Errorf("error on createAttributeValue, actual=%+v", m)
+	}
+	item := Unmarshal(m.M)
+	if item["id"] != 1 || item["name"] != "foo" {
+		t.Errorf("error on createAttributeValue, actual=%+v", item)
+	}
+	if tags, ok := item["tags"].([]interface{}); !ok || len(tags) != 2 || tags[0] != "a" {
+		t.Errorf("error on createAttributeValue, actual=%+v", item["tags"])
+	}
+	if nested, ok := item["nested"].(map[string]interface{}); !ok || nested["ok"] != true {
+		t.Errorf("error on createAttributeValue, actual=%+v", item["nested"])
+	}
+
+	invalid := createAttributeValue(json.RawMessage(`{"id": `))
+	if invalid.S == nil || *invalid.S != `{"id": ` {
+		t.Errorf("error on createAttributeValue, actual=%+v", invalid)
+	}
+
+	o := &marshalOptions{rawJSONAsString: true}
+	s := o.createAttributeValue(raw)
+	if s.M != nil || s.S == nil || *s.S != string(raw) {
+		t.Errorf("error on createAttributeValue, actual=%+v", s)
+	}
+}
+
+func TestCreateAttributeValueArray(t *testing.T) {
+	ss := createAttributeValue([3]string{"foo1", "foo2", "foo3"})
+	if len(ss.SS) != 3 || *ss.SS[0] != "foo1" || *ss.SS[2] != "foo3" {
+		t.Errorf("error on createAttributeValue, actual=%+v", ss)
+	}
+
+	ns := createAttributeValue([2]float64{35.5, 139.75})
+	if len(ns.NS) != 2 || *ns.NS[0] != "35.5" || *ns.NS[1] != "139.75" {
+		t.Errorf("error on createAttributeValue, actual=%+v", ns)
+	}
+
+	us := createAttributeValue([]uint{1, 2})
+	if len(us.NS) != 2 || *us.NS[0] != "1" || *us.NS[1] != "2" {
+		t.Errorf("error on createAttributeValue, actual=%+v", us)
+	}
+
+	b := createAttributeValue([3]byte{102, 111, 111})
+	if !bytes.Equal(b.B, []byte("foo")) {
+		t.Errorf("error on createAttributeValue, actual=%+v", b)
+	}
+
+	l := createAttributeValue([2]interface{}{"foo", 1})
+	if len(l.L) != 2 || *l.L[0].S != "foo" || *l.L[1].N != "1" {
+		t.Errorf("error on createAttributeValue, actual=%+v", l)
+	}
+
+	type myString string
+	ms := createAttributeValue([]myString{"foo"})
+	if len(ms.SS) != 1 || *ms.SS[0] != "foo" {
+		t.Errorf("error on createAttributeValue, actual=%+v", ms)
+	}
+}
+
+func TestMarshalStringSlice(t *testing.T) {
+	data := MarshalStringSlice([3]int{1, 2, 3})
+	if len(data) != 3 || *data[0] != "1" || *data[2] != "3" {
+		t.Errorf("error on MarshalStringSlice, actual=%v", data)
+	}
+}
+
+func TestMarshalWithError(t *testing.T) {
+	item := map[string]interface{}{
+		"id":     1,
+		"name":   "foo",
+		"nested": map[string]interface{}{"list": []interface{}{"a", 1}},
+		"array":  [2]int{1, 2},
+	}
+	data, err := MarshalWithError(item)
+	if err != nil {
+		t.Errorf("error on MarshalWithError, %s", err.Error())
+	}
+	if len(*data) != 4 || len((*data)["array"].NS) != 2 {
+		t.Errorf("error on MarshalWithError, actual=%+v", *data)
+	}
+
+	o := &marshalOptions{strictTypes: true}
+	_, err = o.marshalItem(item, false)
+	if err == nil {
+		t.Errorf("error on MarshalWithError, error must be returned for array on strict mode")
+	}
+
+	delete(item, "array")
+	data, err = o.marshalItem(item, false)
+	if err != nil {
+		t.Errorf("error on MarshalWithError, %s", err.Error())
+	}
+	nested := (*(*data)["nested"].M)["list"]
+	if len(nested.L) != 2 || *nested.L[0].S != "a" || *nested.L[1].N != "1" {
+		t.Errorf("error on MarshalWithError, actual=%+v", nested)
+	}
+
+	// nested unsupported type
+	item["nested"] = map[string]interface{}{"st": TestStruct{}}
+	_, err = o.marshalItem(item, false)
+	if err == nil {
+		t.Errorf("error on MarshalWithError, error must be returned for struct on strict mode")
+	}
+}
+
+func TestCreateAttributeValueNil(t *testing.T) {
+	null := createAttributeValue(nil)
+	if null.NULL == nil || *null.NULL != true {
+		t.Errorf("error on createAttributeValue, actual=%+v", null)
+	}
+	if getItemValue(null) != nil {
+		t.Errorf("error on getItemValue, actual=%+v", null)
+	}
+}
+
+func TestMarshalNilValue(t *testing.T) {
+	data := map[string]interface{}{"id": 1, "x": nil}
+	item := Marshal(data)
+	if x, ok := (*item)["x"]; !ok || x.NULL == nil || *x.NULL != true {
+		t.Errorf("error on Marshal, actual=%+v", *item)
+	}
+	if v, ok := Unmarshal(item)["x"]; !ok || v != nil {
+		t.Errorf("error on Unmarshal, actual=%+v", Unmarshal(item))
+	}
+
+	o := &marshalOptions{omitNilValue: true}
+	item = o.marshal(data)
+	if _, ok := (*item)["x"]; ok || len(*item) != 1 {
+		t.Errorf("error on Marshal, actual=%+v", *item)
+	}
+}
+
+func TestClientMarshalOptions(t *testing.T) {
+	d1 := &AmazonDynamoDB{marshalOptions: &marshalOptions{}}
+	d2 := &AmazonDynamoDB{marshalOptions: &marshalOptions{}}
+	tbl1 := &DynamoTable{db: d1}
+	tbl2 := &DynamoTable{db: d2}
+	d1.SetOmitNilValue(true)
+	d1.SetDurationAttributes("timeout")
+
+	data := map[string]interface{}{"id": 1, "x": nil}
+	if item := tbl1.options().marshal(data); len(*item) != 1 {
+		t.Errorf("error on marshal with the client options, actual=%+v", *item)
+	}
+	if item := tbl2.options().marshal(data); len(*item) != 2 {
+		t.Errorf("error on marshal with the other client options, actual=%+v", *item)
+	}
+	if item := Marshal(data); len(*item) != 2 {
+		t.Errorf("error on Marshal with the default options, actual=%+v", *item)
+	}
+
+	item := tbl1.NewItem()
+	item.AddAttribute("x", nil)
+	if len(item.data) != 0 {
+		t.Errorf("error on AddAttribute with the client options, actual=%+v", item.data)
+	}
+	b := tbl1.NewUpdateBuilder()
+	if b.attrs.opts != d1.marshalOptions {
+		t.Errorf("error on NewUpdateBuilder, the client options are not used")
+	}
+
+	av := Marshal(map[string]interface{}{"timeout": time.Second})
+	if v := d1.Unmarshal(av)["timeout"]; v != time.Second {
+		t.Errorf("error on Unmarshal with the client options, actual=%#v", v)
+	}
+	if v := d2.Unmarshal(av)["timeout"]; v != 1000000000 {
+		t.Errorf("error on Unmarshal with the other client options, actual=%#v", v)
+	}
+
+	// the table without the client uses the default options
+	if o := getTestCacheTable().options(); o != defaultMarshalOptions {
+		t.Errorf("error on options, actual=%+v", o)
+	}
+}
+
 func TestGetItemValue(t *testing.T) {
 	s := createAttributeValue("foo")
 	if getItemValue(s) != "foo" {
@@ -85,11 +282,17 @@ func TestGetItemValue(t *testing.T) {
 	}
 
 	ns := createAttributeValue([]int{1, 2, 3})
-	nsValue := getItemValue(ns).([]*int)
-	if len(nsValue) != 3 || *nsValue[0] != 1 || *nsValue[1] != 2 || *nsValue[2] != 3 {
+	nsValue := getItemValue(ns).([]int64)
+	if len(nsValue) != 3 || nsValue[0] != 1 || nsValue[1] != 2 || nsValue[2] != 3 {
 		t.Errorf("error on getItemValue, actual=%+v", nsValue)
 	}
 
+	nsFloat := createAttributeValue([]float64{1.5, 2})
+	nsFloatValue := getItemValue(nsFloat).([]float64)
+	if len(nsFloatValue) != 2 || nsFloatValue[0] != 1.5 || nsFloatValue[1] != 2 {
+		t.Errorf("error on getItemValue, actual=%+v", nsFloatValue)
+	}
+
 	ss := createAttributeValue([]string{"foo1", "foo2", "foo3"})
 	ssValue := getItemValue(ss).([]*string)
 	if len(ssValue) != 3 || *ssValue[0] != "foo1" || *ssValue[1] != "foo2" || *ssValue[2] != "foo3" {
@@ -117,10 +320,89 @@ func TestGetItemValue(t *testing.T) {
 }
 
 // TestUnmarshal TODO: write test
+func TestNumberSetInt64RoundTrip(t *testing.T) {
+	values := []int64{math.MaxInt64, math.MinInt64, math.MaxInt32 + 1, -1}
+	item := Unmarshal(Marshal(map[string]interface{}{"ns": values}))
+	result, ok := item["ns"].([]int64)
+	if !ok || !reflect.DeepEqual(values, result) {
+		t.Errorf("error on number set round trip, actual=%#v", item["ns"])
+	}
+
+	ns := createAttributeValue(values).NS
+	if len(ns) != 4 || *ns[0] != "9223372036854775807" || *ns[1] != "-9223372036854775808" || *ns[2] != "2147483648" {
+		t.Errorf("error on number set encoding, actual=%v", ns)
+	}
+}
+
 func TestUnmarshal(t *testing.T) {
 	t.Skip("TODO: write test")
 }
 
+func TestNumberAsString(t *testing.T) {
+	item := Marshal(map[string]interface{}{
+		"n":  "0",
+		"ns": []int64{1, 2},
+	})
+	(*item)["n"] = &SDK.AttributeValue{N: String("12345678901234567890.123456789")}
+
+	o := &marshalOptions{numberAsString: true}
+	data := o.unmarshal(item)
+	if data["n"] != "12345678901234567890.123456789" {
+		t.Errorf("error on NumberAsString, actual=%#v", data["n"])
+	}
+	if !reflect.DeepEqual([]string{"1", "2"}, data["ns"]) {
+		t.Errorf("error on NumberAsString, actual=%#v", data["ns"])
+	}
+
+	if data := Unmarshal(item); !reflect.DeepEqual([]int64{1, 2}, data["ns"]) {
+		t.Errorf("error on NumberAsString disabled, actual=%#v", data["ns"])
+	}
+}
+
+func TestDurationRoundTrip(t *testing.T) {
+	item := Marshal(map[string]interface{}{
+		"timeout": 90 * time.Second,
+		"count":   5,
+	})
+	if v := (*item)["timeout"]; v.N == nil || *v.N != "90000000000" {
+		t.Errorf("error on marshal time.Duration, actual=%+v", v)
+	}
+
+	data := Unmarshal(item)
+	if data["timeout"] != 90000000000 {
+		t.Errorf("error on unmarshal time.Duration as default, actual=%#v", data["timeout"])
+	}
+
+	o := &marshalOptions{durationAttributes: map[string]struct{}{"timeout": {}}}
+	data = o.unmarshal(item)
+	if data["timeout"] != 90*time.Second || data["count"] != 5 {
+		t.Errorf("error on unmarshal time.Duration, actual=%#v", data)
+	}
+	data = o.unmarshalSubset(item, "timeout")
+	if data["timeout"] != 90*time.Second {
+		t.Errorf("error on unmarshal time.Duration, actual=%#v", data)
+	}
+}
+
+func TestUnmarshalSubset(t *testing.T) {
+	item := Marshal(map[string]interface{}{
+		"id":   1,
+		"name": "foo",
+		"data": map[string]interface{}{"large": "value"},
+	})
+	data := UnmarshalSubset(item, "id", "name", "non_exist")
+	if len(data) != 2 || data["id"] != 1 || data["name"] != "foo" {
+		t.Errorf("error on UnmarshalSubset, actual=%v", data)
+	}
+	if _, ok := data["non_exist"]; ok {
+		t.Errorf("error on UnmarshalSubset, actual=%v", data)
+	}
+
+	if data := UnmarshalSubset(nil, "id"); len(data) != 0 {
+		t.Errorf("error on UnmarshalSubset, actual=%v", data)
+	}
+}
+
 func TestNewProvisionedThroughput(t *testing.T) {
 	tp := NewProvisionedThroughput(80, 600)
 	if *tp.ReadCapacityUnits != 80 || *tp.WriteCapacityUnits != 600 {
@@ -175,6 +457,32 @@ func TestNewAttributeDefinition(t *testing.T) {
 	}
 }
 
+func TestNewAttributeDefinitionE(t *testing.T) {
+	tests := map[string]string{
+		"S":       "S",
+		"s":       "S",
+		"ns":      "NS",
+		"BOOL":    "BOOL",
+		"m":       "M",
+		"string":  "S",
+		"Number":  "N",
+		"BINARY":  "B",
+		"bool":    "BOOL",
+		"Boolean": "BOOL",
+	}
+	for typ, expected := range tests {
+		attr, err := NewAttributeDefinitionE("foo", typ)
+		if err != nil || *attr.AttributeName != "foo" || *attr.AttributeType != expected {
+			t.Errorf("error on NewAttributeDefinitionE, type=%s, actual=%v", typ, attr)
+		}
+	}
+
+	attr, err := NewAttributeDefinitionE("foo", "bar")
+	if err == nil || attr != nil || !strings.Contains(err.Error(), "[DynamoDB] unknown attribute type") {
+		t.Errorf("error on NewAttributeDefinitionE, error must be returned, actual=%v, err=%v", attr, err)
+	}
+}
+
 func TestNewStringAttribute(t *testing.T) {
 	attr := NewStringAttribute("foo")
 	if *attr.AttributeName != "foo" || *attr.AttributeType != "S" {
@@ -204,3 +512,94 @@ func TestNewBoolAttribute(t *testing.T) {
 }
 
 type TestStruct struct{}
+
+func benchmarkMarshalItem() map[string]interface{} {
+	return map[string]interface{}{
+		"id":      100,
+		"name":    "foo",
+		"time":    int64(1435000000),
+		"score":   99.5,
+		"enabled": true,
+		"tags":    []string{"a", "b"},
+		"nested":  map[string]interface{}{"key": "value"},
+		"list":    []interface{}{"a", 1},
+		"data":    []byte("bar"),
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	item := benchmarkMarshalItem()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MarshalWithError(item)
+	}
+}
+
+func BenchmarkMarshalStrict(b *testing.B) {
+	item := benchmarkMarshalItem()
+	o := &marshalOptions{strictTypes: true}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.marshalItem(item, false)
+	}
+}
+
+func TestCreateFlatAttributeValue(t *testing.T) {
+	values := []interface{}{"foo", true, 100, int64(-1435000000000), 99.5, 1e21, 0.000001}
+	for _, v := range values {
+		av, ok := defaultMarshalOptions.createFlatAttributeValue(v)
+		if !ok {
+			t.Errorf("error on createFlatAttributeValue, value=%v", v)
+			continue
+		}
+		expected, _ := defaultMarshalOptions.newAttributeValue(v)
+		if !reflect.DeepEqual(av, expected) {
+			t.Errorf("error on createFlatAttributeValue, actual=%v, expected=%v", av, expected)
+		}
+	}
+
+	for _, v := range []interface{}{nil, int32(1), []string{"a"}, map[string]interface{}{}} {
+		if _, ok := defaultMarshalOptions.createFlatAttributeValue(v); ok {
+			t.Errorf("error on createFlatAttributeValue, value=%v must not be flat", v)
+		}
+	}
+}
+
+func benchmarkMarshalFlatItem() map[string]interface{} {
+	return map[string]interface{}{
+		"id":       int64(100),
+		"name":     "foo",
+		"email":    "foo@example.com",
+		"time":     int64(1435000000),
+		"updated":  int64(1435000100),
+		"score":    99.5,
+		"rate":     0.25,
+		"enabled":  true,
+		"verified": false,
+		"country":  "JP",
+	}
+}
+
+func BenchmarkMarshalFlat(b *testing.B) {
+	item := benchmarkMarshalFlatItem()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Marshal(item)
+	}
+}
+
+// the generic path without the fast path of the flat values
+func BenchmarkMarshalFlatGeneric(b *testing.B) {
+	item := benchmarkMarshalFlatItem()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data := make(map[string]*SDK.AttributeValue)
+		for key, val := range item {
+			data[key] = createAttributeValue(val)
+		}
+	}
+}
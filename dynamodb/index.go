@@ -10,8 +10,9 @@ const (
 	indexTypeLSI = "local"
 	indexTypeGSI = "global"
 
-	ProjectionTypeAll = "ALL"
+	ProjectionTypeAll      = "ALL"
 	ProjectionTypeKeysOnly = "KEYS_ONLY"
+	ProjectionTypeInclude  = "INCLUDE"
 )
 
 // DynamoIndex is wrapper struct for Index,
@@ -79,3 +80,25 @@ func NewGSI(name string, schema []*SDK.KeySchemaElement, tp *SDK.ProvisionedThro
 	gsi.Projection = &SDK.Projection{ProjectionType: &proj}
 	return gsi
 }
+
+// NewProjectionAll returns Projection for all of the attributes
+func NewProjectionAll() *SDK.Projection {
+	return &SDK.Projection{
+		ProjectionType: String(ProjectionTypeAll),
+	}
+}
+
+// NewProjectionKeysOnly returns Projection for the index and primary keys only
+func NewProjectionKeysOnly() *SDK.Projection {
+	return &SDK.Projection{
+		ProjectionType: String(ProjectionTypeKeysOnly),
+	}
+}
+
+// NewProjectionInclude returns Projection for the keys and the specified non-key attributes
+func NewProjectionInclude(attrs ...string) *SDK.Projection {
+	return &SDK.Projection{
+		ProjectionType:   String(ProjectionTypeInclude),
+		NonKeyAttributes: createPointerSliceString(attrs),
+	}
+}
@@ -102,3 +102,27 @@ func TestNewGSI(t *testing.T) {
 		t.Errorf("error on NewGSI, actual=%v", gsi2)
 	}
 }
+
+func TestNewProjectionAll(t *testing.T) {
+	p := NewProjectionAll()
+	if *p.ProjectionType != "ALL" || p.NonKeyAttributes != nil {
+		t.Errorf("error on NewProjectionAll, actual=%v", p)
+	}
+}
+
+func TestNewProjectionKeysOnly(t *testing.T) {
+	p := NewProjectionKeysOnly()
+	if *p.ProjectionType != "KEYS_ONLY" || p.NonKeyAttributes != nil {
+		t.Errorf("error on NewProjectionKeysOnly, actual=%v", p)
+	}
+}
+
+func TestNewProjectionInclude(t *testing.T) {
+	p := NewProjectionInclude("foo", "bar")
+	if *p.ProjectionType != "INCLUDE" || len(p.NonKeyAttributes) != 2 {
+		t.Errorf("error on NewProjectionInclude, actual=%v", p)
+	}
+	if *p.NonKeyAttributes[0] != "foo" || *p.NonKeyAttributes[1] != "bar" {
+		t.Errorf("error on NewProjectionInclude, actual=%v", p.NonKeyAttributes)
+	}
+}
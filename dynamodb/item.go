@@ -10,19 +10,40 @@ import (
 type DynamoItem struct {
 	data       map[string]*SDK.AttributeValue
 	conditions map[string]*SDK.ExpectedAttributeValue
+
+	// options to convert the values
+	opts *marshalOptions
 }
 
 // Create new empty Item
 func NewItem() *DynamoItem {
+	return defaultMarshalOptions.newItem()
+}
+
+// NewItem creates new empty Item which converts the values by the options of the client (e.g. SetOmitNilValue)
+func (t *DynamoTable) NewItem() *DynamoItem {
+	return t.options().newItem()
+}
+
+// Create new empty Item which converts the values by the options
+func (o *marshalOptions) newItem() *DynamoItem {
 	return &DynamoItem{
 		data:       make(map[string]*SDK.AttributeValue),
 		conditions: make(map[string]*SDK.ExpectedAttributeValue),
+		opts:       o,
 	}
 }
 
 // Add a attribute to the Item
 func (item *DynamoItem) AddAttribute(name string, value Any) {
-	item.data[name] = createAttributeValue(value)
+	if value == nil && item.opts.omitNilValue {
+		return
+	}
+	if av, ok := mustEncodeAttributeValue(name, value); ok {
+		item.data[name] = av
+		return
+	}
+	item.data[name] = item.opts.createAttributeValue(value)
 }
 
 // Add a EXIST condition for put
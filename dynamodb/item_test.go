@@ -45,6 +45,17 @@ func TestAddAttribute(t *testing.T) {
 		t.Errorf("error on add int value, actual=%+v", added)
 	}
 
+	item.AddAttribute("nil", nil)
+	added, _ = item.data["nil"]
+	if added.NULL == nil || *added.NULL != true {
+		t.Errorf("error on add nil value, actual=%+v", added)
+	}
+
+	item = (&marshalOptions{omitNilValue: true}).newItem()
+	item.AddAttribute("omit", nil)
+	if _, ok := item.data["omit"]; ok {
+		t.Errorf("error on add nil value, actual=%v", item.data)
+	}
 }
 
 func TestAddCondition(t *testing.T) {
@@ -0,0 +1,182 @@
+// DynamoDB distributed lock by the TTL-based lease
+
+package dynamodb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// attributes of the lock item
+	LockOwnerAttribute  = "lock_owner"
+	LockExpiryAttribute = "lock_expiry"
+
+	// wait time before retrying to acquire the lock held by the other owner
+	lockRetryWait = 100 * time.Millisecond
+)
+
+var (
+	// ErrLockContention is returned when the lock is held by the other owner until the context is done
+	ErrLockContention = errors.New("[DynamoDB] the lock is held by the other owner")
+
+	// ErrLockExpired is returned when the lease of the lock is expired or taken by the other owner
+	ErrLockExpired = errors.New("[DynamoDB] the lease of the lock is expired")
+)
+
+// Lock is the lease of the distributed lock on the item of the table
+type Lock struct {
+	table  *DynamoTable
+	key    map[string]interface{}
+	owner  string
+	ttl    time.Duration
+	expiry time.Time
+}
+
+// Acquire puts the lock item of the key with the lease of ttl, when the item does not exist or the lease is expired.
+// it retries while the lock is held by the other owner, and returns ErrLockContention when the context is done.
+// the expiry is stored in epoch seconds to LockExpiryAttribute, which can be used as the TTL attribute of the table
+// to delete the released or abandoned lock items
+func (t *DynamoTable) Acquire(ctx context.Context, key map[string]interface{}, ttl time.Duration) (*Lock, error) {
+	owner, err := newLockOwner()
+	if err != nil {
+		return nil, err
+	}
+	l := &Lock{
+		table: t,
+		key:   key,
+		owner: owner,
+		ttl:   ttl,
+	}
+
+	for {
+		err := l.put()
+		switch {
+		case err == nil:
+			return l, nil
+		case err != ErrConditionFailed:
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrLockContention
+		case <-time.After(lockRetryWait):
+		}
+	}
+}
+
+// put the lock item when the lock is free
+func (l *Lock) put() error {
+	t := l.table
+	now := t.db.currentTime()
+	expiry := lockExpiry(now, l.ttl)
+
+	item := make(map[string]interface{}, len(l.key)+2)
+	for k, v := range l.key {
+		item[k] = v
+	}
+	item[LockOwnerAttribute] = l.owner
+	item[LockExpiryAttribute] = expiry.Unix()
+
+	cond := newLockFreeCondition(t.NewFilterBuilder(), t.GetHashKeyName(), now)
+	_, err := t.putItem(&SDK.PutItemInput{
+		TableName:                 String(t.name),
+		Item:                      t.options().marshal(item),
+		ConditionExpression:       String(cond.Expression()),
+		ExpressionAttributeNames:  cond.attrs.expressionNames(),
+		ExpressionAttributeValues: cond.attrs.expressionValues(),
+	})
+	if err == nil {
+		l.expiry = expiry
+	}
+	return err
+}
+
+// Refresh extends the lease of the lock by the ttl,
+// returns ErrLockExpired when the lease is already expired or taken by the other owner
+func (l *Lock) Refresh() error {
+	t := l.table
+	now := t.db.currentTime()
+	expiry := lockExpiry(now, l.ttl)
+
+	b := t.NewUpdateBuilder()
+	b.Set(LockExpiryAttribute, expiry.Unix())
+	cond := newLockOwnerCondition(&FilterBuilder{attrs: b.attrs}, l.owner, now)
+
+	k, err := t.marshalKey(l.key)
+	if err != nil {
+		return err
+	}
+	in := b.newUpdateItemInput(t.name, k)
+	in.ConditionExpression = String(cond.Expression())
+	_, err = t.updateItem(in)
+	switch {
+	case err == ErrConditionFailed:
+		return ErrLockExpired
+	case err != nil:
+		return err
+	}
+	l.expiry = expiry
+	return nil
+}
+
+// Release deletes the lock item when the lock is still owned,
+// returns ErrLockExpired when the lease is already expired or taken by the other owner
+func (l *Lock) Release() error {
+	t := l.table
+	cond := newLockOwnerCondition(t.NewFilterBuilder(), l.owner, t.db.currentTime())
+	err := t.DeleteItemIf(l.key, cond)
+	if err == ErrConditionFailed {
+		return ErrLockExpired
+	}
+	return err
+}
+
+// Owner returns the random owner token of the lock
+func (l *Lock) Owner() string {
+	return l.owner
+}
+
+// Expiry returns the expiry of the lease
+func (l *Lock) Expiry() time.Time {
+	return l.expiry
+}
+
+// create the condition that the lock item does not exist or the lease is expired,
+// `(attribute_not_exists(hash) OR lock_expiry < now)`
+func newLockFreeCondition(cond *FilterBuilder, hashKey string, now time.Time) *FilterBuilder {
+	notExists := NewSharedFilterBuilder(cond)
+	notExists.AddNotExists(hashKey)
+	expired := NewSharedFilterBuilder(cond)
+	expired.AddLT(LockExpiryAttribute, now.Unix())
+	cond.Or(notExists, expired)
+	return cond
+}
+
+// add the condition that the lock is owned and the lease is not expired,
+// `lock_owner = owner AND lock_expiry >= now`
+func newLockOwnerCondition(cond *FilterBuilder, owner string, now time.Time) *FilterBuilder {
+	cond.AddEQ(LockOwnerAttribute, owner)
+	cond.AddGE(LockExpiryAttribute, now.Unix())
+	return cond
+}
+
+// get the expiry of the lease in seconds, the ttl less than a second is rounded up
+func lockExpiry(now time.Time, ttl time.Duration) time.Time {
+	return now.Add(ttl + time.Second - 1).Truncate(time.Second)
+}
+
+// create the random owner token
+func newLockOwner() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
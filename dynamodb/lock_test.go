@@ -0,0 +1,64 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAcquire(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	key := map[string]interface{}{"id": 500, "time": 1}
+
+	l, err := tbl.Acquire(context.Background(), key, 10*time.Second)
+	if err != nil {
+		t.Fatalf("error on Acquire, %s", err.Error())
+	}
+
+	// contention
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if _, err := tbl.Acquire(ctx, key, 10*time.Second); err != ErrLockContention {
+		t.Errorf("error on Acquire, %v", err)
+	}
+
+	if err := l.Refresh(); err != nil {
+		t.Errorf("error on Refresh, %s", err.Error())
+	}
+	if err := l.Release(); err != nil {
+		t.Errorf("error on Release, %s", err.Error())
+	}
+	if err := l.Release(); err != ErrLockExpired {
+		t.Errorf("error on Release, %v", err)
+	}
+	if err := l.Refresh(); err != ErrLockExpired {
+		t.Errorf("error on Refresh, %v", err)
+	}
+}
+
+func TestLockCondition(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cond := newLockFreeCondition(NewFilterBuilder(), "id", now)
+	if exp := cond.Expression(); exp != "((attribute_not_exists(#n0)) OR (#n1 < :v0))" {
+		t.Errorf("error on newLockFreeCondition, %s", exp)
+	}
+	if *cond.attrs.names["#n1"] != LockExpiryAttribute || *cond.attrs.values[":v0"].N != "1000" {
+		t.Errorf("error on newLockFreeCondition, %v", cond.attrs)
+	}
+
+	cond = newLockOwnerCondition(NewFilterBuilder(), "owner", now)
+	if exp := cond.Expression(); exp != "#n0 = :v0 AND #n1 >= :v1" {
+		t.Errorf("error on newLockOwnerCondition, %s", exp)
+	}
+}
+
+func TestLockExpiry(t *testing.T) {
+	now := time.Unix(1000, 500*int64(time.Millisecond))
+	if v := lockExpiry(now, 2*time.Second).Unix(); v != 1003 {
+		t.Errorf("error on lockExpiry, %d", v)
+	}
+	if v := lockExpiry(time.Unix(1000, 0), 2*time.Second).Unix(); v != 1002 {
+		t.Errorf("error on lockExpiry, %d", v)
+	}
+}
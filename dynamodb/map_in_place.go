@@ -0,0 +1,175 @@
+// DynamoDB in-place transform of the items for the backfill
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// default number of segments for parallel scan on MapInPlace
+const mapInPlaceSegments = 4
+
+// MapInPlaceOption is the option of MapInPlaceWithOption
+type MapInPlaceOption struct {
+	// number of segments for parallel scan, the default is 4 (ignored when Resume is set)
+	Segments int
+
+	// Resume is the checkpoint from Progress or the result of the last run, to resume the transform
+	Resume *ScanCheckpoint
+
+	// Progress is called with the counts and the checkpoint after every page is written,
+	// the calls are serialized between the segments
+	Progress func(MapInPlaceProgress)
+}
+
+// MapInPlaceProgress is the progress of MapInPlace, the counts are of the current run (not including the resumed part)
+type MapInPlaceProgress struct {
+	// number of scanned items
+	Scanned int
+
+	// number of updated items
+	Updated int
+
+	// Checkpoint is the position of the scan to resume the transform
+	Checkpoint *ScanCheckpoint
+}
+
+// MapInPlace transforms all of the items in the table by parallel scan of the concurrency segments,
+// and writes back only the changed attributes of the item. see MapInPlaceWithOption
+func (t *DynamoTable) MapInPlace(ctx context.Context, transform func(map[string]interface{}) (map[string]interface{}, error), concurrency int) error {
+	_, err := t.MapInPlaceWithOption(ctx, transform, MapInPlaceOption{Segments: concurrency})
+	return err
+}
+
+// MapInPlaceWithOption transforms the items from the checkpoint and writes back the changes by UpdateItem with DiffUpdate.
+// the item is skipped when transform returns nil or the same attributes, and when the item is deleted during the transform.
+// transform receives the deep copy of the item and must not change the key attributes.
+// the segments transform and write the items in parallel, and the progress of the pages already written is returned with the error to resume later
+func (t *DynamoTable) MapInPlaceWithOption(ctx context.Context, transform func(map[string]interface{}) (map[string]interface{}, error), opt MapInPlaceOption) (MapInPlaceProgress, error) {
+	checkpoint := opt.Resume
+	if checkpoint == nil {
+		segments := opt.Segments
+		if segments <= 0 {
+			segments = mapInPlaceSegments
+		}
+		checkpoint = NewScanCheckpoint(segments)
+	}
+
+	var mu sync.Mutex
+	var progress MapInPlaceProgress
+	handler := func(items []map[string]interface{}) error {
+		var scanned, updated int
+		defer func() {
+			mu.Lock()
+			progress.Scanned += scanned
+			progress.Updated += updated
+			mu.Unlock()
+		}()
+		for _, item := range items {
+			scanned++
+			ok, err := t.mapItemInPlace(item, transform)
+			if err != nil {
+				return err
+			}
+			if ok {
+				updated++
+			}
+		}
+		return nil
+	}
+	var afterPage func(*ScanCheckpoint)
+	if opt.Progress != nil {
+		afterPage = func(cp *ScanCheckpoint) {
+			mu.Lock()
+			p := MapInPlaceProgress{
+				Scanned:    progress.Scanned,
+				Updated:    progress.Updated,
+				Checkpoint: cp.clone(),
+			}
+			mu.Unlock()
+			opt.Progress(p)
+		}
+	}
+
+	cp, err := t.scanResumable(ctx, checkpoint, "MapInPlace", handler, afterPage, true)
+	progress.Checkpoint = cp
+	if err != nil {
+		return progress, err
+	}
+	log.Info("[DynamoDB] MapInPlace, table="+t.name+", scanned="+strconv.Itoa(progress.Scanned)+", updated=", progress.Updated)
+	return progress, nil
+}
+
+// transform the item and update the changed attributes, returns true when the item is updated
+func (t *DynamoTable) mapItemInPlace(item map[string]interface{}, transform func(map[string]interface{}) (map[string]interface{}, error)) (bool, error) {
+	out, err := transform(deepCopyItem(item))
+	if err != nil || out == nil {
+		return false, err
+	}
+
+	key := make(map[string]interface{}, 2)
+	for _, name := range t.keyNames() {
+		if !t.options().isSameAttributeValue(item[name], out[name]) {
+			return false, fmt.Errorf("[DynamoDB] the transform cannot change the key attribute, table=%s, name=%s", t.name, name)
+		}
+		key[name] = item[name]
+	}
+
+	b := t.options().diffUpdate(item, out)
+	if b.Expression() == "" {
+		return false, nil
+	}
+	// do not recreate the item deleted after the scan
+	cond := b.NewCondition()
+	cond.AddExists(Literal(t.GetHashKeyName()))
+	switch err := t.UpdateItemIf(key, b, cond); err {
+	case nil:
+		return true, nil
+	case ErrConditionFailed:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// copy the item deeply, the transform can change the nested map and slice without changing the original item
+func deepCopyItem(item map[string]interface{}) map[string]interface{} {
+	return deepCopyValue(item).(map[string]interface{})
+}
+
+// copy the value deeply, the map and the slice (including the set and the binary) are copied recursively
+func deepCopyValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, elem := range t {
+			m[k] = deepCopyValue(elem)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(t))
+		for i, elem := range t {
+			list[i] = deepCopyValue(elem)
+		}
+		return list
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.IsNil() {
+		return v
+	}
+	c := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+	reflect.Copy(c, rv)
+	if rv.Type().Elem().Kind() == reflect.Slice {
+		for i := 0; i < c.Len(); i++ {
+			c.Index(i).Set(reflect.ValueOf(deepCopyValue(c.Index(i).Interface())))
+		}
+	}
+	return c.Interface()
+}
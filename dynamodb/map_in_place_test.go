@@ -0,0 +1,126 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMapInPlace(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 10; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	// only the items of the even number are changed
+	transform := func(item map[string]interface{}) (map[string]interface{}, error) {
+		if n, ok := item["time"].(int); ok && n%2 == 0 {
+			item["lsi_key"] = "even"
+		}
+		return item, nil
+	}
+	var calls int
+	progress, err := tbl.MapInPlaceWithOption(context.Background(), transform, MapInPlaceOption{
+		Segments: 2,
+		Progress: func(p MapInPlaceProgress) {
+			calls++
+		},
+	})
+	if err != nil {
+		t.Errorf("error on MapInPlace, %s", err.Error())
+	}
+	if progress.Scanned != 10 || progress.Updated != 5 || !progress.Checkpoint.Done() || calls == 0 {
+		t.Errorf("error on MapInPlace, %v, calls=%d", progress, calls)
+	}
+	item, _ := tbl.GetOne(100, 2)
+	if item["lsi_key"] != "even" {
+		t.Errorf("error on MapInPlace, %v", item)
+	}
+	item, _ = tbl.GetOne(100, 3)
+	if item["lsi_key"] != "lsi_value" {
+		t.Errorf("error on MapInPlace, %v", item)
+	}
+
+	// the second run does not update the items
+	progress, err = tbl.MapInPlaceWithOption(context.Background(), transform, MapInPlaceOption{})
+	if err != nil || progress.Scanned != 10 || progress.Updated != 0 {
+		t.Errorf("error on MapInPlace, %v, %v", progress, err)
+	}
+
+	// stop on the transform error
+	errStop := errors.New("stop")
+	err = tbl.MapInPlace(context.Background(), func(item map[string]interface{}) (map[string]interface{}, error) {
+		return nil, errStop
+	}, 2)
+	if err != errStop {
+		t.Errorf("error on MapInPlace, %v", err)
+	}
+}
+
+func TestMapItemInPlace(t *testing.T) {
+	tbl := getTestCacheTable()
+	item := map[string]interface{}{"id": 100, "time": 1, "name": "foo"}
+
+	// nil and the same attributes are skipped without the request
+	tests := []func(map[string]interface{}) (map[string]interface{}, error){
+		func(item map[string]interface{}) (map[string]interface{}, error) {
+			return nil, nil
+		},
+		func(item map[string]interface{}) (map[string]interface{}, error) {
+			item["time"] = int64(1)
+			return item, nil
+		},
+	}
+	for _, fn := range tests {
+		if updated, err := tbl.mapItemInPlace(item, fn); updated || err != nil {
+			t.Errorf("error on mapItemInPlace, %v, %v", updated, err)
+		}
+	}
+
+	// the key cannot be changed, and the input item is not modified by the transform
+	updated, err := tbl.mapItemInPlace(item, func(item map[string]interface{}) (map[string]interface{}, error) {
+		item["time"] = 2
+		return item, nil
+	})
+	if updated || err == nil {
+		t.Errorf("error on mapItemInPlace, the change of the key is accepted")
+	}
+	if item["time"] != 1 {
+		t.Errorf("error on mapItemInPlace, the item is modified, %v", item)
+	}
+
+	// the nested attributes are not modified by the transform
+	item["profile"] = map[string]interface{}{"tags": []interface{}{"a"}}
+	item["ss"] = []string{"x"}
+	_, err = tbl.mapItemInPlace(item, func(item map[string]interface{}) (map[string]interface{}, error) {
+		profile := item["profile"].(map[string]interface{})
+		profile["tags"].([]interface{})[0] = "b"
+		profile["name"] = "bar"
+		item["ss"].([]string)[0] = "y"
+		item["time"] = 2
+		return item, nil
+	})
+	if err == nil {
+		t.Errorf("error on mapItemInPlace, the change of the key is accepted")
+	}
+	profile := item["profile"].(map[string]interface{})
+	if len(profile) != 1 || profile["tags"].([]interface{})[0] != "a" || item["ss"].([]string)[0] != "x" {
+		t.Errorf("error on mapItemInPlace, the nested attribute is modified, %v", item)
+	}
+}
+
+func TestDeepCopyValue(t *testing.T) {
+	bs := [][]byte{[]byte("a")}
+	c := deepCopyValue(bs).([][]byte)
+	c[0][0] = 'b'
+	if string(bs[0]) != "a" {
+		t.Errorf("error on deepCopyValue, the binary set is modified, %v", bs)
+	}
+	if v := deepCopyValue([]string(nil)); v.([]string) != nil {
+		t.Errorf("error on deepCopyValue, actual=%v", v)
+	}
+	if v := deepCopyValue(100); v != 100 {
+		t.Errorf("error on deepCopyValue, actual=%v", v)
+	}
+}
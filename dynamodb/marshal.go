@@ -0,0 +1,453 @@
+// DynamoDB Item conversion from/to struct
+
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// MarshalStruct converts the struct into DynamoDB Item data by the struct tags (see parseStructFields),
+// the values are converted as same as MarshalWithError after the nested struct is converted into map(M).
+// the slice of string, number and []byte is stored as the set (SS, NS and BS) and the empty set is skipped because DynamoDB does not accept it.
+// the nil pointer field is set as NULL, or skipped on the client with SetOmitNilValue(true),
+// and the zero value of the field with omitempty is skipped. returns error for the value out of the enum option
+func MarshalStruct(v interface{}) (*map[string]*SDK.AttributeValue, error) {
+	return defaultMarshalOptions.marshalStruct(v)
+}
+
+// MarshalStruct converts the struct into DynamoDB Item data by the options of the client (see the package-level MarshalStruct)
+func (d *AmazonDynamoDB) MarshalStruct(v interface{}) (*map[string]*SDK.AttributeValue, error) {
+	return d.options().marshalStruct(v)
+}
+
+// convert the struct into DynamoDB Item data by the options
+func (o *marshalOptions) marshalStruct(v interface{}) (*map[string]*SDK.AttributeValue, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("[DynamoDB] the value must be struct")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("[DynamoDB] the value must be struct")
+	}
+
+	item, err := o.structToMap(rv)
+	if err != nil {
+		return nil, err
+	}
+	return o.marshalItem(item, o.sortedKeys)
+}
+
+// MarshalStructs converts the slice of the struct or the pointer to struct (e.g. []*User) into DynamoDB Items by MarshalStruct,
+// returns error for the nil element because the empty item cannot be written (e.g. BatchWriteItem rejects it)
+func MarshalStructs(v interface{}) ([]*map[string]*SDK.AttributeValue, error) {
+	return defaultMarshalOptions.marshalStructs(v)
+}
+
+// MarshalStructs converts the slice of the struct into DynamoDB Items by the options of the client (see the package-level MarshalStructs)
+func (d *AmazonDynamoDB) MarshalStructs(v interface{}) ([]*map[string]*SDK.AttributeValue, error) {
+	return d.options().marshalStructs(v)
+}
+
+// convert the slice of the struct into DynamoDB Items by the options
+func (o *marshalOptions) marshalStructs(v interface{}) ([]*map[string]*SDK.AttributeValue, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, errors.New("[DynamoDB] the value must be slice of struct")
+	}
+
+	items := make([]*map[string]*SDK.AttributeValue, rv.Len())
+	for i := range items {
+		elem := rv.Index(i)
+		if (elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface) && elem.IsNil() {
+			return nil, fmt.Errorf("[DynamoDB] the element of the slice must not be nil, index=%d", i)
+		}
+		item, err := o.marshalStruct(elem.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%s, index=%d", err.Error(), i)
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// convert the struct into the map of the attributes
+func (o *marshalOptions) structToMap(rv reflect.Value) (map[string]interface{}, error) {
+	fields, err := structFieldsOf(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	item := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue
+		}
+		var value interface{}
+		switch {
+		case f.defaultValue != nil && fv.IsZero():
+			value = f.defaultValue
+		case f.omitEmpty && fv.IsZero(), isEmptySet(fv):
+			continue
+		case f.dateFormat:
+			if tv, ok := structFieldValue(fv).(time.Time); ok {
+				value = NewDate(tv)
+			}
+		default:
+			if err := f.validateEnum(fv); err != nil {
+				return nil, err
+			}
+			value, err = o.marshalValue(fv)
+			if err != nil {
+				return nil, errors.New(err.Error() + ", attribute=" + f.name)
+			}
+		}
+		item[f.name] = value
+	}
+	return item, nil
+}
+
+// convert the value of the field into the value for the attribute,
+// the struct is converted into map, the slice of struct is converted into list
+// and the named type of string, bool and number is converted into the underlying type
+func (o *marshalOptions) marshalValue(fv reflect.Value) (interface{}, error) {
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	v := fv.Interface()
+	if _, ok := o.encodeNumber(v); ok {
+		return v, nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		switch fv.Type() {
+		case dateType:
+			return v, nil
+		case timeType:
+			return nil, errors.New("[DynamoDB] time.Time requires format=date option on struct")
+		}
+		return o.structToMap(fv)
+	case reflect.Slice, reflect.Array:
+		if !isListOfValues(fv.Type().Elem()) {
+			return v, nil
+		}
+		list := make([]interface{}, fv.Len())
+		for i := range list {
+			elem, err := o.marshalValue(fv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list[i] = elem
+		}
+		return list, nil
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String {
+			return nil, errors.New("[DynamoDB] the key of the map must be string, type=" + fv.Type().String())
+		}
+		m := make(map[string]interface{}, fv.Len())
+		iter := fv.MapRange()
+		for iter.Next() {
+			elem, err := o.marshalValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[iter.Key().String()] = elem
+		}
+		return m, nil
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return fv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fv.Uint(), nil
+	case reflect.Float32:
+		return float32(fv.Float()), nil
+	case reflect.Float64:
+		return fv.Float(), nil
+	}
+	return v, nil
+}
+
+// check if the slice of the element type is converted into list(L) by each element,
+// the slice of string, number and []byte is converted as the set by createAttributeValue
+func isListOfValues(elem reflect.Type) bool {
+	switch elem.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Map, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return elem.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// check if the value is the empty slice to store as the set
+func isEmptySet(fv reflect.Value) bool {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return false
+		}
+		fv = fv.Elem()
+	}
+	if fv.Kind() != reflect.Slice || fv.Len() != 0 {
+		return false
+	}
+	switch elem := fv.Type().Elem(); elem.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return elem.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// UnmarshalStruct converts DynamoDB Item data into the struct of the pointer by the struct tags (see parseStructFields),
+// the field of the absent attribute is not changed, and the pointer field is nil for NULL.
+// the time.Time field with format=date is set as the beginning of the date in UTC
+func UnmarshalStruct(item *map[string]*SDK.AttributeValue, v interface{}) error {
+	return defaultMarshalOptions.unmarshalStruct(item, v)
+}
+
+// UnmarshalStruct converts DynamoDB Item data into the struct of the pointer by the options of the client (see the package-level UnmarshalStruct)
+func (d *AmazonDynamoDB) UnmarshalStruct(item *map[string]*SDK.AttributeValue, v interface{}) error {
+	return d.options().unmarshalStruct(item, v)
+}
+
+// convert DynamoDB Item data into the struct of the pointer by the options
+func (o *marshalOptions) unmarshalStruct(item *map[string]*SDK.AttributeValue, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("[DynamoDB] the value must be non-nil pointer to struct")
+	}
+	if item == nil {
+		return nil
+	}
+	return o.unmarshalStructValue(rv.Elem(), *item, true)
+}
+
+// set the attributes into the fields of the struct,
+// the encrypted or compressed attribute is decoded for the top-level attributes
+func (o *marshalOptions) unmarshalStructValue(rv reflect.Value, item map[string]*SDK.AttributeValue, topLevel bool) error {
+	fields, err := structFieldsOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		av, ok := item[f.name]
+		if !ok || av == nil {
+			continue
+		}
+		if topLevel {
+			av = o.decodedAttributeValue(f.name, av)
+		}
+
+		fv := allocFieldByIndex(rv, f.index)
+		if f.dateFormat {
+			err = unmarshalDateTime(fv, av)
+		} else {
+			err = o.unmarshalValue(fv, av)
+		}
+		if err != nil {
+			return errors.New(err.Error() + ", attribute=" + f.name)
+		}
+	}
+	return nil
+}
+
+// get the decrypted or decompressed AttributeValue of the attribute
+func (o *marshalOptions) decodedAttributeValue(name string, av *SDK.AttributeValue) *SDK.AttributeValue {
+	if v, ok := decryptAttributeValue(name, av); ok {
+		return o.createAttributeValue(v)
+	}
+	if s, ok := decompressAttributeValue(av); ok {
+		return &SDK.AttributeValue{
+			S: String(s),
+		}
+	}
+	return av
+}
+
+// set the AttributeValue into the value by the type of the value,
+// the number is parsed by the type to keep the precision
+func (o *marshalOptions) unmarshalValue(fv reflect.Value, av *SDK.AttributeValue) error {
+	if av.NULL != nil && *av.NULL {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	typ := fv.Type()
+	switch fv.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(typ.Elem())
+		if err := o.unmarshalValue(p.Elem(), av); err != nil {
+			return err
+		}
+		fv.Set(p)
+		return nil
+	case reflect.Interface:
+		if typ.NumMethod() != 0 {
+			break
+		}
+		if v := o.getItemValue(av); v != nil {
+			fv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	case reflect.String:
+		if av.S != nil {
+			fv.SetString(*av.S)
+			return nil
+		}
+	case reflect.Bool:
+		if av.BOOL != nil {
+			fv.SetBool(*av.BOOL)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if av.N != nil {
+			return setNumberValue(fv, *av.N)
+		}
+	case reflect.Struct:
+		switch {
+		case typ == dateType && av.S != nil:
+			d, err := ParseDate(*av.S)
+			if err != nil {
+				return err
+			}
+			fv.Set(reflect.ValueOf(d))
+			return nil
+		case av.N != nil:
+			// custom number type (e.g. decimal) by the codec
+			if v, ok := o.decodeNumber(*av.N); ok && reflect.TypeOf(v).AssignableTo(typ) {
+				fv.Set(reflect.ValueOf(v))
+				return nil
+			}
+		case av.M != nil && typ != timeType:
+			return o.unmarshalStructValue(fv, *av.M, false)
+		}
+	case reflect.Slice:
+		return o.unmarshalSliceValue(fv, av)
+	case reflect.Map:
+		if av.M == nil || typ.Key().Kind() != reflect.String {
+			break
+		}
+		m := reflect.MakeMapWithSize(typ, len(*av.M))
+		for k, v := range *av.M {
+			elem := reflect.New(typ.Elem()).Elem()
+			if err := o.unmarshalValue(elem, v); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(typ.Key()), elem)
+		}
+		fv.Set(m)
+		return nil
+	}
+	return fmt.Errorf("[DynamoDB] cannot unmarshal the attribute into %s", typ)
+}
+
+// set the binary, set or list AttributeValue into the slice
+func (o *marshalOptions) unmarshalSliceValue(fv reflect.Value, av *SDK.AttributeValue) error {
+	typ := fv.Type()
+	if typ.Elem().Kind() == reflect.Uint8 && av.B != nil {
+		fv.Set(reflect.ValueOf(av.B).Convert(typ))
+		return nil
+	}
+
+	var elems []*SDK.AttributeValue
+	switch {
+	case av.SS != nil:
+		for _, s := range av.SS {
+			elems = append(elems, &SDK.AttributeValue{S: s})
+		}
+	case av.NS != nil:
+		for _, n := range av.NS {
+			elems = append(elems, &SDK.AttributeValue{N: n})
+		}
+	case av.BS != nil:
+		for _, b := range av.BS {
+			elems = append(elems, &SDK.AttributeValue{B: b})
+		}
+	case av.L != nil:
+		elems = av.L
+	default:
+		return fmt.Errorf("[DynamoDB] cannot unmarshal the attribute into %s", typ)
+	}
+
+	list := reflect.MakeSlice(typ, len(elems), len(elems))
+	for i, elem := range elems {
+		if err := o.unmarshalValue(list.Index(i), elem); err != nil {
+			return err
+		}
+	}
+	fv.Set(list)
+	return nil
+}
+
+// parse the number string by the kind of the value
+func setNumberValue(fv reflect.Value, s string) error {
+	bits := fv.Type().Bits()
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, bits)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, bits)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	default:
+		n, err := strconv.ParseFloat(s, bits)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	}
+	return nil
+}
+
+// set the Date string into the time.Time (or the pointer) for the field with format=date
+func unmarshalDateTime(fv reflect.Value, av *SDK.AttributeValue) error {
+	if av.NULL != nil && *av.NULL {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	if av.S == nil {
+		return fmt.Errorf("[DynamoDB] cannot unmarshal the attribute into %s", fv.Type())
+	}
+	d, err := ParseDate(*av.S)
+	if err != nil {
+		return err
+	}
+
+	tv := reflect.ValueOf(d.Time(time.UTC))
+	if fv.Kind() == reflect.Ptr {
+		p := reflect.New(timeType)
+		p.Elem().Set(tv)
+		tv = p
+	}
+	fv.Set(tv)
+	return nil
+}
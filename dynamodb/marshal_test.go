@@ -0,0 +1,357 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+type testAddress struct {
+	City string `dynamo:"city"`
+	Zip  *int   `dynamo:"zip"`
+}
+
+type testProfile struct {
+	ID       int64             `dynamo:"id"`
+	Name     string            `dynamo:"name"`
+	Score    float64           `dynamo:"score"`
+	Level    uint8             `dynamo:"level"`
+	Active   bool              `dynamo:"active"`
+	Tags     []string          `dynamo:"tags"`
+	Points   []int             `dynamo:"points"`
+	Data     []byte            `dynamo:"data"`
+	Wait     time.Duration     `dynamo:"wait"`
+	Birthday time.Time         `dynamo:"dob,format=date"`
+	Since    Date              `dynamo:"since"`
+	Nick     *string           `dynamo:"nick"`
+	Address  testAddress       `dynamo:"address"`
+	History  []testAddress     `dynamo:"history"`
+	Extra    map[string]string `dynamo:"extra"`
+	Memo     string            `dynamo:"memo,omitempty"`
+	Secret   string            `dynamo:"-"`
+	Country  string
+}
+
+func TestMarshalStruct(t *testing.T) {
+	zip := 1000001
+	nick := "foo"
+	v := testProfile{
+		ID:       100,
+		Name:     "Alice",
+		Score:    12.5,
+		Level:    3,
+		Active:   true,
+		Tags:     []string{"a", "b"},
+		Points:   []int{1, 20, 300},
+		Data:     []byte{0x01, 0x02},
+		Wait:     3 * time.Second,
+		Birthday: time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC),
+		Since:    Date{Year: 2020, Month: time.April, Day: 1},
+		Nick:     &nick,
+		Address:  testAddress{City: "Tokyo", Zip: &zip},
+		History:  []testAddress{{City: "Osaka"}},
+		Extra:    map[string]string{"lang": "ja"},
+		Secret:   "secret",
+		Country:  "JP",
+	}
+	item, err := MarshalStruct(&v)
+	if err != nil {
+		t.Errorf("error on MarshalStruct, %s", err.Error())
+	}
+
+	m := *item
+	switch {
+	case *m["id"].N != "100", *m["name"].S != "Alice", *m["score"].N != "12.5", !*m["active"].BOOL:
+		t.Errorf("error on MarshalStruct, %v", m)
+	case len(m["tags"].SS) != 2, len(m["points"].NS) != 3, len(m["data"].B) != 2:
+		t.Errorf("error on MarshalStruct, %v", m)
+	case *m["dob"].S != "2000-02-29", *m["since"].S != "2020-04-01":
+		t.Errorf("error on MarshalStruct, %v", m)
+	case *(*m["address"].M)["city"].S != "Tokyo", len(m["history"].L) != 1:
+		t.Errorf("error on MarshalStruct, %v", m)
+	case *m["Country"].S != "JP":
+		t.Errorf("error on MarshalStruct, the field name must be used, %v", m)
+	}
+	if _, ok := m["memo"]; ok {
+		t.Errorf("error on MarshalStruct, the empty field with omitempty is marshaled")
+	}
+	if _, ok := m["Secret"]; ok {
+		t.Errorf("error on MarshalStruct, the skipped field is marshaled")
+	}
+
+	// round trip
+	var result testProfile
+	if err := UnmarshalStruct(item, &result); err != nil {
+		t.Errorf("error on UnmarshalStruct, %s", err.Error())
+	}
+	v.Secret = ""
+	if !reflect.DeepEqual(v, result) {
+		t.Errorf("error on UnmarshalStruct, %#v", result)
+	}
+
+	if _, err := MarshalStruct("foo"); err == nil {
+		t.Errorf("error on MarshalStruct, the string is accepted")
+	}
+	if _, err := MarshalStruct((*testProfile)(nil)); err == nil {
+		t.Errorf("error on MarshalStruct, nil pointer is accepted")
+	}
+	invalid := struct {
+		Created time.Time `dynamo:"created"`
+	}{}
+	if _, err := MarshalStruct(invalid); err == nil {
+		t.Errorf("error on MarshalStruct, time.Time without format is accepted")
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	item := &map[string]*SDK.AttributeValue{
+		"city": {S: String("Tokyo")},
+		"zip":  {NULL: Boolean(true)},
+	}
+	zip := 100
+	v := testAddress{Zip: &zip}
+	if err := UnmarshalStruct(item, &v); err != nil {
+		t.Errorf("error on UnmarshalStruct, %s", err.Error())
+	}
+	if v.City != "Tokyo" || v.Zip != nil {
+		t.Errorf("error on UnmarshalStruct, %v", v)
+	}
+
+	// the absent attribute does not change the field
+	v = testAddress{City: "Osaka"}
+	UnmarshalStruct(&map[string]*SDK.AttributeValue{}, &v)
+	if v.City != "Osaka" || v.Zip != nil {
+		t.Errorf("error on UnmarshalStruct, %v", v)
+	}
+
+	// the mismatched type
+	mismatched := &map[string]*SDK.AttributeValue{
+		"city": {N: String("1")},
+	}
+	if err := UnmarshalStruct(mismatched, &v); err == nil {
+		t.Errorf("error on UnmarshalStruct, the number is accepted for string")
+	}
+
+	invalid := []interface{}{
+		nil,
+		v,
+		(*testAddress)(nil),
+		new(string),
+	}
+	for _, target := range invalid {
+		if err := UnmarshalStruct(item, target); err == nil {
+			t.Errorf("error on UnmarshalStruct, %#v is accepted", target)
+		}
+	}
+}
+
+type testTimestamps struct {
+	Created int64 `dynamodb:"created_at"`
+	Updated int64 `dynamodb:"updated_at,omitempty"`
+}
+
+type testOwner struct {
+	OwnerID string `dynamodb:"owner_id"`
+}
+
+type TestGroup struct {
+	GroupID string `dynamodb:"group_id"`
+}
+
+type testDocument struct {
+	testTimestamps
+	*testOwner `dynamodb:"-"`
+	*Date
+	ID      string `dynamodb:"id,hash"`
+	Updated string `dynamodb:"updated_at"`
+}
+
+func TestMarshalStructEmbedded(t *testing.T) {
+	v := testDocument{
+		testTimestamps: testTimestamps{Created: 100, Updated: 200},
+		ID:             "doc1",
+		Updated:        "outer",
+	}
+	item, err := MarshalStruct(v)
+	if err != nil {
+		t.Errorf("error on MarshalStruct, %s", err.Error())
+	}
+	m := *item
+	if len(m) != 4 || *m["created_at"].N != "100" || *m["id"].S != "doc1" {
+		t.Errorf("error on MarshalStruct, the embedded fields must be flattened, %v", m)
+	}
+	if !*m["Date"].NULL {
+		t.Errorf("error on MarshalStruct, Date must not be flattened, %v", m)
+	}
+	if *m["updated_at"].S != "outer" {
+		t.Errorf("error on MarshalStruct, the outer field must win, %v", m)
+	}
+
+	var result testDocument
+	if err := UnmarshalStruct(item, &result); err != nil {
+		t.Errorf("error on UnmarshalStruct, %s", err.Error())
+	}
+	if result.Created != 100 || result.testTimestamps.Updated != 0 || result.Updated != "outer" || result.ID != "doc1" {
+		t.Errorf("error on UnmarshalStruct, %#v", result)
+	}
+
+	// the embedded pointer is allocated on unmarshal, and the pointer to the unexported struct is skipped
+	type withPointer struct {
+		*TestGroup
+		*testOwner
+		ID string `dynamodb:"id"`
+	}
+	item, _ = MarshalStruct(withPointer{ID: "doc2"})
+	if len(*item) != 1 {
+		t.Errorf("error on MarshalStruct, the nil embedded pointer must be skipped, %v", *item)
+	}
+	(*item)["group_id"] = &SDK.AttributeValue{S: String("group1")}
+	(*item)["owner_id"] = &SDK.AttributeValue{S: String("user1")}
+	var wp withPointer
+	if err := UnmarshalStruct(item, &wp); err != nil {
+		t.Errorf("error on UnmarshalStruct, %s", err.Error())
+	}
+	if wp.TestGroup == nil || wp.GroupID != "group1" || wp.testOwner != nil {
+		t.Errorf("error on UnmarshalStruct, %#v", wp)
+	}
+}
+
+func TestMarshalStructSet(t *testing.T) {
+	type sets struct {
+		Names  []string  `dynamodb:"names"`
+		Counts []int64   `dynamodb:"counts"`
+		Rates  []float64 `dynamodb:"rates"`
+		Blobs  [][]byte  `dynamodb:"blobs"`
+		Empty  []string  `dynamodb:"empty"`
+		List   []bool    `dynamodb:"list"`
+	}
+	v := sets{
+		Names:  []string{"a", "b"},
+		Counts: []int64{1, 9007199254740993},
+		Rates:  []float64{0.5, 1.25},
+		Blobs:  [][]byte{{0x01}, {0x02, 0x03}},
+		Empty:  []string{},
+		List:   []bool{true, false},
+	}
+	item, err := MarshalStruct(v)
+	if err != nil {
+		t.Errorf("error on MarshalStruct, %s", err.Error())
+	}
+	m := *item
+	if len(m["names"].SS) != 2 || len(m["counts"].NS) != 2 || len(m["rates"].NS) != 2 || len(m["blobs"].BS) != 2 {
+		t.Errorf("error on MarshalStruct, %v", m)
+	}
+	if _, ok := m["empty"]; ok {
+		t.Errorf("error on MarshalStruct, the empty set must be skipped")
+	}
+	if len(m["list"].L) != 2 {
+		t.Errorf("error on MarshalStruct, %v", m)
+	}
+
+	var result sets
+	if err := UnmarshalStruct(item, &result); err != nil {
+		t.Errorf("error on UnmarshalStruct, %s", err.Error())
+	}
+	v.Empty = nil
+	if !reflect.DeepEqual(v, result) {
+		t.Errorf("error on UnmarshalStruct, %#v", result)
+	}
+}
+
+type testRole string
+
+func TestMarshalStructEnum(t *testing.T) {
+	type user struct {
+		ID   int      `dynamodb:"id"`
+		Role testRole `dynamodb:"role,enum=admin|user|guest"`
+		Plan *string  `dynamodb:"plan,enum=free|pro"`
+		Kind string   `dynamodb:"kind,omitempty,enum=a|b"`
+	}
+	item, err := MarshalStruct(user{ID: 1, Role: "admin"})
+	if err != nil {
+		t.Errorf("error on MarshalStruct with enum, %s", err.Error())
+	}
+	if m := *item; *m["role"].S != "admin" || m["plan"].NULL == nil {
+		t.Errorf("error on MarshalStruct with enum, %v", m)
+	}
+	if _, ok := (*item)["kind"]; ok {
+		t.Errorf("error on MarshalStruct with enum, the empty value with omitempty must be skipped")
+	}
+
+	plan := "enterprise"
+	invalid := []user{
+		{ID: 1, Role: "root"},
+		{ID: 1, Role: ""},
+		{ID: 1, Role: "user", Plan: &plan},
+	}
+	for i, v := range invalid {
+		if _, err := MarshalStruct(v); err == nil {
+			t.Errorf("error on MarshalStruct, the value out of enum is accepted, #%d", i)
+		}
+	}
+
+	// enum is only for string
+	type invalidEnum struct {
+		Level int `dynamodb:"level,enum=1|2"`
+	}
+	if _, err := MarshalStruct(invalidEnum{}); err == nil {
+		t.Errorf("error on MarshalStruct, enum is accepted for int")
+	}
+	type invalidDefault struct {
+		Role string `dynamodb:"role,default=root,enum=admin|user"`
+	}
+	if _, err := MarshalStruct(invalidDefault{}); err == nil {
+		t.Errorf("error on MarshalStruct, the default value out of enum is accepted")
+	}
+}
+
+func TestMarshalStructPointer(t *testing.T) {
+	type user struct {
+		ID   int    `dynamodb:"id"`
+		Name string `dynamodb:"name"`
+	}
+	item, err := MarshalStruct(&user{ID: 1, Name: "foo"})
+	if err != nil {
+		t.Errorf("error on MarshalStruct with pointer, %s", err.Error())
+	}
+	if m := *item; *m["id"].N != "1" || *m["name"].S != "foo" {
+		t.Errorf("error on MarshalStruct with pointer, %v", m)
+	}
+	var nilUser *user
+	if _, err := MarshalStruct(nilUser); err == nil {
+		t.Errorf("error on MarshalStruct, nil pointer is accepted")
+	}
+}
+
+func TestMarshalStructs(t *testing.T) {
+	type user struct {
+		ID   int    `dynamodb:"id"`
+		Name string `dynamodb:"name"`
+	}
+	items, err := MarshalStructs([]*user{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}})
+	if err != nil {
+		t.Errorf("error on MarshalStructs, %s", err.Error())
+	}
+	if len(items) != 2 || *(*items[0])["id"].N != "1" || *(*items[1])["name"].S != "bar" {
+		t.Errorf("error on MarshalStructs, %v", items)
+	}
+
+	items, err = MarshalStructs([]user{{ID: 3}})
+	if err != nil || len(items) != 1 || *(*items[0])["id"].N != "3" {
+		t.Errorf("error on MarshalStructs, %v, %v", items, err)
+	}
+
+	invalid := []interface{}{
+		[]*user{{ID: 1}, nil},
+		[]int{1},
+		user{ID: 1},
+		nil,
+	}
+	for i, v := range invalid {
+		if _, err := MarshalStructs(v); err == nil {
+			t.Errorf("error on MarshalStructs, error must be returned, #%d", i)
+		}
+	}
+}
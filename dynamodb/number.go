@@ -0,0 +1,39 @@
+// DynamoDB number codec
+
+package dynamodb
+
+// NumberCodec converts the value of number(N) attribute,
+// use this to keep the precision of the number instead of int and float64
+type NumberCodec interface {
+	Encode(v interface{}) (string, error)
+	Decode(s string) (interface{}, error)
+}
+
+// SetNumberCodec sets the codec for number(N) attribute on the tables of the client, nil codec restores default conversion
+func (d *AmazonDynamoDB) SetNumberCodec(c NumberCodec) {
+	d.marshalOptions.numberCodec = c
+}
+
+// encode the value into number string by the codec
+func (o *marshalOptions) encodeNumber(v Any) (string, bool) {
+	if o.numberCodec == nil {
+		return "", false
+	}
+	s, err := o.numberCodec.Encode(v)
+	if err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// decode the number string into the value by the codec
+func (o *marshalOptions) decodeNumber(s string) (Any, bool) {
+	if o.numberCodec == nil {
+		return nil, false
+	}
+	v, err := o.numberCodec.Decode(s)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,40 @@
+//go:build decimal
+// +build decimal
+
+// DynamoDB number codec for decimal
+
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// DecimalCodec is the NumberCodec to decode number(N) attribute into decimal.Decimal
+type DecimalCodec struct{}
+
+// Encode converts decimal.Decimal and the number types into number string
+func (DecimalCodec) Encode(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case decimal.Decimal:
+		return t.String(), nil
+	case *decimal.Decimal:
+		if t != nil {
+			return t.String(), nil
+		}
+	case int, int32, int64, uint, uint32, uint64:
+		return fmt.Sprint(t), nil
+	case float32:
+		return decimal.NewFromFloat(float64(t)).String(), nil
+	case float64:
+		return decimal.NewFromFloat(t).String(), nil
+	}
+	return "", errors.New("[DynamoDB] unsupported type for DecimalCodec")
+}
+
+// Decode converts number string into decimal.Decimal
+func (DecimalCodec) Decode(s string) (interface{}, error) {
+	return decimal.NewFromString(s)
+}
@@ -0,0 +1,30 @@
+//go:build decimal
+// +build decimal
+
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDecimalCodec(t *testing.T) {
+	o := &marshalOptions{numberCodec: DecimalCodec{}}
+
+	d, _ := decimal.NewFromString("12345678901234567890.123456789")
+	v := o.createAttributeValue(d)
+	if v.N == nil || *v.N != "12345678901234567890.123456789" {
+		t.Errorf("error on createAttributeValue, actual=%+v", v)
+	}
+
+	actual, ok := o.getItemValue(v).(decimal.Decimal)
+	if !ok || !actual.Equal(d) {
+		t.Errorf("error on getItemValue, actual=%v", o.getItemValue(v))
+	}
+
+	i := o.createAttributeValue(99)
+	if *i.N != "99" {
+		t.Errorf("error on createAttributeValue, actual=%+v", i)
+	}
+}
@@ -0,0 +1,67 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+// codec to decode number into *big.Rat
+type testRatCodec struct{}
+
+func (testRatCodec) Encode(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case *big.Rat:
+		return t.FloatString(10), nil
+	case int, float64:
+		return fmt.Sprint(t), nil
+	}
+	return "", errors.New("unsupported type")
+}
+
+func (testRatCodec) Decode(s string) (interface{}, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errors.New("invalid number")
+	}
+	return r, nil
+}
+
+func TestSetNumberCodec(t *testing.T) {
+	d := &AmazonDynamoDB{marshalOptions: &marshalOptions{}}
+	d.SetNumberCodec(testRatCodec{})
+	o := d.options()
+
+	r, _ := new(big.Rat).SetString("1234567890.0123456789")
+	v := o.createAttributeValue(r)
+	if v.N == nil || *v.N != "1234567890.0123456789" {
+		t.Errorf("error on createAttributeValue, actual=%+v", v)
+	}
+	actual, ok := o.getItemValue(v).(*big.Rat)
+	if !ok || actual.Cmp(r) != 0 {
+		t.Errorf("error on getItemValue, actual=%v", o.getItemValue(v))
+	}
+
+	i := o.createAttributeValue(99)
+	if *i.N != "99" {
+		t.Errorf("error on createAttributeValue, actual=%+v", i)
+	}
+
+	// not a number
+	s := o.createAttributeValue("foo")
+	if s.S == nil || *s.S != "foo" {
+		t.Errorf("error on createAttributeValue, actual=%+v", s)
+	}
+
+	// the other client uses default conversion
+	if getItemValue(v) != 0 {
+		t.Errorf("error on getItemValue, actual=%v", getItemValue(v))
+	}
+
+	// restore default
+	d.SetNumberCodec(nil)
+	if o.getItemValue(v) != 0 {
+		t.Errorf("error on getItemValue, actual=%v", getItemValue(v))
+	}
+}
@@ -0,0 +1,294 @@
+// DynamoDB Query/Scan request builder
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// QueryRequest is the builder of Query operation on the table or the index, like
+// `table.NewQuery().Index("status-index").Hash("active").KeyCondition(func(c *FilterBuilder) { c.AddGT("created_at", 100) }).All()`.
+// the key condition and the filter share the placeholders, and the request is built on every call of Input
+type QueryRequest struct {
+	table *DynamoTable
+
+	index      string
+	hash       Any
+	hasHash    bool
+	keyConds   []func(*FilterBuilder)
+	filters    []func(*FilterBuilder)
+	projection []string
+
+	limit          int
+	pageSize       int64
+	desc           bool
+	consistentRead *bool
+}
+
+// NewQuery creates the builder of Query operation on the table
+func (t *DynamoTable) NewQuery() *QueryRequest {
+	return &QueryRequest{
+		table: t,
+	}
+}
+
+// Index sets the name of the local or global secondary index to query
+func (q *QueryRequest) Index(name string) *QueryRequest {
+	q.index = name
+	return q
+}
+
+// Hash adds EQUAL condition of the hash key of the table, or the hash key of the index when Index is set
+func (q *QueryRequest) Hash(value Any) *QueryRequest {
+	q.hash = value
+	q.hasHash = true
+	return q
+}
+
+// KeyCondition adds the key conditions (e.g. the range of the sort key) by the function
+func (q *QueryRequest) KeyCondition(fn func(cond *FilterBuilder)) *QueryRequest {
+	q.keyConds = append(q.keyConds, fn)
+	return q
+}
+
+// Filter adds the conditions of FilterExpression by the function, the filter is applied after the items are read
+func (q *QueryRequest) Filter(fn func(filter *FilterBuilder)) *QueryRequest {
+	q.filters = append(q.filters, fn)
+	return q
+}
+
+// Project sets the attributes to retrieve, the name accepts document path for the nested attribute
+func (q *QueryRequest) Project(attrs ...string) *QueryRequest {
+	q.projection = append(q.projection, attrs...)
+	return q
+}
+
+// Limit sets the max number of items of All after the filter, 0 means no limit
+func (q *QueryRequest) Limit(n int) *QueryRequest {
+	q.limit = n
+	return q
+}
+
+// PageSize sets the max number of items to read in the single page (Limit of QueryInput)
+func (q *QueryRequest) PageSize(n int64) *QueryRequest {
+	q.pageSize = n
+	return q
+}
+
+// Desc sorts the items in descending order of the sort key
+func (q *QueryRequest) Desc() *QueryRequest {
+	q.desc = true
+	return q
+}
+
+// ConsistentRead sets ConsistentRead of the request, which is not supported on GSI
+func (q *QueryRequest) ConsistentRead(b bool) *QueryRequest {
+	q.consistentRead = Boolean(b)
+	return q
+}
+
+// Input builds QueryInput of the request, returns error when the key condition is empty or invalid
+func (q *QueryRequest) Input() (*SDK.QueryInput, error) {
+	t := q.table
+	hashName := t.GetHashKeyName()
+	if q.index != "" {
+		index, err := t.getIndex(q.index)
+		if err != nil {
+			return nil, err
+		}
+		hashName = index.GetHashKeyName()
+	}
+
+	keyCond := q.table.NewFilterBuilder()
+	if q.hasHash {
+		keyCond.AddEQ(Literal(hashName), q.hash)
+	}
+	for _, fn := range q.keyConds {
+		fn(keyCond)
+	}
+	if len(keyCond.conditions) == 0 {
+		return nil, errors.New("[DynamoDB] the key condition is required for Query, table=" + t.name)
+	}
+	filter := NewSharedFilterBuilder(keyCond)
+	for _, fn := range q.filters {
+		fn(filter)
+	}
+	if filter.Error() != nil {
+		return nil, filter.Error()
+	}
+	projection := projectionExpression(keyCond.attrs, q.projection)
+
+	in, err := newExpressionQueryInput(t.name, keyCond, filter)
+	if err != nil {
+		return nil, err
+	}
+	in.ProjectionExpression = projection
+	in.ConsistentRead = q.consistentRead
+	if q.pageSize > 0 {
+		in.Limit = Long(q.pageSize)
+	}
+	if q.desc {
+		in.ScanIndexForward = Boolean(false)
+	}
+	if q.index == "" {
+		return in, nil
+	}
+	return t.newIndexQueryInput(q.index, in)
+}
+
+// All gets mapped-items of the request by following LastEvaluatedKey until the last page or the limit
+func (q *QueryRequest) All() ([]map[string]interface{}, error) {
+	in, err := q.Input()
+	if err != nil {
+		return nil, err
+	}
+	return q.table.queryPages(in, q.limit)
+}
+
+// Page gets mapped-items of the single page from the cursor (empty cursor for the first page),
+// and returns the cursor of the next page. see QueryPage
+func (q *QueryRequest) Page(cursor string) ([]map[string]interface{}, string, error) {
+	in, err := q.Input()
+	if err != nil {
+		return nil, "", err
+	}
+	return q.table.QueryPage(in, cursor)
+}
+
+// Chan sends mapped-items of the request to the item channel with paging in background. see QueryChan
+func (q *QueryRequest) Chan(ctx context.Context) (<-chan map[string]interface{}, <-chan error) {
+	in, err := q.Input()
+	if err != nil {
+		items := make(chan map[string]interface{})
+		errs := make(chan error, 1)
+		errs <- err
+		close(items)
+		close(errs)
+		return items, errs
+	}
+	return q.table.QueryChan(ctx, in)
+}
+
+// ScanRequest is the builder of Scan operation on the table with FilterExpression and the projection,
+// (this SDK does not support Scan on the index)
+type ScanRequest struct {
+	table *DynamoTable
+
+	filters    []func(*FilterBuilder)
+	projection []string
+
+	limit    int
+	pageSize int64
+}
+
+// NewScan creates the builder of Scan operation on the table
+func (t *DynamoTable) NewScan() *ScanRequest {
+	return &ScanRequest{
+		table: t,
+	}
+}
+
+// Filter adds the conditions of FilterExpression by the function, the filter is applied after the items are read
+func (s *ScanRequest) Filter(fn func(filter *FilterBuilder)) *ScanRequest {
+	s.filters = append(s.filters, fn)
+	return s
+}
+
+// Project sets the attributes to retrieve, the name accepts document path for the nested attribute
+func (s *ScanRequest) Project(attrs ...string) *ScanRequest {
+	s.projection = append(s.projection, attrs...)
+	return s
+}
+
+// Limit sets the max number of items of All after the filter, 0 means no limit
+func (s *ScanRequest) Limit(n int) *ScanRequest {
+	s.limit = n
+	return s
+}
+
+// PageSize sets the max number of items to read in the single page (Limit of ScanInput)
+func (s *ScanRequest) PageSize(n int64) *ScanRequest {
+	s.pageSize = n
+	return s
+}
+
+// Input builds ScanInput of the request, returns error when the filter is invalid
+func (s *ScanRequest) Input() (*SDK.ScanInput, error) {
+	t := s.table
+	filter := s.table.NewFilterBuilder()
+	for _, fn := range s.filters {
+		fn(filter)
+	}
+	if filter.Error() != nil {
+		return nil, filter.Error()
+	}
+
+	in := &SDK.ScanInput{
+		TableName:              String(t.name),
+		ProjectionExpression:   projectionExpression(filter.attrs, s.projection),
+		ReturnConsumedCapacity: t.returnCapacity(nil),
+	}
+	if len(filter.conditions) != 0 {
+		in.FilterExpression = String(filter.Expression())
+	}
+	in.ExpressionAttributeNames = filter.attrs.expressionNames()
+	in.ExpressionAttributeValues = filter.attrs.expressionValues()
+	if s.pageSize > 0 {
+		in.Limit = Long(s.pageSize)
+	}
+	return in, nil
+}
+
+// All gets mapped-items of the request by following LastEvaluatedKey until the last page or the limit
+func (s *ScanRequest) All() ([]map[string]interface{}, error) {
+	t := s.table
+	if err := t.guardScan("ScanRequest"); err != nil {
+		return nil, err
+	}
+	in, err := s.Input()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]interface{}
+	err = pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		req, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			t.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		items = append(items, t.ConvertItemsToMapArray(req.Items)...)
+		if s.limit > 0 && len(items) >= s.limit {
+			items = items[:s.limit]
+			return nil, true, nil
+		}
+		next, done := nextPageKey(req.LastEvaluatedKey)
+		return next, done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// get ProjectionExpression of the attributes with the placeholders, returns nil for no attribute
+func projectionExpression(attrs *expressionAttributes, names []string) *string {
+	if len(names) == 0 {
+		return nil
+	}
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = attrs.path(name)
+	}
+	return String(strings.Join(paths, ", "))
+}
@@ -0,0 +1,163 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func getTestQueryRequestTable() *DynamoTable {
+	tbl := getTestCacheTable()
+	tbl.indexes = map[string]*DynamoIndex{
+		"status-index": NewDynamoIndex("status-index", indexTypeGSI, NewKeySchema(NewHashKeyElement("status"), NewRangeKeyElement("created_at"))),
+	}
+	return tbl
+}
+
+func TestQueryRequestInput(t *testing.T) {
+	tbl := getTestQueryRequestTable()
+
+	q := tbl.NewQuery().Hash(100).KeyCondition(func(c *FilterBuilder) {
+		c.AddGT("time", 5)
+	}).Filter(func(f *FilterBuilder) {
+		f.AddExists("email")
+	}).Project("name", "profile.email").PageSize(10).Desc()
+	in, err := q.Input()
+	if err != nil {
+		t.Errorf("error on QueryRequest, %s", err.Error())
+	}
+	switch {
+	case *in.TableName != "foo_table", in.IndexName != nil:
+		t.Errorf("error on QueryRequest, %v", in)
+	case *in.KeyConditionExpression != "#n0 = :v0 AND #n1 > :v1":
+		t.Errorf("error on QueryRequest, %s", *in.KeyConditionExpression)
+	case *in.FilterExpression != "attribute_exists(#n2)":
+		t.Errorf("error on QueryRequest, %s", *in.FilterExpression)
+	case *in.ProjectionExpression != "#n3, #n4.#n2":
+		t.Errorf("error on QueryRequest, %s", *in.ProjectionExpression)
+	case *in.Limit != 10, *in.ScanIndexForward:
+		t.Errorf("error on QueryRequest, %v", in)
+	case *(*in.ExpressionAttributeNames)["#n0"] != "id", *(*in.ExpressionAttributeValues)[":v1"].N != "5":
+		t.Errorf("error on QueryRequest, %v", in)
+	}
+
+	// the input is built on every call
+	in2, _ := q.Input()
+	if *in2.KeyConditionExpression != *in.KeyConditionExpression {
+		t.Errorf("error on QueryRequest, %s", *in2.KeyConditionExpression)
+	}
+
+	// the hash key of the index
+	in, err = tbl.NewQuery().Index("status-index").Hash("active").Input()
+	if err != nil {
+		t.Errorf("error on QueryRequest, %s", err.Error())
+	}
+	if *in.IndexName != "status-index" || *(*in.ExpressionAttributeNames)["#n0"] != "status" || in.FilterExpression != nil {
+		t.Errorf("error on QueryRequest, %v", in)
+	}
+
+	invalid := []*QueryRequest{
+		tbl.NewQuery(),
+		tbl.NewQuery().Index("not-exist").Hash(1),
+		tbl.NewQuery().Index("status-index").Hash("active").ConsistentRead(true),
+		tbl.NewQuery().Hash(1).Filter(func(f *FilterBuilder) {
+			f.AddSize("tags", "CONTAINS", 1)
+		}),
+	}
+	for i, q := range invalid {
+		if _, err := q.Input(); err == nil {
+			t.Errorf("error on QueryRequest, invalid request is accepted, #%d", i)
+		}
+	}
+
+	items, errs := tbl.NewQuery().Chan(context.Background())
+	if _, ok := <-items; ok {
+		t.Errorf("error on QueryRequest, the item channel must be closed")
+	}
+	if err := <-errs; err == nil {
+		t.Errorf("error on QueryRequest, error must be sent")
+	}
+}
+
+func TestQueryRequestInputDottedKey(t *testing.T) {
+	tbl := &DynamoTable{
+		name: "foo_table",
+		table: &SDK.TableDescription{
+			KeySchema: NewKeySchema(NewHashKeyElement("user.id"), NewRangeKeyElement("created.at")),
+		},
+	}
+
+	// the key names are used as the single attribute name, not the document path
+	in, err := tbl.NewQuery().Hash("u1").KeyCondition(func(c *FilterBuilder) {
+		c.AddGT(Literal("created.at"), 5)
+	}).Input()
+	if err != nil {
+		t.Errorf("error on QueryRequest with dotted key, %s", err.Error())
+	}
+	switch {
+	case *in.KeyConditionExpression != "#n0 = :v0 AND #n1 > :v1":
+		t.Errorf("error on QueryRequest with dotted key, %s", *in.KeyConditionExpression)
+	case *(*in.ExpressionAttributeNames)["#n0"] != "user.id", *(*in.ExpressionAttributeNames)["#n1"] != "created.at":
+		t.Errorf("error on QueryRequest with dotted key, %v", *in.ExpressionAttributeNames)
+	case len(*in.ExpressionAttributeNames) != 2:
+		t.Errorf("error on QueryRequest with dotted key, %v", *in.ExpressionAttributeNames)
+	}
+}
+
+func TestScanRequestInput(t *testing.T) {
+	tbl := getTestQueryRequestTable()
+
+	in, err := tbl.NewScan().Filter(func(f *FilterBuilder) {
+		f.AddEQ("status", "active")
+	}).Project("id", "status").PageSize(100).Input()
+	if err != nil {
+		t.Errorf("error on ScanRequest, %s", err.Error())
+	}
+	switch {
+	case *in.FilterExpression != "#n0 = :v0", *in.ProjectionExpression != "#n1, #n0", *in.Limit != 100:
+		t.Errorf("error on ScanRequest, %v", in)
+	}
+
+	in, err = tbl.NewScan().Input()
+	if err != nil {
+		t.Errorf("error on ScanRequest, %s", err.Error())
+	}
+	if in.FilterExpression != nil || in.ProjectionExpression != nil || in.ExpressionAttributeNames != nil || in.ExpressionAttributeValues != nil || in.Limit != nil {
+		t.Errorf("error on ScanRequest, %v", in)
+	}
+}
+
+func TestQueryRequestAll(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 10; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	// follow the pages until the limit
+	items, err := tbl.NewQuery().Hash(100).KeyCondition(func(c *FilterBuilder) {
+		c.AddGE("time", 3)
+	}).PageSize(2).Limit(4).All()
+	if err != nil {
+		t.Errorf("error on QueryRequest, %s", err.Error())
+	}
+	if len(items) != 4 || items[0]["time"] != 3 || items[3]["time"] != 6 {
+		t.Errorf("error on QueryRequest, %v", items)
+	}
+
+	items, _ = tbl.NewQuery().Hash(100).Desc().Project("time").PageSize(3).All()
+	if len(items) != 10 || items[0]["time"] != 10 || items[0]["lsi_key"] != nil {
+		t.Errorf("error on QueryRequest, %v", items)
+	}
+
+	items, err = tbl.NewScan().Filter(func(f *FilterBuilder) {
+		f.AddLE("time", 5)
+	}).PageSize(3).All()
+	if err != nil {
+		t.Errorf("error on ScanRequest, %s", err.Error())
+	}
+	if len(items) != 5 {
+		t.Errorf("error on ScanRequest, %v", items)
+	}
+}
@@ -0,0 +1,92 @@
+// DynamoDB approximate sampling of the table
+
+package dynamodb
+
+import (
+	"math/rand"
+	"sync"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// number of segments for parallel scan on Sample
+const sampleSegments = 4
+
+// Sample returns the random sample of the items by parallel scan, each item is kept with the probability of fraction,
+// and the scan stops when maxItems items are sampled (maxItems=0 means no limit).
+// the sample is approximate since DynamoDB has no native sampling; all of the scanned items consume the read capacity,
+// and the distribution may be skewed slightly by the segment boundaries when the scan stops at maxItems
+func (t *DynamoTable) Sample(fraction float64, maxItems int) ([]map[string]interface{}, error) {
+	if fraction <= 0 {
+		return nil, nil
+	}
+
+	var mu sync.Mutex
+	var items []map[string]interface{}
+	// add the sampled items and returns true when the sample is full
+	add := func(page []*map[string]*SDK.AttributeValue) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if maxItems > 0 && len(items) >= maxItems {
+			return true
+		}
+		items = append(items, t.ConvertItemsToMapArray(page)...)
+		return maxItems > 0 && len(items) >= maxItems
+	}
+
+	errs := make([]error, sampleSegments)
+	t.parallel(sampleSegments, func(segment int) {
+		errs[segment] = t.sampleSegment(segment, sampleSegments, fraction, add)
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	if maxItems > 0 && len(items) > maxItems {
+		items = items[:maxItems]
+	}
+	return items, nil
+}
+
+// scan the items in the segment and pass the sampled items to add until it returns true
+func (t *DynamoTable) sampleSegment(segment, total int, fraction float64, add func([]*map[string]*SDK.AttributeValue) bool) error {
+	in := &SDK.ScanInput{
+		TableName:     String(t.name),
+		Segment:       Long(int64(segment)),
+		TotalSegments: Long(int64(total)),
+	}
+	return pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		res, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			t.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		if add(sampleItems(res.Items, fraction, rand.Float64)) {
+			return nil, true, nil
+		}
+		next, done := nextPageKey(res.LastEvaluatedKey)
+		return next, done, nil
+	})
+}
+
+// keep each item when random() is less than fraction
+func sampleItems(items []*map[string]*SDK.AttributeValue, fraction float64, random func() float64) []*map[string]*SDK.AttributeValue {
+	if fraction >= 1 {
+		return items
+	}
+	var results []*map[string]*SDK.AttributeValue
+	for _, item := range items {
+		if random() < fraction {
+			results = append(results, item)
+		}
+	}
+	return results
+}
@@ -0,0 +1,59 @@
+package dynamodb
+
+import (
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func TestSample(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 20; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	items, err := tbl.Sample(1, 0)
+	if err != nil {
+		t.Errorf("error on Sample, %s", err.Error())
+	}
+	if len(items) != 20 {
+		t.Errorf("error on Sample, %d", len(items))
+	}
+
+	items, err = tbl.Sample(1, 5)
+	if err != nil {
+		t.Errorf("error on Sample, %s", err.Error())
+	}
+	if len(items) != 5 {
+		t.Errorf("error on Sample with maxItems, %d", len(items))
+	}
+
+	items, err = tbl.Sample(0, 0)
+	if err != nil || len(items) != 0 {
+		t.Errorf("error on Sample with zero fraction, %d", len(items))
+	}
+}
+
+func TestSampleItems(t *testing.T) {
+	var items []*map[string]*SDK.AttributeValue
+	for i := 0; i < 4; i++ {
+		items = append(items, Marshal(map[string]interface{}{"id": i}))
+	}
+
+	values := []float64{0.1, 0.6, 0.4, 0.9}
+	var i int
+	random := func() float64 {
+		v := values[i]
+		i++
+		return v
+	}
+	results := sampleItems(items, 0.5, random)
+	if len(results) != 2 || results[0] != items[0] || results[1] != items[2] {
+		t.Errorf("error on sampleItems, %v", results)
+	}
+
+	if results := sampleItems(items, 1, nil); len(results) != 4 {
+		t.Errorf("error on sampleItems with fraction=1, %v", results)
+	}
+}
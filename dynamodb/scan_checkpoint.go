@@ -0,0 +1,181 @@
+// DynamoDB resumable parallel scan with the checkpoint
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// ScanCheckpoint is the resumable position of every segment of ScanResumable,
+// it can be serialized by encoding/json to save it between the restarts of the job
+type ScanCheckpoint struct {
+	Segments []ScanSegmentCheckpoint `json:"segments"`
+}
+
+// ScanSegmentCheckpoint is the resumable position of the segment
+type ScanSegmentCheckpoint struct {
+	Segment int `json:"segment"`
+
+	// LastKey is the last evaluated key of the handled page as the cursor, empty when the segment is not started
+	LastKey string `json:"last_key,omitempty"`
+
+	// Done is true when all of the items in the segment are handled
+	Done bool `json:"done,omitempty"`
+}
+
+// NewScanCheckpoint creates the checkpoint to start the scan with the segments from the beginning
+func NewScanCheckpoint(segments int) *ScanCheckpoint {
+	if segments <= 0 {
+		segments = 1
+	}
+	cp := &ScanCheckpoint{
+		Segments: make([]ScanSegmentCheckpoint, segments),
+	}
+	for i := range cp.Segments {
+		cp.Segments[i].Segment = i
+	}
+	return cp
+}
+
+// Done returns true when all of the segments are done
+func (cp *ScanCheckpoint) Done() bool {
+	for _, s := range cp.Segments {
+		if !s.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// copy the checkpoint to return it
+func (cp *ScanCheckpoint) clone() *ScanCheckpoint {
+	segments := make([]ScanSegmentCheckpoint, len(cp.Segments))
+	copy(segments, cp.Segments)
+	return &ScanCheckpoint{Segments: segments}
+}
+
+// ScanResumable scans the table by parallel scan from the checkpoint (nil starts the single segment scan),
+// and returns the checkpoint after the scan. see ScanResumableContext
+func (t *DynamoTable) ScanResumable(checkpoint *ScanCheckpoint, handler func([]map[string]interface{}) error) (*ScanCheckpoint, error) {
+	return t.ScanResumableContext(context.Background(), checkpoint, handler)
+}
+
+// ScanResumableContext scans the table by parallel scan from the checkpoint and passes the mapped-items of every page to the handler,
+// the calls of the handler are serialized between the segments. the scan stops when the context is done or the handler returns the error,
+// and the checkpoint of the pages already handled is returned with the error to resume the scan later
+func (t *DynamoTable) ScanResumableContext(ctx context.Context, checkpoint *ScanCheckpoint, handler func([]map[string]interface{}) error) (*ScanCheckpoint, error) {
+	return t.scanResumable(ctx, checkpoint, "ScanResumable", handler, nil, false)
+}
+
+// scan the table from the checkpoint, afterPage is called with the updated checkpoint after every handled page (optional)
+func (t *DynamoTable) scanResumable(ctx context.Context, checkpoint *ScanCheckpoint, op string, handler func([]map[string]interface{}) error, afterPage func(*ScanCheckpoint), parallelHandler bool) (*ScanCheckpoint, error) {
+	if checkpoint == nil {
+		checkpoint = NewScanCheckpoint(1)
+	}
+	for i, s := range checkpoint.Segments {
+		if s.Segment != i {
+			return checkpoint, errors.New("[DynamoDB] invalid segment order of the checkpoint, table=" + t.name)
+		}
+	}
+	if err := t.guardScan(op); err != nil {
+		return checkpoint, err
+	}
+
+	cp := checkpoint.clone()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	total := len(cp.Segments)
+	errs := make([]error, total)
+	t.parallel(total, func(segment int) {
+		errs[segment] = t.scanSegmentFrom(ctx, cp, segment, total, &mu, handler, afterPage, parallelHandler)
+		if errs[segment] != nil {
+			cancel()
+		}
+	})
+
+	for _, err := range errs {
+		if err != nil && err != context.Canceled {
+			return cp, err
+		}
+	}
+	if err := ctx.Err(); err != nil && !cp.Done() {
+		return cp, err
+	}
+	return cp, nil
+}
+
+// scan the segment from the checkpoint, and update the checkpoint after the handler succeeds,
+// the handler is called outside the lock when parallelHandler is true (the handler must be safe for concurrent use)
+func (t *DynamoTable) scanSegmentFrom(ctx context.Context, cp *ScanCheckpoint, segment, total int, mu *sync.Mutex, handler func([]map[string]interface{}) error, afterPage func(*ScanCheckpoint), parallelHandler bool) error {
+	mu.Lock()
+	last := cp.Segments[segment]
+	mu.Unlock()
+	if last.Done {
+		return nil
+	}
+	lastKey, err := decodeCursorKey(last.LastKey)
+	if err != nil {
+		return err
+	}
+
+	in := &SDK.ScanInput{
+		TableName:              String(t.name),
+		Segment:                Long(int64(segment)),
+		TotalSegments:          Long(int64(total)),
+		ReturnConsumedCapacity: t.returnCapacity(nil),
+	}
+	return pager.PaginateContext(ctx, func(token interface{}) (interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		if token == nil {
+			in.ExclusiveStartKey = lastKey
+		}
+		res, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			t.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		next, done := nextPageKey(res.LastEvaluatedKey)
+		cursor, err := encodeCursorKey(res.LastEvaluatedKey)
+		if err != nil {
+			return nil, false, err
+		}
+
+		items := t.ConvertItemsToMapArray(res.Items)
+		if parallelHandler {
+			if err := ctx.Err(); err != nil {
+				return nil, false, err
+			}
+			if err := handler(items); err != nil {
+				return nil, false, err
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		if !parallelHandler {
+			if err := handler(items); err != nil {
+				return nil, false, err
+			}
+		}
+		cp.Segments[segment].LastKey = cursor
+		cp.Segments[segment].Done = done
+		if afterPage != nil {
+			afterPage(cp)
+		}
+		return next, done, nil
+	})
+}
@@ -0,0 +1,94 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestScanResumable(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 20; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	// stop on the handler error and resume from the checkpoint
+	errStop := errors.New("stop")
+	var count, calls int
+	cp, err := tbl.ScanResumable(NewScanCheckpoint(2), func(items []map[string]interface{}) error {
+		calls++
+		if calls > 1 {
+			return errStop
+		}
+		count += len(items)
+		return nil
+	})
+	if err != errStop || cp == nil || len(cp.Segments) != 2 {
+		t.Errorf("error on ScanResumable, %v, %v", cp, err)
+	}
+
+	b, err := json.Marshal(cp)
+	if err != nil {
+		t.Errorf("error on ScanResumable, %s", err.Error())
+	}
+	var saved ScanCheckpoint
+	json.Unmarshal(b, &saved)
+	cp, err = tbl.ScanResumable(&saved, func(items []map[string]interface{}) error {
+		count += len(items)
+		return nil
+	})
+	if err != nil || !cp.Done() {
+		t.Errorf("error on ScanResumable, %v, %v", cp, err)
+	}
+	if count != 20 {
+		t.Errorf("error on ScanResumable, %d", count)
+	}
+
+	// the done checkpoint does not scan
+	_, err = tbl.ScanResumable(cp, func(items []map[string]interface{}) error {
+		return errStop
+	})
+	if err != nil {
+		t.Errorf("error on ScanResumable, %s", err.Error())
+	}
+}
+
+func TestScanResumableContext(t *testing.T) {
+	tbl := getTestCacheTable()
+	tbl.db = &AmazonDynamoDB{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cp, err := tbl.ScanResumableContext(ctx, nil, func(items []map[string]interface{}) error {
+		return nil
+	})
+	if err != context.Canceled {
+		t.Errorf("error on ScanResumableContext, %v", err)
+	}
+	if cp == nil || len(cp.Segments) != 1 || cp.Done() {
+		t.Errorf("error on ScanResumableContext, the checkpoint must be returned, %v", cp)
+	}
+
+	invalid := &ScanCheckpoint{Segments: []ScanSegmentCheckpoint{{Segment: 1}}}
+	if _, err := tbl.ScanResumable(invalid, nil); err == nil {
+		t.Errorf("error on ScanResumable, invalid checkpoint is accepted")
+	}
+}
+
+func TestNewScanCheckpoint(t *testing.T) {
+	cp := NewScanCheckpoint(3)
+	if len(cp.Segments) != 3 || cp.Segments[2].Segment != 2 || cp.Done() {
+		t.Errorf("error on NewScanCheckpoint, %v", cp)
+	}
+	for i := range cp.Segments {
+		cp.Segments[i].Done = true
+	}
+	if !cp.Done() {
+		t.Errorf("error on ScanCheckpoint.Done, %v", cp)
+	}
+	if cp := NewScanCheckpoint(0); len(cp.Segments) != 1 {
+		t.Errorf("error on NewScanCheckpoint, %v", cp)
+	}
+}
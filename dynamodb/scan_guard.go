@@ -0,0 +1,52 @@
+// DynamoDB guard for the full table scan
+
+package dynamodb
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// ScanGuardMode is the behavior of Scan on the table which can be queried by the secondary indexes
+type ScanGuardMode int
+
+const (
+	// ScanGuardOff does nothing (default)
+	ScanGuardOff ScanGuardMode = iota
+	// ScanGuardWarn logs the warning and performs Scan
+	ScanGuardWarn
+	// ScanGuardStrict returns ErrScanWithIndex without Scan
+	ScanGuardStrict
+)
+
+// ErrScanWithIndex is returned by Scan on ScanGuardStrict when the table has the secondary indexes
+var ErrScanWithIndex = errors.New("[DynamoDB] Scan on the table with the secondary indexes, use Query instead")
+
+// SetScanGuard sets the mode to surface Scan and ScanAll on the table with the secondary indexes,
+// which may be served by Query on the index. the intentional full scans (export, copy, DeleteAll, etc) are not guarded
+func (d *AmazonDynamoDB) SetScanGuard(mode ScanGuardMode) {
+	d.scanGuard = mode
+}
+
+// check the scan by the guard mode, returns error on the strict mode when the table has the secondary indexes
+func (t *DynamoTable) guardScan(op string) error {
+	if t.db.scanGuard == ScanGuardOff || len(t.indexes) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(t.indexes))
+	for name := range t.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msg := "[DynamoDB] `" + op + "` on the table with the indexes, consider Query instead, table=" + t.name
+	if t.db.scanGuard == ScanGuardStrict {
+		log.Error(msg, strings.Join(names, ","))
+		return ErrScanWithIndex
+	}
+	log.Warn(msg, strings.Join(names, ","))
+	return nil
+}
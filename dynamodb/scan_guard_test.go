@@ -0,0 +1,42 @@
+package dynamodb
+
+import (
+	"testing"
+)
+
+func TestGuardScan(t *testing.T) {
+	tbl := &DynamoTable{
+		db:      &AmazonDynamoDB{},
+		name:    "foo_table",
+		indexes: map[string]*DynamoIndex{},
+	}
+	if err := tbl.guardScan("Scan"); err != nil {
+		t.Errorf("error on guardScan, %s", err.Error())
+	}
+
+	tbl.db.SetScanGuard(ScanGuardStrict)
+	if err := tbl.guardScan("Scan"); err != nil {
+		t.Errorf("error on guardScan, table without index is guarded, %s", err.Error())
+	}
+
+	tbl.indexes["gsi-name"] = NewDynamoIndex("gsi-name", indexTypeGSI, nil)
+	if err := tbl.guardScan("Scan"); err != ErrScanWithIndex {
+		t.Errorf("error on guardScan, strict mode does not return error, %v", err)
+	}
+	if _, err := tbl.Scan(); err != ErrScanWithIndex {
+		t.Errorf("error on Scan, strict mode does not return error, %v", err)
+	}
+	if _, err := tbl.ScanAll(0); err != ErrScanWithIndex {
+		t.Errorf("error on ScanAll, strict mode does not return error, %v", err)
+	}
+
+	tbl.db.SetScanGuard(ScanGuardWarn)
+	if err := tbl.guardScan("Scan"); err != nil {
+		t.Errorf("error on guardScan, warn mode returns error, %s", err.Error())
+	}
+
+	tbl.db.SetScanGuard(ScanGuardOff)
+	if err := tbl.guardScan("Scan"); err != nil {
+		t.Errorf("error on guardScan, %s", err.Error())
+	}
+}
@@ -0,0 +1,552 @@
+// DynamoDB table definition from struct
+
+package dynamodb
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	structTagName      = "dynamodb"
+	structTagAlias     = "dynamo"
+	tagOptionHash      = "hash"
+	tagOptionRange     = "range"
+	tagOptionOmitEmpty = "omitempty"
+	tagOptionDefault   = "default="
+	tagOptionFormat    = "format="
+	tagOptionEnum      = "enum="
+
+	// the format option to store time.Time as Date
+	tagFormatDate = "date"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	dateType = reflect.TypeOf(Date{})
+)
+
+// NameMapper converts the field name of the struct to the attribute name
+type NameMapper func(fieldName string) string
+
+// mapper of the attribute name for the field without the name in the tag, nil means the field name as it is
+var nameMapper NameMapper
+
+// SetNameMapper sets the mapper of the attribute name for the struct field without the name in the tag
+// (e.g. SnakeCaseMapper), the name in the tag always wins over the mapper. nil restores the field name as it is
+func SetNameMapper(fn NameMapper) {
+	nameMapper = fn
+}
+
+// SnakeCaseMapper converts the field name to snake_case, like "UserID" to "user_id" and "HTTPStatus" to "http_status"
+func SnakeCaseMapper(fieldName string) string {
+	runes := []rune(fieldName)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					b.WriteByte('_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// structField is the attribute information from the struct field tag
+type structField struct {
+	name     string
+	attrType string
+	keyType  string
+
+	// omitempty skips the zero value, and the default value (non-nil) is used for the zero value instead
+	omitEmpty    bool
+	defaultValue interface{}
+
+	// dateFormat stores time.Time as Date by `format=date`
+	dateFormat bool
+
+	// enum is the allowed values of the string field by `enum=a|b|c`, nil means any value
+	enum map[string]bool
+
+	// index sequence of the field in the struct, the fields of the embedded struct have the index of the embedded field first
+	index []int
+}
+
+// TableFromStruct creates CreateTableInput from the struct tags,
+// the key schema and the attribute definitions are derived from `dynamodb:"pk,hash"` and `dynamodb:"sk,range"`.
+// non-key fields are not used for the schema, but must not conflict with the type of the key attributes.
+// (ProvisionedThroughput and indexes are not set)
+func TableFromStruct(v interface{}, tableName string) (*SDK.CreateTableInput, error) {
+	fields, err := parseStructFields(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var hash, rng *structField
+	types := make(map[string]string)
+	for _, f := range fields {
+		if typ, ok := types[f.name]; ok && f.attrType != "" && typ != "" && typ != f.attrType {
+			return nil, errors.New("[DynamoDB] conflicted attribute type on struct, name=" + f.name + ", type=" + typ + "," + f.attrType)
+		}
+		if f.attrType != "" {
+			types[f.name] = f.attrType
+		}
+
+		switch f.keyType {
+		case KeyTypeHash:
+			if hash != nil {
+				return nil, errors.New("[DynamoDB] multiple hash keys on struct, name=" + hash.name + "," + f.name)
+			}
+			hash = f
+		case KeyTypeRange:
+			if rng != nil {
+				return nil, errors.New("[DynamoDB] multiple range keys on struct, name=" + rng.name + "," + f.name)
+			}
+			rng = f
+		}
+	}
+	if hash == nil {
+		return nil, errors.New("[DynamoDB] hash key is not found on struct, table=" + tableName)
+	}
+
+	keys := []*structField{hash}
+	if rng != nil {
+		keys = append(keys, rng)
+	}
+	var schema []*SDK.KeySchemaElement
+	var attrs []*SDK.AttributeDefinition
+	for _, k := range keys {
+		switch k.attrType {
+		case "S", "N", "B":
+		default:
+			return nil, errors.New("[DynamoDB] unsupported type for key attribute on struct, name=" + k.name)
+		}
+		schema = append(schema, NewKeyElement(k.name, k.keyType))
+		attrs = append(attrs, NewAttributeDefinition(k.name, k.attrType))
+	}
+
+	return &SDK.CreateTableInput{
+		TableName:            String(tableName),
+		KeySchema:            NewKeySchema(schema...),
+		AttributeDefinitions: NewAttributeDefinitions(attrs...),
+	}, nil
+}
+
+// parse the fields of the struct by the tags,
+// the field without tag uses the field name (converted by the NameMapper when it's set) and the field with `dynamodb:"-"` is skipped.
+// `dynamo` tag is used as the alias when the field does not have `dynamodb` tag.
+// the options are hash, range, omitempty, default (e.g. `dynamodb:"status,default=active"`, the value cannot contain comma)
+// format (`dynamodb:"dob,format=date"` stores time.Time as Date)
+// and enum (`dynamodb:"role,enum=admin|user|guest"` rejects the other values of the string field on marshal).
+// the fields of the embedded struct without the name in the tag are flattened like encoding/json,
+// and the field of the outer struct wins over the embedded field of the same name
+func parseStructFields(v interface{}) ([]*structField, error) {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New("[DynamoDB] the value must be struct")
+	}
+	return structFieldsOf(typ)
+}
+
+// parse the fields of the struct type
+func structFieldsOf(typ reflect.Type) ([]*structField, error) {
+	return parseStructType(typ, nil, map[reflect.Type]bool{typ: true})
+}
+
+// parse the fields of the struct type, parent is the index sequence of the embedded struct
+// and visited holds the embedded struct types to stop the recursive embedding
+func parseStructType(typ reflect.Type, parent []int, visited map[reflect.Type]bool) ([]*structField, error) {
+	var fields, embedded []*structField
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, ok := sf.Tag.Lookup(structTagName)
+		if !ok {
+			tag = sf.Tag.Get(structTagAlias)
+		}
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		index := append(append([]int{}, parent...), i)
+
+		if et := indirectType(sf.Type); sf.Anonymous && opts[0] == "" && isEmbeddedStruct(sf, et) {
+			if visited[et] {
+				continue
+			}
+			visited[et] = true
+			sub, err := parseStructType(et, index, visited)
+			delete(visited, et)
+			if err != nil {
+				return nil, err
+			}
+			embedded = append(embedded, sub...)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		f := &structField{
+			name:     sf.Name,
+			attrType: structFieldType(sf.Type),
+			index:    index,
+		}
+		switch {
+		case opts[0] != "":
+			f.name = opts[0]
+		case nameMapper != nil:
+			f.name = nameMapper(sf.Name)
+		}
+		for _, opt := range opts[1:] {
+			opt = strings.TrimSpace(opt)
+			switch {
+			case opt == tagOptionHash:
+				f.keyType = KeyTypeHash
+			case opt == tagOptionRange:
+				f.keyType = KeyTypeRange
+			case opt == tagOptionOmitEmpty:
+				f.omitEmpty = true
+			case strings.HasPrefix(opt, tagOptionDefault):
+				dv, err := parseDefaultValue(sf.Type, strings.TrimPrefix(opt, tagOptionDefault))
+				if err != nil {
+					return nil, errors.New("[DynamoDB] invalid default value on struct, name=" + f.name + ", " + err.Error())
+				}
+				f.defaultValue = dv
+			case strings.HasPrefix(opt, tagOptionFormat):
+				format := strings.TrimPrefix(opt, tagOptionFormat)
+				if format != tagFormatDate || indirectType(sf.Type) != timeType {
+					return nil, errors.New("[DynamoDB] invalid format on struct, name=" + f.name + ", format=" + format + ", type=" + sf.Type.String())
+				}
+				f.dateFormat = true
+				f.attrType = "S"
+			case strings.HasPrefix(opt, tagOptionEnum):
+				if indirectType(sf.Type).Kind() != reflect.String {
+					return nil, errors.New("[DynamoDB] enum requires string field on struct, name=" + f.name + ", type=" + sf.Type.String())
+				}
+				f.enum = make(map[string]bool)
+				for _, ev := range strings.Split(strings.TrimPrefix(opt, tagOptionEnum), "|") {
+					f.enum[ev] = true
+				}
+			}
+		}
+		if f.defaultValue != nil {
+			if err := f.validateEnum(reflect.ValueOf(f.defaultValue)); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, f)
+	}
+
+	names := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		names[f.name] = true
+	}
+	for _, f := range embedded {
+		if !names[f.name] {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// check if the string value of the field is one of the enum values, nil pointer is not checked
+func (f *structField) validateEnum(fv reflect.Value) error {
+	if f.enum == nil {
+		return nil
+	}
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	if !f.enum[fv.String()] {
+		return errors.New("[DynamoDB] the value is not in enum on struct, name=" + f.name + ", value=" + fv.String())
+	}
+	return nil
+}
+
+// check if the fields of the embedded field are flattened,
+// the pointer to the unexported struct is skipped because it cannot be allocated on unmarshal
+func isEmbeddedStruct(sf reflect.StructField, typ reflect.Type) bool {
+	switch {
+	case typ.Kind() != reflect.Struct, typ == dateType, typ == timeType:
+		return false
+	case sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr:
+		return false
+	}
+	return true
+}
+
+// get the field of the index sequence, returns false when the embedded pointer is nil
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					return reflect.Value{}, false
+				}
+				rv = rv.Elem()
+			}
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// get the field of the index sequence, the nil embedded pointer is allocated
+func allocFieldByIndex(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 {
+			for rv.Kind() == reflect.Ptr {
+				if rv.IsNil() {
+					rv.Set(reflect.New(rv.Type().Elem()))
+				}
+				rv = rv.Elem()
+			}
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+// parse the default value of the tag to the type of the struct field (the element type for the pointer),
+// only string, number and bool are supported
+func parseDefaultValue(typ reflect.Type, s string) (interface{}, error) {
+	typ = indirectType(typ)
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return nil, err
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		v.SetBool(b)
+	default:
+		return nil, errors.New("unsupported type for default, type=" + typ.String())
+	}
+	return v.Interface(), nil
+}
+
+// get the attribute type from the type of the struct field, returns empty string for the other types
+func structFieldType(typ reflect.Type) string {
+	typ = indirectType(typ)
+	if typ == dateType {
+		return "S"
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "S"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "N"
+	case reflect.Bool:
+		return "BOOL"
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "B"
+		}
+		return "L"
+	case reflect.Map, reflect.Struct:
+		return "M"
+	}
+	return ""
+}
+
+// get the element type of the pointer type
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// ValidateCreateTableInput checks CreateTableInput before CreateTable operation,
+// every key attribute of the table and the indexes must be defined in AttributeDefinitions as S, N or B,
+// every defined attribute must be used by the keys, and the table and the global secondary indexes must have ProvisionedThroughput.
+// (the default throughput of the client is not considered, and this SDK does not support BillingMode)
+func ValidateCreateTableInput(in *SDK.CreateTableInput) error {
+	if in == nil || in.TableName == nil || *in.TableName == "" {
+		return errors.New("[DynamoDB] TableName is required on CreateTableInput")
+	}
+	table := *in.TableName
+
+	defined := make(map[string]string, len(in.AttributeDefinitions))
+	for _, attr := range in.AttributeDefinitions {
+		if attr == nil || attr.AttributeName == nil || attr.AttributeType == nil {
+			return errors.New("[DynamoDB] AttributeName and AttributeType are required on AttributeDefinitions, table=" + table)
+		}
+		name := *attr.AttributeName
+		if _, ok := defined[name]; ok {
+			return errors.New("[DynamoDB] duplicated attribute on AttributeDefinitions, table=" + table + ", name=" + name)
+		}
+		switch *attr.AttributeType {
+		case "S", "N", "B":
+		default:
+			return errors.New("[DynamoDB] unsupported type for key attribute, table=" + table + ", name=" + name + ", type=" + *attr.AttributeType)
+		}
+		defined[name] = *attr.AttributeType
+	}
+
+	used := make(map[string]bool)
+	if err := validateKeySchema(in.KeySchema, defined, used, "table="+table); err != nil {
+		return err
+	}
+	if err := validateThroughput(in.ProvisionedThroughput, "table="+table); err != nil {
+		return err
+	}
+
+	indexNames := make(map[string]bool)
+	for _, gsi := range in.GlobalSecondaryIndexes {
+		if gsi == nil {
+			continue
+		}
+		target, err := validateIndexName(gsi.IndexName, indexNames, table)
+		if err != nil {
+			return err
+		}
+		if err := validateKeySchema(gsi.KeySchema, defined, used, target); err != nil {
+			return err
+		}
+		if err := validateProjection(gsi.Projection, target); err != nil {
+			return err
+		}
+		if err := validateThroughput(gsi.ProvisionedThroughput, target); err != nil {
+			return err
+		}
+	}
+	for _, lsi := range in.LocalSecondaryIndexes {
+		if lsi == nil {
+			continue
+		}
+		target, err := validateIndexName(lsi.IndexName, indexNames, table)
+		if err != nil {
+			return err
+		}
+		if err := validateKeySchema(lsi.KeySchema, defined, used, target); err != nil {
+			return err
+		}
+		switch {
+		case len(in.KeySchema) < 2:
+			return errors.New("[DynamoDB] local secondary index requires the range key on the table, " + target)
+		case len(lsi.KeySchema) < 2:
+			return errors.New("[DynamoDB] range key is required on local secondary index, " + target)
+		case *lsi.KeySchema[0].AttributeName != *in.KeySchema[0].AttributeName:
+			return errors.New("[DynamoDB] hash key of local secondary index must be same as the table, " + target)
+		}
+		if err := validateProjection(lsi.Projection, target); err != nil {
+			return err
+		}
+	}
+
+	for _, attr := range in.AttributeDefinitions {
+		if !used[*attr.AttributeName] {
+			return errors.New("[DynamoDB] attribute on AttributeDefinitions is not used by the keys, table=" + table + ", name=" + *attr.AttributeName)
+		}
+	}
+	return nil
+}
+
+// check the key schema has HASH key and optional RANGE key of the defined attributes, and mark them as used
+func validateKeySchema(schema []*SDK.KeySchemaElement, defined map[string]string, used map[string]bool, target string) error {
+	if len(schema) == 0 || len(schema) > 2 {
+		return errors.New("[DynamoDB] KeySchema must have hash key and optional range key, " + target)
+	}
+	for i, elem := range schema {
+		if elem == nil || elem.AttributeName == nil || elem.KeyType == nil {
+			return errors.New("[DynamoDB] AttributeName and KeyType are required on KeySchema, " + target)
+		}
+		expected := KeyTypeHash
+		if i == 1 {
+			expected = KeyTypeRange
+		}
+		if *elem.KeyType != expected {
+			return errors.New("[DynamoDB] KeyType must be " + expected + " on KeySchema, " + target + ", name=" + *elem.AttributeName)
+		}
+		if _, ok := defined[*elem.AttributeName]; !ok {
+			return errors.New("[DynamoDB] key attribute is not found on AttributeDefinitions, " + target + ", name=" + *elem.AttributeName)
+		}
+		used[*elem.AttributeName] = true
+	}
+	return nil
+}
+
+// check the index name is not empty and unique in the table
+func validateIndexName(name *string, names map[string]bool, table string) (string, error) {
+	if name == nil || *name == "" {
+		return "", errors.New("[DynamoDB] IndexName is required on secondary index, table=" + table)
+	}
+	target := "table=" + table + ", index=" + *name
+	if names[*name] {
+		return "", errors.New("[DynamoDB] duplicated index name, " + target)
+	}
+	names[*name] = true
+	return target, nil
+}
+
+// check the projection type, and NonKeyAttributes is used only for INCLUDE
+func validateProjection(p *SDK.Projection, target string) error {
+	if p == nil || p.ProjectionType == nil {
+		return errors.New("[DynamoDB] ProjectionType is required on secondary index, " + target)
+	}
+	switch *p.ProjectionType {
+	case ProjectionTypeInclude:
+		if len(p.NonKeyAttributes) == 0 {
+			return errors.New("[DynamoDB] NonKeyAttributes is required for INCLUDE projection, " + target)
+		}
+	case ProjectionTypeAll, ProjectionTypeKeysOnly:
+		if len(p.NonKeyAttributes) != 0 {
+			return errors.New("[DynamoDB] NonKeyAttributes is only for INCLUDE projection, " + target)
+		}
+	default:
+		return errors.New("[DynamoDB] unsupported ProjectionType, " + target + ", type=" + *p.ProjectionType)
+	}
+	return nil
+}
+
+// check the read and write capacity units are positive
+func validateThroughput(p *SDK.ProvisionedThroughput, target string) error {
+	if p == nil || p.ReadCapacityUnits == nil || p.WriteCapacityUnits == nil {
+		return errors.New("[DynamoDB] ProvisionedThroughput is required, " + target)
+	}
+	if *p.ReadCapacityUnits < 1 || *p.WriteCapacityUnits < 1 {
+		return errors.New("[DynamoDB] capacity units of ProvisionedThroughput must be positive, " + target)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+type schemaTestUser struct {
+	ID      int64  `dynamodb:"pk,hash"`
+	Name    string `dynamodb:"sk,range"`
+	Email   string `dynamodb:"email"`
+	Age     int
+	Ignored string `dynamodb:"-"`
+}
+
+func TestTableFromStruct(t *testing.T) {
+	in, err := TableFromStruct(&schemaTestUser{}, "users")
+	if err != nil {
+		t.Errorf("error on TableFromStruct, %s", err.Error())
+		return
+	}
+	if *in.TableName != "users" || len(in.KeySchema) != 2 || len(in.AttributeDefinitions) != 2 {
+		t.Errorf("error on TableFromStruct, %v", in)
+		return
+	}
+	if *in.KeySchema[0].AttributeName != "pk" || *in.KeySchema[0].KeyType != KeyTypeHash ||
+		*in.KeySchema[1].AttributeName != "sk" || *in.KeySchema[1].KeyType != KeyTypeRange {
+		t.Errorf("error on TableFromStruct, %v", in.KeySchema)
+	}
+	if *in.AttributeDefinitions[0].AttributeType != "N" || *in.AttributeDefinitions[1].AttributeType != "S" {
+		t.Errorf("error on TableFromStruct, %v", in.AttributeDefinitions)
+	}
+
+	hashOnly := struct {
+		ID   []byte `dynamodb:"id,hash"`
+		Data string
+	}{}
+	in, err = TableFromStruct(hashOnly, "hash")
+	if err != nil || len(in.KeySchema) != 1 || *in.AttributeDefinitions[0].AttributeType != "B" {
+		t.Errorf("error on TableFromStruct, %v, %v", in, err)
+	}
+}
+
+func TestTableFromStructError(t *testing.T) {
+	noHash := struct {
+		Name string `dynamodb:"sk,range"`
+	}{}
+	multiHash := struct {
+		ID   int    `dynamodb:"id,hash"`
+		Name string `dynamodb:"name,hash"`
+	}{}
+	conflicted := struct {
+		ID    int    `dynamodb:"id,hash"`
+		Other string `dynamodb:"id"`
+	}{}
+	invalidKey := struct {
+		ID bool `dynamodb:"id,hash"`
+	}{}
+
+	tests := []interface{}{
+		noHash,
+		multiHash,
+		conflicted,
+		invalidKey,
+		"not struct",
+		nil,
+	}
+	for _, v := range tests {
+		if _, err := TableFromStruct(v, "foo"); err == nil {
+			t.Errorf("error on TableFromStruct, error must be returned, value=%v", v)
+		}
+	}
+}
+
+func TestParseDefaultValue(t *testing.T) {
+	v := struct {
+		Status  string  `dynamodb:"status,default=active"`
+		Count   int64   `dynamodb:"count,default=10"`
+		Rate    float64 `dynamodb:"rate,default=0.5"`
+		Enabled *bool   `dynamodb:"enabled,omitempty,default=true"`
+		Memo    string  `dynamodb:"memo,omitempty"`
+	}{}
+	fields, err := parseStructFields(v)
+	if err != nil {
+		t.Errorf("error on parseStructFields, %s", err.Error())
+	}
+	expected := []interface{}{"active", int64(10), 0.5, true, nil}
+	for i, f := range fields {
+		if f.defaultValue != expected[i] {
+			t.Errorf("error on parseStructFields, name=%s, default=%#v", f.name, f.defaultValue)
+		}
+	}
+	if fields[2].omitEmpty || !fields[3].omitEmpty || !fields[4].omitEmpty {
+		t.Errorf("error on parseStructFields, omitempty is not parsed")
+	}
+
+	tests := []interface{}{
+		struct {
+			Count int `dynamodb:"count,default=foo"`
+		}{},
+		struct {
+			Small int8 `dynamodb:"small,default=1000"`
+		}{},
+		struct {
+			Tags []string `dynamodb:"tags,default=a"`
+		}{},
+	}
+	for _, v := range tests {
+		if _, err := parseStructFields(v); err == nil {
+			t.Errorf("error on parseStructFields, error must be returned, value=%v", v)
+		}
+	}
+}
+
+func TestSnakeCaseMapper(t *testing.T) {
+	tests := map[string]string{
+		"Name":       "name",
+		"UserID":     "user_id",
+		"ID":         "id",
+		"CreatedAt":  "created_at",
+		"HTTPStatus": "http_status",
+		"Item2Name":  "item2_name",
+		"snake_case": "snake_case",
+		"":           "",
+	}
+	for name, expected := range tests {
+		if actual := SnakeCaseMapper(name); actual != expected {
+			t.Errorf("error on SnakeCaseMapper, name=%s, expected=%s, actual=%s", name, expected, actual)
+		}
+	}
+}
+
+func TestSetNameMapper(t *testing.T) {
+	v := struct {
+		UserID    int    `dynamodb:",hash"`
+		CreatedAt int    `dynamodb:",range"`
+		FullName  string `dynamodb:"name"`
+	}{}
+	SetNameMapper(SnakeCaseMapper)
+	defer SetNameMapper(nil)
+	fields, _ := parseStructFields(v)
+	if fields[0].name != "user_id" || fields[1].name != "created_at" || fields[2].name != "name" {
+		t.Errorf("error on SetNameMapper, %v, %v, %v", fields[0], fields[1], fields[2])
+	}
+	in, err := TableFromStruct(v, "users")
+	if err != nil || *in.KeySchema[0].AttributeName != "user_id" {
+		t.Errorf("error on TableFromStruct with NameMapper, %v, %v", in, err)
+	}
+
+	SetNameMapper(nil)
+	fields, _ = parseStructFields(v)
+	if fields[0].name != "UserID" || fields[2].name != "name" {
+		t.Errorf("error on SetNameMapper, %v, %v", fields[0], fields[2])
+	}
+}
+
+func newTestCreateTableInput() *SDK.CreateTableInput {
+	return &SDK.CreateTableInput{
+		TableName: String("users"),
+		KeySchema: NewKeySchema(NewHashKeyElement("id"), NewRangeKeyElement("time")),
+		AttributeDefinitions: NewAttributeDefinitions(
+			NewNumberAttribute("id"),
+			NewNumberAttribute("time"),
+			NewStringAttribute("email"),
+			NewStringAttribute("name"),
+		),
+		ProvisionedThroughput: NewProvisionedThroughput(1, 1),
+		GlobalSecondaryIndexes: []*SDK.GlobalSecondaryIndex{
+			NewGSI("email-index", NewKeySchema(NewHashKeyElement("email")), NewProvisionedThroughput(1, 1)),
+		},
+		LocalSecondaryIndexes: []*SDK.LocalSecondaryIndex{
+			NewLSI("name-index", NewKeySchema(NewHashKeyElement("id"), NewRangeKeyElement("name")), ProjectionTypeKeysOnly),
+		},
+	}
+}
+
+func TestValidateCreateTableInput(t *testing.T) {
+	if err := ValidateCreateTableInput(newTestCreateTableInput()); err != nil {
+		t.Errorf("error on ValidateCreateTableInput, %s", err.Error())
+	}
+}
+
+func TestValidateCreateTableInputError(t *testing.T) {
+	tests := []struct {
+		modify   func(in *SDK.CreateTableInput)
+		expected string
+	}{
+		{func(in *SDK.CreateTableInput) { in.TableName = nil }, "TableName is required"},
+		{func(in *SDK.CreateTableInput) {
+			in.AttributeDefinitions = in.AttributeDefinitions[1:]
+		}, "key attribute is not found on AttributeDefinitions, table=users, name=id"},
+		{func(in *SDK.CreateTableInput) {
+			in.AttributeDefinitions = append(in.AttributeDefinitions, NewStringAttribute("unused"))
+		}, "not used by the keys, table=users, name=unused"},
+		{func(in *SDK.CreateTableInput) {
+			in.AttributeDefinitions = append(in.AttributeDefinitions, NewStringAttribute("id"))
+		}, "duplicated attribute"},
+		{func(in *SDK.CreateTableInput) {
+			in.GlobalSecondaryIndexes[0].KeySchema = NewKeySchema(NewHashKeyElement("undefined"))
+		}, "table=users, index=email-index, name=undefined"},
+		{func(in *SDK.CreateTableInput) {
+			in.GlobalSecondaryIndexes[0].ProvisionedThroughput = nil
+		}, "ProvisionedThroughput is required, table=users, index=email-index"},
+		{func(in *SDK.CreateTableInput) { in.ProvisionedThroughput = NewProvisionedThroughput(0, 1) }, "must be positive, table=users"},
+		{func(in *SDK.CreateTableInput) {
+			in.KeySchema = NewKeySchema(NewRangeKeyElement("id"))
+		}, "KeyType must be HASH"},
+		{func(in *SDK.CreateTableInput) {
+			in.LocalSecondaryIndexes[0].KeySchema = NewKeySchema(NewHashKeyElement("email"), NewRangeKeyElement("name"))
+		}, "hash key of local secondary index must be same as the table"},
+		{func(in *SDK.CreateTableInput) {
+			in.LocalSecondaryIndexes[0].IndexName = String("email-index")
+		}, "duplicated index name"},
+		{func(in *SDK.CreateTableInput) {
+			in.GlobalSecondaryIndexes[0].Projection = &SDK.Projection{ProjectionType: String(ProjectionTypeInclude)}
+		}, "NonKeyAttributes is required"},
+	}
+	for i, tt := range tests {
+		in := newTestCreateTableInput()
+		tt.modify(in)
+		err := ValidateCreateTableInput(in)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("error on ValidateCreateTableInput, case=%d, err=%v", i, err)
+		}
+	}
+}
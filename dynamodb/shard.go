@@ -0,0 +1,59 @@
+// DynamoDB write sharding for the hot partition key
+
+package dynamodb
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// separator between the base key and the shard number
+const shardKeySeparator = "#"
+
+// ShardedKey returns the partition key with the random shard suffix in [0, shards), like "base#3",
+// it spreads the writes of the hot key over the partitions. the base key is returned when shards <= 1
+func ShardedKey(base string, shards int) string {
+	if shards <= 1 {
+		return base
+	}
+	return shardKey(base, rand.Intn(shards))
+}
+
+// get the partition key of the shard number
+func shardKey(base string, shard int) string {
+	return base + shardKeySeparator + strconv.Itoa(shard)
+}
+
+// QueryAllShards retrieves all of the items in the partitions of every shard created by ShardedKey,
+// the shards are queried in parallel up to the max concurrency and the results are merged in order of the shard number
+func (t *DynamoTable) QueryAllShards(baseKey string, shards int) ([]map[string]interface{}, error) {
+	if shards <= 1 {
+		return t.queryPartition(baseKey)
+	}
+
+	results := make([][]map[string]interface{}, shards)
+	errs := make([]error, shards)
+	t.parallel(shards, func(shard int) {
+		results[shard], errs[shard] = t.queryPartition(shardKey(baseKey, shard))
+	})
+
+	var items []map[string]interface{}
+	for i := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		items = append(items, results[i]...)
+	}
+	return items, nil
+}
+
+// get all of the items in the partition
+func (t *DynamoTable) queryPartition(hash interface{}) ([]map[string]interface{}, error) {
+	keyCond := t.NewFilterBuilder()
+	keyCond.AddEQ(Literal(t.GetHashKeyName()), hash)
+	in, err := newExpressionQueryInput(t.name, keyCond, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.queryAll(in)
+}
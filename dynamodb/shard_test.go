@@ -0,0 +1,74 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+func TestShardedKey(t *testing.T) {
+	if ShardedKey("foo", 0) != "foo" || ShardedKey("foo", 1) != "foo" {
+		t.Errorf("error on ShardedKey, shard suffix is added for single shard")
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		key := ShardedKey("foo", 4)
+		if !strings.HasPrefix(key, "foo#") {
+			t.Fatalf("error on ShardedKey, actual=%s", key)
+		}
+		seen[key] = true
+	}
+	for k := range seen {
+		switch k {
+		case "foo#0", "foo#1", "foo#2", "foo#3":
+		default:
+			t.Errorf("error on ShardedKey, out of range key=%s", k)
+		}
+	}
+}
+
+func TestQueryAllShards(t *testing.T) {
+	tbl := getTestShardTable()
+	tbl.DeleteAll()
+	for i := 0; i < 10; i++ {
+		item := NewItem()
+		item.AddAttribute("id", ShardedKey("foo", 3))
+		item.AddAttribute("time", i)
+		tbl.AddItem(item)
+	}
+	item := NewItem()
+	item.AddAttribute("id", "bar#0")
+	item.AddAttribute("time", 100)
+	tbl.AddItem(item)
+	tbl.Put()
+
+	results, err := tbl.QueryAllShards("foo", 3)
+	if err != nil {
+		t.Errorf("error on QueryAllShards, %s", err.Error())
+	}
+	if len(results) != 10 {
+		t.Errorf("error on QueryAllShards, %v", results)
+	}
+}
+
+func getTestShardTable() *DynamoTable {
+	setTestEnv()
+
+	c := NewClient()
+	name := "foo_shardtable"
+	in := SDK.CreateTableInput{
+		TableName: String(GetTablePrefix() + name),
+		KeySchema: NewKeySchema(
+			NewHashKeyElement("id"),
+			NewRangeKeyElement("time")),
+		AttributeDefinitions: NewAttributeDefinitions(
+			NewStringAttribute("id"),
+			NewNumberAttribute("time")),
+		ProvisionedThroughput: NewProvisionedThroughput(1, 1),
+	}
+	createTable(c, in)
+	tbl, _ := c.GetTable(name)
+	return tbl
+}
@@ -0,0 +1,111 @@
+// DynamoDB Item size estimation
+
+package dynamodb
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	// max size of an item, includes attribute names and values
+	MaxItemSize = 400 * 1024
+
+	// overhead bytes for List and Map attribute
+	containerOverheadSize = 3
+	// overhead bytes for each element in List and Map attribute
+	elementOverheadSize = 1
+)
+
+// ItemSize returns the estimated size of the item as DynamoDB accounts
+func ItemSize(item map[string]interface{}) (int, error) {
+	return itemSize(Marshal(item))
+}
+
+// get the size of the item, sum of the attribute names and values
+func itemSize(item *map[string]*SDK.AttributeValue) (int, error) {
+	if item == nil {
+		return 0, nil
+	}
+	var size int
+	for name, v := range *item {
+		s, err := attributeValueSize(v)
+		if err != nil {
+			return 0, errors.New(err.Error() + ", attribute=" + name)
+		}
+		size += len(name) + s
+	}
+	return size, nil
+}
+
+// get the size of the attribute value
+func attributeValueSize(v *SDK.AttributeValue) (int, error) {
+	switch {
+	case v == nil:
+		return 0, errors.New("[DynamoDB] attribute value is nil")
+	case v.S != nil:
+		return len(*v.S), nil
+	case v.N != nil:
+		return numberSize(*v.N), nil
+	case v.B != nil:
+		return len(v.B), nil
+	case v.BOOL != nil, v.NULL != nil:
+		return 1, nil
+	case v.SS != nil:
+		var size int
+		for _, s := range v.SS {
+			size += len(*s)
+		}
+		return size, nil
+	case v.NS != nil:
+		var size int
+		for _, n := range v.NS {
+			size += numberSize(*n)
+		}
+		return size, nil
+	case v.BS != nil:
+		var size int
+		for _, b := range v.BS {
+			size += len(b)
+		}
+		return size, nil
+	case v.L != nil:
+		size := containerOverheadSize
+		for _, elem := range v.L {
+			s, err := attributeValueSize(elem)
+			if err != nil {
+				return 0, err
+			}
+			size += s + elementOverheadSize
+		}
+		return size, nil
+	case v.M != nil:
+		size := containerOverheadSize
+		for name, elem := range *v.M {
+			s, err := attributeValueSize(elem)
+			if err != nil {
+				return 0, err
+			}
+			size += len(name) + s + elementOverheadSize
+		}
+		return size, nil
+	}
+	return 0, errors.New("[DynamoDB] unsupported type of attribute value")
+}
+
+// get the size of the number, 1 byte per two significant digits plus 1 byte
+func numberSize(n string) int {
+	n = strings.TrimLeft(n, "+-")
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		n = n[:i]
+	}
+	digits := strings.Trim(strings.Replace(n, ".", "", 1), "0")
+	count := utf8.RuneCountInString(digits)
+	if count == 0 {
+		return 1
+	}
+	return (count+1)/2 + 1
+}
@@ -0,0 +1,82 @@
+package dynamodb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestItemSize(t *testing.T) {
+	item := map[string]interface{}{
+		"id":   1,
+		"name": "foo",
+		"ok":   true,
+	}
+	size, err := ItemSize(item)
+	if err != nil {
+		t.Errorf("error on ItemSize, %s", err.Error())
+	}
+	// id(2) + 1(2) + name(4) + foo(3) + ok(2) + true(1)
+	if size != 14 {
+		t.Errorf("error on ItemSize, size=%d", size)
+	}
+
+	nested := map[string]interface{}{
+		"m": map[string]interface{}{"a": "bc"},
+		"l": []interface{}{"abc", nil},
+	}
+	size, err = ItemSize(nested)
+	if err != nil {
+		t.Errorf("error on ItemSize, %s", err.Error())
+	}
+	// m(1) + [3 + a(1) + bc(2) + 1] + l(1) + [3 + abc(3) + 1 + null(1) + 1]
+	if size != 18 {
+		t.Errorf("error on ItemSize, size=%d", size)
+	}
+
+	_, err = ItemSize(map[string]interface{}{"st": TestStruct{}})
+	if err == nil {
+		t.Errorf("error on ItemSize, error must be returned for unsupported type")
+	}
+}
+
+func TestNumberSize(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected int
+	}{
+		{"0", 1},
+		{"1", 2},
+		{"12", 2},
+		{"123", 3},
+		{"-123.45", 4},
+		{"1000", 2},
+		{"0.001", 2},
+		{"1.5E10", 2},
+	}
+	for _, tt := range tests {
+		if size := numberSize(tt.number); size != tt.expected {
+			t.Errorf("error on numberSize, number=%s, expected=%d, actual=%d", tt.number, tt.expected, size)
+		}
+	}
+}
+
+func TestCheckItemSize(t *testing.T) {
+	tbl := getTestTable()
+	tbl.CheckItemSize(true)
+	defer tbl.CheckItemSize(false)
+
+	item := NewItem()
+	item.AddAttribute("id", 100)
+	item.AddAttribute("time", 1)
+	item.AddAttribute("data", strings.Repeat("x", MaxItemSize))
+	tbl.AddItem(item)
+	err := tbl.Put()
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("error on Put, error must be returned for large item, %v", err)
+	}
+
+	err = putTestTable(tbl, 100, 1)
+	if err != nil {
+		t.Errorf("error on Put, %s", err.Error())
+	}
+}
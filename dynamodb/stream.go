@@ -0,0 +1,66 @@
+// DynamoDB Streams image helper
+
+package dynamodb
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// StreamDiff compares OldImage and NewImage of the MODIFY stream record,
+// and returns the decoded values of the changed or added attributes and the sorted names of the removed attributes.
+// the members of the set (SS, NS and BS) are compared regardless of the order
+func StreamDiff(old, updated map[string]*SDK.AttributeValue) (changed map[string]interface{}, removed []string) {
+	changed = make(map[string]interface{})
+	for name, v := range updated {
+		if prev, ok := old[name]; ok && isSameImageValue(prev, v) {
+			continue
+		}
+		changed[name] = defaultMarshalOptions.getAttributeValue(name, v)
+	}
+	for name := range old {
+		if _, ok := updated[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// check if the AttributeValues are same, the set is compared as sorted members
+func isSameImageValue(a, b *SDK.AttributeValue) bool {
+	switch {
+	case a == nil || b == nil:
+		return a == b
+	case len(a.SS) > 0 && len(b.SS) > 0:
+		return reflect.DeepEqual(sortedStringSet(a.SS), sortedStringSet(b.SS))
+	case len(a.NS) > 0 && len(b.NS) > 0:
+		return reflect.DeepEqual(sortedStringSet(a.NS), sortedStringSet(b.NS))
+	case len(a.BS) > 0 && len(b.BS) > 0:
+		return reflect.DeepEqual(sortedBinarySet(a.BS), sortedBinarySet(b.BS))
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedStringSet(values []*string) []string {
+	list := make([]string, len(values))
+	for i, v := range values {
+		if v != nil {
+			list[i] = *v
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+func sortedBinarySet(values [][]byte) [][]byte {
+	list := make([][]byte, len(values))
+	copy(list, values)
+	sort.Slice(list, func(i, j int) bool {
+		return bytes.Compare(list[i], list[j]) < 0
+	})
+	return list
+}
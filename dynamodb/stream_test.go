@@ -0,0 +1,42 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStreamDiff(t *testing.T) {
+	old := *Marshal(map[string]interface{}{
+		"id":    100,
+		"name":  "foo",
+		"count": 5,
+		"tags":  []string{"a", "b"},
+		"memo":  "bar",
+		"bin":   [][]byte{[]byte("x"), []byte("y")},
+	})
+	updated := *Marshal(map[string]interface{}{
+		"id":     100,
+		"name":   "foo",
+		"count":  6,
+		"tags":   []string{"b", "a"},
+		"status": "active",
+		"bin":    [][]byte{[]byte("y"), []byte("x")},
+	})
+
+	changed, removed := StreamDiff(old, updated)
+	expected := map[string]interface{}{
+		"count":  6,
+		"status": "active",
+	}
+	if !reflect.DeepEqual(expected, changed) {
+		t.Errorf("error on StreamDiff, changed=%v", changed)
+	}
+	if len(removed) != 1 || removed[0] != "memo" {
+		t.Errorf("error on StreamDiff, removed=%v", removed)
+	}
+
+	changed, removed = StreamDiff(nil, updated)
+	if len(changed) != len(updated) || len(removed) != 0 {
+		t.Errorf("error on StreamDiff, changed=%v, removed=%v", changed, removed)
+	}
+}
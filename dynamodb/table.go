@@ -5,11 +5,33 @@ package dynamodb
 import (
 	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
 
+	"bytes"
+	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+	"math/big"
+	"strconv"
 	"strings"
+	"time"
 )
 
+const (
+	errCodeConditionalCheckFailed = "ConditionalCheckFailedException"
+
+	ReturnValuesAllNew     = "ALL_NEW"
+	ReturnValuesUpdatedNew = "UPDATED_NEW"
+)
+
+// ErrConditionFailed is returned when the condition of the conditional write is not satisfied
+var ErrConditionFailed = errors.New("[DynamoDB] the conditional request failed")
+
+// ErrResultTruncated is returned with the partial result when the number of items reaches the max items
+var ErrResultTruncated = errors.New("[DynamoDB] the result is truncated by the max items")
+
 // DynamoTable is a wapper struct for DynamoDB table
 type DynamoTable struct {
 	db         *AmazonDynamoDB
@@ -18,6 +40,123 @@ type DynamoTable struct {
 	indexes    map[string]*DynamoIndex
 	writeItems []*SDK.PutItemInput
 	errorItems []*SDK.PutItemInput
+
+	describedAt time.Time
+
+	checkItemSize bool
+	strictKeys    bool
+
+	// defaults of the requests, used when the request does not have the value
+	defaultConsistentRead bool
+	defaultReturnCapacity string
+
+	// retry limits for the unprocessed items of batch operations
+	batchMaxRetries int
+	batchTimeout    time.Duration
+
+	// max number of the concurrent requests on the parallel operations
+	concurrency int
+
+	// read-through cache of GetOne and Query
+	cache    Cache
+	cacheTTL time.Duration
+
+	returnItemCollectionMetrics bool
+}
+
+// ItemCollectionMetrics is the size information of the item collection returned from write operation
+type ItemCollectionMetrics struct {
+	Key                 map[string]interface{}
+	SizeEstimateRangeGB []float64
+}
+
+// ReturnItemCollectionMetrics sets the flag to request item collection metrics on write operations,
+// the metrics are returned by PutWithResult, DeleteWithResult and UpdateItemWithResult (only the table with LSI returns the metrics)
+func (t *DynamoTable) ReturnItemCollectionMetrics(b bool) {
+	t.returnItemCollectionMetrics = b
+}
+
+// CheckItemSize sets the flag to validate the item size before the write operation
+func (t *DynamoTable) CheckItemSize(b bool) {
+	t.checkItemSize = b
+}
+
+// StrictKeys sets the flag to validate the key attributes of the item before PutItem and UpdateItem
+// with the key schema and the attribute definitions of the table description
+func (t *DynamoTable) StrictKeys(b bool) {
+	t.strictKeys = b
+}
+
+// SetDefaultConsistentRead sets the default ConsistentRead of GetItem, Query and BatchGetItem on the table,
+// the request with ConsistentRead keeps its value and the query on GSI is not changed
+func (t *DynamoTable) SetDefaultConsistentRead(b bool) {
+	t.defaultConsistentRead = b
+}
+
+// SetDefaultReturnCapacity sets the default ReturnConsumedCapacity (INDEXES, TOTAL or NONE) of the reads and the writes on the table,
+// the request with ReturnConsumedCapacity keeps its value and empty string restores the default of the operation
+func (t *DynamoTable) SetDefaultReturnCapacity(v string) {
+	t.defaultReturnCapacity = v
+}
+
+// get ConsistentRead of the request with the default of the table, the default is not used for GSI
+func (t *DynamoTable) consistentRead(v *bool, indexName *string) *bool {
+	if v != nil || !t.defaultConsistentRead {
+		return v
+	}
+	if indexName != nil {
+		if idx, ok := t.indexes[*indexName]; !ok || idx.IndexType == indexTypeGSI {
+			return v
+		}
+	}
+	return Boolean(true)
+}
+
+// get ReturnConsumedCapacity of the request with the default of the table
+func (t *DynamoTable) returnCapacity(v *string) *string {
+	if v != nil || t.defaultReturnCapacity == "" {
+		return v
+	}
+	return String(t.defaultReturnCapacity)
+}
+
+// apply the defaults of the table to GetItemInput
+func (t *DynamoTable) applyGetDefaults(in *SDK.GetItemInput) {
+	in.ConsistentRead = t.consistentRead(in.ConsistentRead, nil)
+	in.ReturnConsumedCapacity = t.returnCapacity(in.ReturnConsumedCapacity)
+}
+
+// apply the defaults of the table to QueryInput
+func (t *DynamoTable) applyQueryDefaults(in *SDK.QueryInput) {
+	in.ConsistentRead = t.consistentRead(in.ConsistentRead, in.IndexName)
+	in.ReturnConsumedCapacity = t.returnCapacity(in.ReturnConsumedCapacity)
+}
+
+// convert item collection metrics from write operation's response, returns nil when the response does not have the metrics
+func (t *DynamoTable) newItemCollectionMetrics(m *SDK.ItemCollectionMetrics) *ItemCollectionMetrics {
+	if m == nil {
+		return nil
+	}
+	metrics := &ItemCollectionMetrics{
+		Key: t.options().unmarshal(m.ItemCollectionKey),
+	}
+	for _, size := range m.SizeEstimateRangeGB {
+		metrics.SizeEstimateRangeGB = append(metrics.SizeEstimateRangeGB, *size)
+	}
+	return metrics
+}
+
+// set the table description and the indexes, describedAt is the time of the description
+func (t *DynamoTable) setDescription(desc *SDK.TableDescription, describedAt time.Time) {
+	t.table = desc
+	t.indexes = make(map[string]*DynamoIndex)
+	for _, idx := range desc.LocalSecondaryIndexes {
+		t.indexes[*idx.IndexName] = NewDynamoIndex(*idx.IndexName, indexTypeLSI, idx.KeySchema)
+	}
+	for _, idx := range desc.GlobalSecondaryIndexes {
+		t.indexes[*idx.IndexName] = NewDynamoIndex(*idx.IndexName, indexTypeGSI, idx.KeySchema)
+	}
+	t.describedAt = describedAt
 }
 
 // AddItem adds an item to the write-waiting list (writeItem)
@@ -25,6 +164,12 @@ func (t *DynamoTable) AddItem(item *DynamoItem) {
 	w := &SDK.PutItemInput{}
 	w.TableName = String(t.name)
 	w.ReturnConsumedCapacity = String("TOTAL")
+	if t.defaultReturnCapacity != "" {
+		w.ReturnConsumedCapacity = String(t.defaultReturnCapacity)
+	}
+	if t.returnItemCollectionMetrics {
+		w.ReturnItemCollectionMetrics = String("SIZE")
+	}
 	w.Item = &item.data
 	w.Expected = &item.conditions
 	t.writeItems = append(t.writeItems, w)
@@ -33,8 +178,16 @@ func (t *DynamoTable) AddItem(item *DynamoItem) {
 
 // excecute write operation in the write-waiting list (writeItem)
 func (t *DynamoTable) Put() error {
+	_, err := t.PutWithResult()
+	return err
+}
+
+// PutWithResult executes write operation in the write-waiting list (writeItem) same as Put,
+// and returns the item collection metrics of the written items when ReturnItemCollectionMetrics is set
+func (t *DynamoTable) PutWithResult() ([]*ItemCollectionMetrics, error) {
 	var err error = nil
 	var errs []string
+	var metrics []*ItemCollectionMetrics
 	// アイテムの保存処理
 	for _, item := range t.writeItems {
 		if !t.isExistPrimaryKeys(item) {
@@ -43,37 +196,223 @@ func (t *DynamoTable) Put() error {
 			log.Error(msg, item)
 			continue
 		}
-		_, e := t.db.client.PutItem(item)
+		if t.checkItemSize {
+			if e := t.validateItemSize(item.Item); e != nil {
+				errs = append(errs, e.Error())
+				log.Error("[DynamoDB] Error on item size validation, table="+t.name, e)
+				t.errorItems = append(t.errorItems, item)
+				continue
+			}
+		}
+		if t.strictKeys {
+			if e := t.validateKeyAttributes(item.Item); e != nil {
+				errs = append(errs, e.Error())
+				log.Error("[DynamoDB] Error on key validation, table="+t.name, e)
+				t.errorItems = append(t.errorItems, item)
+				continue
+			}
+		}
+		res, e := t.db.client.PutItem(item)
+		t.invalidateCache(item.Item)
 		if e != nil {
+			e = wrapError("PutItem", e)
+			t.notifyThrottle("PutItem", item.Item, e)
 			errs = append(errs, e.Error())
 			t.errorItems = append(t.errorItems, item)
+			continue
+		}
+		if m := t.newItemCollectionMetrics(res.ItemCollectionMetrics); m != nil {
+			metrics = append(metrics, m)
 		}
 	}
 	t.writeItems = []*SDK.PutItemInput{}
 	if len(errs) != 0 {
 		err = errors.New(strings.Join(errs, "\n"))
 	}
-	return err
+	return metrics, err
+}
+
+// Upsert puts the item and returns true when the item is newly created.
+// the conditional put with `attribute_not_exists(hash key)` is performed at first,
+// and the unconditional put is performed when the item already exists.
+// it costs two write requests to replace the existing item, and these two requests are not atomic
+func (t *DynamoTable) Upsert(item map[string]interface{}) (created bool, err error) {
+	data := Marshal(item)
+	cond := t.NewFilterBuilder()
+	cond.AddNotExists(Literal(t.GetHashKeyName()))
+	in := &SDK.PutItemInput{
+		TableName:                 String(t.name),
+		Item:                      data,
+		ConditionExpression:       String(cond.Expression()),
+		ExpressionAttributeNames:  cond.attrs.expressionNames(),
+		ExpressionAttributeValues: cond.attrs.expressionValues(),
+	}
+	if !t.isExistPrimaryKeys(in) {
+		msg := "[DynamoDB] Cannot find primary key, table=" + t.name
+		log.Error(msg, item)
+		return false, errors.New(msg)
+	}
+
+	_, err = t.putItem(in)
+	switch {
+	case err == nil:
+		return true, nil
+	case err != ErrConditionFailed:
+		return false, err
+	}
+
+	_, err = t.putItem(&SDK.PutItemInput{
+		TableName: String(t.name),
+		Item:      data,
+	})
+	return false, err
+}
+
+// execute PutItem operation and returns the item collection metrics, returns ErrConditionFailed when the condition is not satisfied
+func (t *DynamoTable) putItem(in *SDK.PutItemInput) (*ItemCollectionMetrics, error) {
+	if t.returnItemCollectionMetrics {
+		in.ReturnItemCollectionMetrics = String("SIZE")
+	}
+	in.ReturnConsumedCapacity = t.returnCapacity(in.ReturnConsumedCapacity)
+	if t.checkItemSize {
+		if err := t.validateItemSize(in.Item); err != nil {
+			log.Error("[DynamoDB] Error on item size validation, table="+t.name, err)
+			return nil, err
+		}
+	}
+	if t.strictKeys {
+		if err := t.validateKeyAttributes(in.Item); err != nil {
+			log.Error("[DynamoDB] Error on key validation, table="+t.name, err)
+			return nil, err
+		}
+	}
+	res, err := t.db.client.PutItem(in)
+	t.invalidateCache(in.Item)
+	err = wrapError("PutItem", err)
+	t.notifyThrottle("PutItem", in.Item, err)
+	switch {
+	case isConditionalCheckFailed(err):
+		return nil, ErrConditionFailed
+	case err != nil:
+		log.Error("[DynamoDB] Error in `PutItem` operation, table="+t.name, err)
+		return nil, err
+	}
+	return t.newItemCollectionMetrics(res.ItemCollectionMetrics), nil
+}
+
+// check if the item size is not over the limit
+func (t *DynamoTable) validateItemSize(item *map[string]*SDK.AttributeValue) error {
+	size, err := itemSize(item)
+	switch {
+	case err != nil:
+		return err
+	case size > MaxItemSize:
+		return fmt.Errorf("[DynamoDB] item size exceeds the limit, table=%s, key=[%s], size=%d", t.name, t.keyString(item), size)
+	}
+	return nil
 }
 
 // GetOne retrieves a single item by GetOne(HashKey [, RangeKey])
 func (t *DynamoTable) GetOne(values ...Any) (map[string]interface{}, error) {
-	key := NewItem()
-	key.AddAttribute(t.GetHashKeyName(), values[0])
-	if len(values) > 1 && t.GetRangeKeyName() != "" {
-		key.AddAttribute(t.GetRangeKeyName(), values[1])
+	k, err := t.marshalKey(t.primaryKey(values))
+	if err != nil {
+		return nil, err
 	}
-
 	in := &SDK.GetItemInput{
 		TableName: String(t.name),
-		Key:       &key.data,
+		Key:       k,
+	}
+	t.applyGetDefaults(in)
+	key, cacheable := t.cacheKey("GetItem", (*in.Key)[t.GetHashKeyName()], in)
+	if cacheable {
+		if items, ok := t.loadCache(key); ok && len(items) == 1 {
+			return t.options().unmarshal(items[0]), nil
+		}
+	}
+
+	req, err := t.db.client.GetItem(in)
+	if err != nil {
+		err = wrapError("GetItem", err)
+		t.notifyThrottle("GetItem", in.Key, err)
+		log.Error("[DynamoDB] Error in `GetItem` operation, table="+t.name, err)
+		return nil, err
+	}
+	if cacheable {
+		t.storeCache(key, []*map[string]*SDK.AttributeValue{req.Item})
+	}
+	return t.options().unmarshal(req.Item), nil
+}
+
+// GetAttribute retrieves the single attribute of the item by the key with ProjectionExpression,
+// returns nil when the item or the attribute does not exist
+func (t *DynamoTable) GetAttribute(key map[string]interface{}, attr string) (interface{}, error) {
+	k, err := t.marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	attrs := t.options().newExpressionAttributes()
+	in := &SDK.GetItemInput{
+		TableName:                String(t.name),
+		Key:                      k,
+		ProjectionExpression:     String(attrs.name(attr)),
+		ExpressionAttributeNames: attrs.expressionNames(),
 	}
+	t.applyGetDefaults(in)
 	req, err := t.db.client.GetItem(in)
 	if err != nil {
+		err = wrapError("GetItem", err)
+		t.notifyThrottle("GetItem", in.Key, err)
 		log.Error("[DynamoDB] Error in `GetItem` operation, table="+t.name, err)
 		return nil, err
 	}
-	return Unmarshal(req.Item), nil
+	return t.options().unmarshal(req.Item)[attr], nil
+}
+
+// GetItemLive retrieves a single item by the key, and treats the item as absent when the TTL attribute is in the past.
+// (DynamoDB deletes the expired items in the background, it may take up to 48 hours after the expiration)
+func (t *DynamoTable) GetItemLive(key map[string]interface{}, ttlAttr string) (map[string]interface{}, bool, error) {
+	k, err := t.marshalKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	in := &SDK.GetItemInput{
+		TableName: String(t.name),
+		Key:       k,
+	}
+	t.applyGetDefaults(in)
+	req, err := t.db.client.GetItem(in)
+	if err != nil {
+		err = wrapError("GetItem", err)
+		t.notifyThrottle("GetItem", in.Key, err)
+		log.Error("[DynamoDB] Error in `GetItem` operation, table="+t.name, err)
+		return nil, false, err
+	}
+	item := t.options().unmarshal(req.Item)
+	if len(item) == 0 || isExpired(item[ttlAttr], t.db.currentTime()) {
+		return nil, false, nil
+	}
+	return item, true, nil
+}
+
+// check if the TTL value of epoch seconds is in the past,
+// the value which is not number is not expired as same as DynamoDB TTL
+func isExpired(v interface{}, now time.Time) bool {
+	var sec int64
+	switch t := v.(type) {
+	case int:
+		sec = int64(t)
+	case int64:
+		sec = t
+	case float64:
+		sec = int64(t)
+	default:
+		n, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+		if v == nil || err != nil {
+			return false
+		}
+		sec = int64(n)
+	}
+	return sec < now.Unix()
 }
 
 // query using LSI or GSI
@@ -87,14 +426,22 @@ func (t *DynamoTable) GetByIndex(idx string, values ...Any) ([]map[string]interf
 	hashKey := index.GetHashKeyName()
 	rangeKey := index.GetRangeKeyName()
 
+	hash, err := t.keyAttributeValue(hashKey, values[0])
+	if err != nil {
+		return nil, err
+	}
 	keys := make(map[string]*SDK.Condition)
 	keys[hashKey] = &SDK.Condition{
-		AttributeValueList: []*SDK.AttributeValue{createAttributeValue(values[0])},
+		AttributeValueList: []*SDK.AttributeValue{hash},
 		ComparisonOperator: String(ComparisonOperatorEQ),
 	}
 	if len(values) > 1 && rangeKey != "" {
+		rng, err := t.keyAttributeValue(rangeKey, values[1])
+		if err != nil {
+			return nil, err
+		}
 		keys[rangeKey] = &SDK.Condition{
-			AttributeValueList: []*SDK.AttributeValue{createAttributeValue(values[1])},
+			AttributeValueList: []*SDK.AttributeValue{rng},
 			ComparisonOperator: String(ComparisonOperatorEQ),
 		}
 	}
@@ -135,9 +482,13 @@ func (t *DynamoTable) Get(values ...Any) ([]map[string]interface{}, error) {
 		return t.getOneAsSlice(values)
 	}
 
+	hash, err := t.keyAttributeValue(t.GetHashKeyName(), values[0])
+	if err != nil {
+		return nil, err
+	}
 	keys := make(map[string]*SDK.Condition)
 	keys[t.GetHashKeyName()] = &SDK.Condition{
-		AttributeValueList: []*SDK.AttributeValue{createAttributeValue(values[0])},
+		AttributeValueList: []*SDK.AttributeValue{hash},
 		ComparisonOperator: String(ComparisonOperatorEQ),
 	}
 
@@ -150,22 +501,433 @@ func (t *DynamoTable) Get(values ...Any) ([]map[string]interface{}, error) {
 
 // get mapped-items with Query operation
 func (t *DynamoTable) Query(in *SDK.QueryInput) ([]map[string]interface{}, error) {
+	t.applyQueryDefaults(in)
+	key, cacheable := t.cacheKey("Query", t.queryPartitionValue(in), in)
+	if cacheable {
+		if items, ok := t.loadCache(key); ok {
+			return t.ConvertItemsToMapArray(items), nil
+		}
+	}
+
 	req, err := t.db.client.Query(in)
 	if err != nil {
+		err = wrapError("Query", err)
+		t.notifyThrottle("Query", nil, err)
 		log.Error("[DynamoDB] Error in `Query` operation, table="+t.name, err)
 		return nil, err
 	}
+	if cacheable {
+		t.storeCache(key, req.Items)
+	}
 	return t.ConvertItemsToMapArray(req.Items), nil
 }
 
+// get mapped-items with Query operation by KeyConditionExpression and FilterExpression,
+// the filter must be created by NewSharedFilterBuilder with the key condition (filter is optional)
+func (t *DynamoTable) QueryWithExpression(keyCond, filter *FilterBuilder) ([]map[string]interface{}, error) {
+	in, err := newExpressionQueryInput(t.name, keyCond, filter)
+	if err != nil {
+		return nil, err
+	}
+	return t.Query(in)
+}
+
+// get mapped-items whose sort key begins with the prefix in the partition,
+// all of the pages are fetched
+func (t *DynamoTable) QueryPrefix(hash interface{}, sortAttr, prefix string) ([]map[string]interface{}, error) {
+	keyCond := t.NewFilterBuilder()
+	keyCond.AddEQ(Literal(t.GetHashKeyName()), hash)
+	keyCond.AddBeginsWith(Literal(sortAttr), prefix)
+	in, err := newExpressionQueryInput(t.name, keyCond, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.queryAll(in)
+}
+
+// QueryBetween gets mapped-items whose sort key is between lo and hi (inclusive) in the partition,
+// all of the pages are fetched. returns error when lo is greater than hi or they are not the same type
+func (t *DynamoTable) QueryBetween(hash interface{}, sortAttr string, lo, hi interface{}) ([]map[string]interface{}, error) {
+	if err := t.options().validateBetween(lo, hi); err != nil {
+		return nil, err
+	}
+	keyCond := t.NewFilterBuilder()
+	keyCond.AddEQ(Literal(t.GetHashKeyName()), hash)
+	keyCond.AddBetween(Literal(sortAttr), lo, hi)
+	in, err := newExpressionQueryInput(t.name, keyCond, nil)
+	if err != nil {
+		return nil, err
+	}
+	return t.queryAll(in)
+}
+
+// check if the range of BETWEEN is valid, lo and hi must be the same type of string, number or binary and lo <= hi
+func (o *marshalOptions) validateBetween(lo, hi interface{}) error {
+	l := o.createAttributeValue(lo)
+	h := o.createAttributeValue(hi)
+	var cmp int
+	switch {
+	case l.S != nil && h.S != nil:
+		cmp = strings.Compare(*l.S, *h.S)
+	case l.N != nil && h.N != nil:
+		lr, ok1 := new(big.Rat).SetString(*l.N)
+		hr, ok2 := new(big.Rat).SetString(*h.N)
+		if !ok1 || !ok2 {
+			return errors.New("[DynamoDB] invalid number for BETWEEN, lo=" + *l.N + ", hi=" + *h.N)
+		}
+		cmp = lr.Cmp(hr)
+	case l.B != nil && h.B != nil:
+		cmp = bytes.Compare(l.B, h.B)
+	default:
+		return fmt.Errorf("[DynamoDB] BETWEEN requires the same type of string, number or binary, lo=%T, hi=%T", lo, hi)
+	}
+	if cmp > 0 {
+		return fmt.Errorf("[DynamoDB] lo must be less than or equal to hi for BETWEEN, lo=%v, hi=%v", lo, hi)
+	}
+	return nil
+}
+
+// QueryGrouped retrieves all of the items in the partition and groups them by the prefix of the sort key,
+// the prefix is the string up to the first `#` (e.g. "USER" for "USER#123"), or the whole value when it has no `#`
+func (t *DynamoTable) QueryGrouped(hash interface{}, sortAttr string) (map[string][]map[string]interface{}, error) {
+	items, err := t.queryPartition(hash)
+	if err != nil {
+		return nil, err
+	}
+	return groupBySortPrefix(items, sortAttr), nil
+}
+
+// group the items by the prefix of the sort key up to the first `#`,
+// the item without the sort key is grouped in the empty prefix
+func groupBySortPrefix(items []map[string]interface{}, sortAttr string) map[string][]map[string]interface{} {
+	groups := make(map[string][]map[string]interface{})
+	for _, item := range items {
+		var prefix string
+		if v, ok := item[sortAttr]; ok {
+			prefix = fmt.Sprint(v)
+		}
+		if i := strings.Index(prefix, "#"); i >= 0 {
+			prefix = prefix[:i]
+		}
+		groups[prefix] = append(groups[prefix], item)
+	}
+	return groups
+}
+
+// get mapped-items in the partition by descending order of the sort key,
+// returns up to the limit items from the last (returns all items when the limit is 0)
+func (t *DynamoTable) QueryDesc(hash interface{}, limit int64) ([]map[string]interface{}, error) {
+	keyCond := t.NewFilterBuilder()
+	keyCond.AddEQ(Literal(t.GetHashKeyName()), hash)
+	in, err := newExpressionQueryInput(t.name, keyCond, nil)
+	if err != nil {
+		return nil, err
+	}
+	in.ScanIndexForward = Boolean(false)
+	if limit > 0 {
+		in.Limit = Long(limit)
+		return t.Query(in)
+	}
+	return t.queryAll(in)
+}
+
+// get mapped-items with Query operation until n items are collected after the filter,
+// Limit of the input is used as the page size
+func (t *DynamoTable) QueryLimit(in *SDK.QueryInput, n int) ([]map[string]interface{}, error) {
+	return t.queryPages(in, n)
+}
+
+// get mapped-items with Query operation on the index,
+// the index must exist on the table and ConsistentRead is not allowed for GSI
+func (t *DynamoTable) QueryIndex(indexName string, in *SDK.QueryInput) ([]map[string]interface{}, error) {
+	q, err := t.newIndexQueryInput(indexName, in)
+	if err != nil {
+		return nil, err
+	}
+	return t.Query(q)
+}
+
+// get mapped-items whose sort key of the index begins with the prefix in the partition of the index,
+// all of the pages are fetched
+func (t *DynamoTable) QueryIndexPrefix(indexName string, hash interface{}, prefix string) ([]map[string]interface{}, error) {
+	index, err := t.getIndex(indexName)
+	if err != nil {
+		return nil, err
+	}
+	sortAttr := index.GetRangeKeyName()
+	if sortAttr == "" {
+		return nil, errors.New("[DynamoDB] the index does not have range key, table=" + t.name + ", index=" + indexName)
+	}
+
+	keyCond := t.NewFilterBuilder()
+	keyCond.AddEQ(Literal(index.GetHashKeyName()), hash)
+	keyCond.AddBeginsWith(Literal(sortAttr), prefix)
+	in, err := newExpressionQueryInput(t.name, keyCond, nil)
+	if err != nil {
+		return nil, err
+	}
+	q, err := t.newIndexQueryInput(indexName, in)
+	if err != nil {
+		return nil, err
+	}
+	return t.queryAll(q)
+}
+
+// get the index from the cached schema
+func (t *DynamoTable) getIndex(indexName string) (*DynamoIndex, error) {
+	index, ok := t.indexes[indexName]
+	if !ok {
+		return nil, errors.New("[DynamoDB] Cannot find the index name, table=" + t.name + ", index=" + indexName)
+	}
+	return index, nil
+}
+
+// IndexStatus returns the current status of the global secondary index (CREATING, UPDATING, DELETING or ACTIVE),
+// and true while the index is backfilling the existing items. the cached schema is refreshed by the description
+func (t *DynamoTable) IndexStatus(indexName string) (status string, backfilling bool, err error) {
+	desc, err := t.db.DescribeTable(t.name)
+	if err != nil {
+		return "", false, err
+	}
+	t.setDescription(desc, t.db.currentTime())
+	status, backfilling, ok := globalIndexStatus(desc, indexName)
+	if !ok {
+		return "", false, errors.New("[DynamoDB] Cannot find the global secondary index, table=" + t.name + ", index=" + indexName)
+	}
+	return status, backfilling, nil
+}
+
+// WaitForIndexActive waits until the global secondary index is ACTIVE and not backfilling, or the timeout
+func (t *DynamoTable) WaitForIndexActive(indexName string, timeout time.Duration) error {
+	deadline := t.currentTime().Add(timeout)
+	wait := tableWaitInterval
+	for {
+		status, backfilling, err := t.IndexStatus(indexName)
+		if err != nil {
+			return err
+		}
+		if status == tableStatusActive && !backfilling {
+			return nil
+		}
+
+		if t.currentTime().Add(wait).After(deadline) {
+			return fmt.Errorf("[DynamoDB] timeout on waiting for the index to be ACTIVE, table=%s, index=%s, status=%s, backfilling=%t", t.name, indexName, status, backfilling)
+		}
+		time.Sleep(wait)
+		wait *= 2
+		if wait > tableWaitMaxInterval {
+			wait = tableWaitMaxInterval
+		}
+	}
+}
+
+// get the status and backfilling of the global secondary index from the table description,
+// returns false when the index is not found
+func globalIndexStatus(desc *SDK.TableDescription, indexName string) (status string, backfilling bool, ok bool) {
+	for _, idx := range desc.GlobalSecondaryIndexes {
+		if idx.IndexName == nil || *idx.IndexName != indexName {
+			continue
+		}
+		if idx.IndexStatus != nil {
+			status = *idx.IndexStatus
+		}
+		return status, idx.Backfilling != nil && *idx.Backfilling, true
+	}
+	return "", false, false
+}
+
+// create a copy of QueryInput for the index, the original input is not changed
+func (t *DynamoTable) newIndexQueryInput(indexName string, in *SDK.QueryInput) (*SDK.QueryInput, error) {
+	index, err := t.getIndex(indexName)
+	if err != nil {
+		return nil, err
+	}
+	if index.IndexType == indexTypeGSI && in.ConsistentRead != nil && *in.ConsistentRead {
+		return nil, errors.New("[DynamoDB] ConsistentRead is not supported on GSI, table=" + t.name + ", index=" + indexName)
+	}
+
+	q := *in
+	q.IndexName = String(indexName)
+	if q.TableName == nil {
+		q.TableName = String(t.name)
+	}
+	return &q, nil
+}
+
+// get mapped-items with Query operation until the last page
+func (t *DynamoTable) queryAll(in *SDK.QueryInput) ([]map[string]interface{}, error) {
+	return t.queryPages(in, 0)
+}
+
+// get mapped-items with Query operation until n items are collected or the last page,
+// n=0 means no limit
+func (t *DynamoTable) queryPages(in *SDK.QueryInput, n int) ([]map[string]interface{}, error) {
+	var items []map[string]interface{}
+	q := *in
+	t.applyQueryDefaults(&q)
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		if token != nil {
+			q.ExclusiveStartKey = startKey(token)
+		}
+		req, err := t.db.client.Query(&q)
+		if err != nil {
+			err = wrapError("Query", err)
+			t.notifyThrottle("Query", nil, err)
+			log.Error("[DynamoDB] Error in `Query` operation, table="+t.name, err)
+			return nil, false, err
+		}
+		items = append(items, t.ConvertItemsToMapArray(req.Items)...)
+		if n > 0 && len(items) >= n {
+			items = items[:n]
+			return nil, true, nil
+		}
+		next, done := nextPageKey(req.LastEvaluatedKey)
+		return next, done, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// QueryAll gets mapped-items with Query operation until the last page,
+// returns up to maxItems items with ErrResultTruncated when more items may exist (maxItems=0 means no limit)
+func (t *DynamoTable) QueryAll(in *SDK.QueryInput, maxItems int) ([]map[string]interface{}, error) {
+	q := *in
+	t.applyQueryDefaults(&q)
+	return t.collectPages(maxItems, func(token interface{}) ([]*map[string]*SDK.AttributeValue, interface{}, bool, error) {
+		if token != nil {
+			q.ExclusiveStartKey = startKey(token)
+		}
+		req, err := t.db.client.Query(&q)
+		if err != nil {
+			err = wrapError("Query", err)
+			t.notifyThrottle("Query", nil, err)
+			log.Error("[DynamoDB] Error in `Query` operation, table="+t.name, err)
+			return nil, nil, false, err
+		}
+		next, done := nextPageKey(req.LastEvaluatedKey)
+		return req.Items, next, done, nil
+	})
+}
+
+// ScanAll gets mapped-items with Scan operation until the last page,
+// returns up to maxItems items with ErrResultTruncated when more items may exist (maxItems=0 means no limit)
+func (t *DynamoTable) ScanAll(maxItems int) ([]map[string]interface{}, error) {
+	if err := t.guardScan("ScanAll"); err != nil {
+		return nil, err
+	}
+	in := &SDK.ScanInput{
+		TableName:              String(t.name),
+		ReturnConsumedCapacity: t.returnCapacity(nil),
+	}
+	return t.collectPages(maxItems, func(token interface{}) ([]*map[string]*SDK.AttributeValue, interface{}, bool, error) {
+		in.ExclusiveStartKey = startKey(token)
+		req, err := t.db.client.Scan(in)
+		if err != nil {
+			err = wrapError("Scan", err)
+			t.notifyThrottle("Scan", nil, err)
+			log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
+			return nil, nil, false, err
+		}
+		next, done := nextPageKey(req.LastEvaluatedKey)
+		return req.Items, next, done, nil
+	})
+}
+
+// collect mapped-items from the pages until the last page or the max items,
+// the paging stops at the max items and ErrResultTruncated is returned when the page is not the last
+func (t *DynamoTable) collectPages(maxItems int, fetch func(token interface{}) ([]*map[string]*SDK.AttributeValue, interface{}, bool, error)) ([]map[string]interface{}, error) {
+	var items []map[string]interface{}
+	truncated := false
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		page, next, done, err := fetch(token)
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, t.ConvertItemsToMapArray(page)...)
+		if maxItems > 0 && len(items) >= maxItems && (len(items) > maxItems || !done) {
+			items = items[:maxItems]
+			truncated = true
+			return nil, true, nil
+		}
+		return next, done, nil
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case truncated:
+		return items, ErrResultTruncated
+	}
+	return items, nil
+}
+
+// QueryChan performs Query operation with paging in background,
+// and sends mapped-items to the item channel until the last page or the context is done
+func (t *DynamoTable) QueryChan(ctx context.Context, in *SDK.QueryInput) (<-chan map[string]interface{}, <-chan error) {
+	items := make(chan map[string]interface{})
+	errs := make(chan error, 1)
+	go func() {
+		defer close(items)
+		defer close(errs)
+
+		q := *in
+		t.applyQueryDefaults(&q)
+		err := pager.PaginateContext(ctx, func(token interface{}) (interface{}, bool, error) {
+			if token != nil {
+				q.ExclusiveStartKey = startKey(token)
+			}
+			req, err := t.db.client.Query(&q)
+			if err != nil {
+				err = wrapError("Query", err)
+				log.Error("[DynamoDB] Error in `Query` operation, table="+t.name, err)
+				return nil, false, err
+			}
+			for _, item := range req.Items {
+				select {
+				case items <- t.options().unmarshal(item):
+				case <-ctx.Done():
+					return nil, false, ctx.Err()
+				}
+			}
+			next, done := nextPageKey(req.LastEvaluatedKey)
+			return next, done, nil
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return items, errs
+}
+
+// get the page token from LastEvaluatedKey, returns true when it's the last page
+func nextPageKey(key *map[string]*SDK.AttributeValue) (interface{}, bool) {
+	if key == nil || len(*key) == 0 {
+		return nil, true
+	}
+	return key, false
+}
+
+// get ExclusiveStartKey from the page token
+func startKey(token interface{}) *map[string]*SDK.AttributeValue {
+	key, _ := token.(*map[string]*SDK.AttributeValue)
+	return key
+}
+
 // get mapped-items with Scan operation
 func (t *DynamoTable) Scan() ([]map[string]interface{}, error) {
+	if err := t.guardScan("Scan"); err != nil {
+		return nil, err
+	}
 	in := &SDK.ScanInput{
-		TableName: String(t.name),
-		Limit:     Long(1000),
+		TableName:              String(t.name),
+		Limit:                  Long(1000),
+		ReturnConsumedCapacity: t.returnCapacity(nil),
 	}
 	req, err := t.db.client.Scan(in)
 	if err != nil {
+		err = wrapError("Scan", err)
+		t.notifyThrottle("Scan", nil, err)
 		log.Error("[DynamoDB] Error in `Scan` operation, table="+t.name, err)
 		return nil, err
 	}
@@ -174,29 +936,313 @@ func (t *DynamoTable) Scan() ([]map[string]interface{}, error) {
 
 // delete item
 func (t *DynamoTable) Delete(values ...Any) error {
-	key := NewItem()
-	key.AddAttribute(t.GetHashKeyName(), values[0])
-	if len(values) > 1 && t.GetRangeKeyName() != "" {
-		key.AddAttribute(t.GetRangeKeyName(), values[1])
-	}
+	_, err := t.DeleteWithResult(values...)
+	return err
+}
 
+// DeleteWithResult deletes item same as Delete,
+// and returns the item collection metrics when ReturnItemCollectionMetrics is set (nil when it's not returned)
+func (t *DynamoTable) DeleteWithResult(values ...Any) (*ItemCollectionMetrics, error) {
+	k, err := t.marshalKey(t.primaryKey(values))
+	if err != nil {
+		return nil, err
+	}
 	in := &SDK.DeleteItemInput{
 		TableName: String(t.name),
-		Key:       &key.data,
+		Key:       k,
+	}
+	return t.deleteItem(in)
+}
+
+// delete item only when the condition is satisfied, returns ErrConditionFailed when it's not satisfied.
+// the condition must not be empty, use Delete to delete the item unconditionally
+func (t *DynamoTable) DeleteItemIf(key map[string]interface{}, cond *FilterBuilder) error {
+	switch {
+	case cond == nil || len(cond.conditions) == 0:
+		return errors.New("[DynamoDB] condition is required for DeleteItemIf, table=" + t.name)
+	case cond.Error() != nil:
+		log.Error("[DynamoDB] Error on building ConditionExpression, table="+t.name, cond.Error())
+		return cond.Error()
 	}
-	_, err := t.db.client.DeleteItem(in)
+	k, err := t.marshalKey(key)
 	if err != nil {
+		return err
+	}
+	in := &SDK.DeleteItemInput{
+		TableName:                 String(t.name),
+		Key:                       k,
+		ConditionExpression:       String(cond.Expression()),
+		ExpressionAttributeNames:  cond.attrs.expressionNames(),
+		ExpressionAttributeValues: cond.attrs.expressionValues(),
+	}
+	_, err = t.deleteItem(in)
+	return err
+}
+
+// execute DeleteItem operation and returns the item collection metrics
+func (t *DynamoTable) deleteItem(in *SDK.DeleteItemInput) (*ItemCollectionMetrics, error) {
+	if t.returnItemCollectionMetrics {
+		in.ReturnItemCollectionMetrics = String("SIZE")
+	}
+	in.ReturnConsumedCapacity = t.returnCapacity(in.ReturnConsumedCapacity)
+	res, err := t.db.client.DeleteItem(in)
+	t.invalidateCache(in.Key)
+	err = wrapError("DeleteItem", err)
+	t.notifyThrottle("DeleteItem", in.Key, err)
+	switch {
+	case isConditionalCheckFailed(err):
+		return nil, ErrConditionFailed
+	case err != nil:
 		log.Error("[DynamoDB] Error in `DeleteItem` operation, table="+t.name, err)
+		return nil, err
+	}
+	return t.newItemCollectionMetrics(res.ItemCollectionMetrics), nil
+}
+
+// update item with the UpdateExpression of the builder
+func (t *DynamoTable) UpdateItem(key map[string]interface{}, b *UpdateBuilder) error {
+	_, err := t.UpdateItemWithResult(key, b)
+	return err
+}
+
+// UpdateItemWithResult updates item same as UpdateItem,
+// and returns the item collection metrics when ReturnItemCollectionMetrics is set (nil when it's not returned)
+func (t *DynamoTable) UpdateItemWithResult(key map[string]interface{}, b *UpdateBuilder) (*ItemCollectionMetrics, error) {
+	if b.Error() != nil {
+		log.Error("[DynamoDB] Error on building UpdateExpression, table="+t.name, b.Error())
+		return nil, b.Error()
+	}
+	k, err := t.marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	in := b.newUpdateItemInput(t.name, k)
+	res, err := t.updateItem(in)
+	if err != nil {
+		return nil, err
+	}
+	return t.newItemCollectionMetrics(res.ItemCollectionMetrics), nil
+}
+
+// UpdateItemIf updates item with the UpdateExpression of the builder only when the condition is satisfied,
+// the condition must be created by NewCondition of the builder. returns ErrConditionFailed when the condition is not satisfied.
+// the condition must not be empty, use UpdateItem to update the item unconditionally
+func (t *DynamoTable) UpdateItemIf(key map[string]interface{}, b *UpdateBuilder, cond *FilterBuilder) error {
+	switch {
+	case cond == nil || len(cond.conditions) == 0:
+		return errors.New("[DynamoDB] condition is required for UpdateItemIf, table=" + t.name)
+	case b.Error() != nil:
+		log.Error("[DynamoDB] Error on building UpdateExpression, table="+t.name, b.Error())
+		return b.Error()
+	case cond.Error() != nil:
+		log.Error("[DynamoDB] Error on building ConditionExpression, table="+t.name, cond.Error())
+		return cond.Error()
+	case cond.attrs != b.attrs:
+		return errors.New("[DynamoDB] the condition must be created by NewCondition of the builder, table=" + t.name)
+	}
+	k, err := t.marshalKey(key)
+	if err != nil {
 		return err
 	}
-	return nil
+	in := b.newUpdateItemInput(t.name, k)
+	in.ConditionExpression = String(cond.Expression())
+	_, err = t.updateItem(in)
+	return err
+}
+
+// Merge updates the attributes of the item and keeps the other attributes,
+// the item is created when it does not exist (key attributes in updates are ignored)
+func (t *DynamoTable) Merge(key map[string]interface{}, updates map[string]interface{}) error {
+	return t.UpdateItem(key, t.options().newMergeBuilder(updates, t.keyNames()))
+}
+
+// MergeReturn performs Merge and returns all of the attributes of the item after the update
+func (t *DynamoTable) MergeReturn(key map[string]interface{}, updates map[string]interface{}) (map[string]interface{}, error) {
+	b := t.options().newMergeBuilder(updates, t.keyNames())
+	if b.Error() != nil {
+		log.Error("[DynamoDB] Error on building UpdateExpression, table="+t.name, b.Error())
+		return nil, b.Error()
+	}
+	k, err := t.marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	in := b.newUpdateItemInput(t.name, k)
+	in.ReturnValues = String(ReturnValuesAllNew)
+	res, err := t.updateItem(in)
+	if err != nil {
+		return nil, err
+	}
+	return t.options().unmarshal(res.Attributes), nil
+}
+
+// AddToNumberIfBelow adds delta to the number attribute only when the current value is below the limit or the attribute does not exist,
+// returns the new value and false when the condition is not satisfied.
+// (the condition is checked on the value before the addition)
+func (t *DynamoTable) AddToNumberIfBelow(key map[string]interface{}, attr string, delta, limit int64) (newVal int64, ok bool, err error) {
+	b := t.NewUpdateBuilder()
+	b.Add(attr, delta)
+	if b.Error() != nil {
+		log.Error("[DynamoDB] Error on building UpdateExpression, table="+t.name, b.Error())
+		return 0, false, b.Error()
+	}
+
+	// ADD takes the attribute name as it is, so the condition uses the same name
+	cond := b.NewCondition()
+	notExists := NewSharedFilterBuilder(cond)
+	notExists.AddNotExists(Literal(attr))
+	below := NewSharedFilterBuilder(cond)
+	below.AddLT(Literal(attr), limit)
+	cond.Or(notExists, below)
+	if cond.Error() != nil {
+		log.Error("[DynamoDB] Error on building ConditionExpression, table="+t.name, cond.Error())
+		return 0, false, cond.Error()
+	}
+
+	k, err := t.marshalKey(key)
+	if err != nil {
+		return 0, false, err
+	}
+	in := b.newUpdateItemInput(t.name, k)
+	in.ConditionExpression = String(cond.Expression())
+	in.ReturnValues = String(ReturnValuesUpdatedNew)
+	res, err := t.updateItem(in)
+	switch {
+	case err == ErrConditionFailed:
+		return 0, false, nil
+	case err != nil:
+		return 0, false, err
+	}
+
+	if res.Attributes != nil {
+		if v, ok := (*res.Attributes)[attr]; ok && v.N != nil {
+			newVal, err = strconv.ParseInt(*v.N, 10, 64)
+		}
+	}
+	return newVal, true, err
+}
+
+// execute UpdateItem operation
+func (t *DynamoTable) updateItem(in *SDK.UpdateItemInput) (*SDK.UpdateItemOutput, error) {
+	if t.returnItemCollectionMetrics {
+		in.ReturnItemCollectionMetrics = String("SIZE")
+	}
+	in.ReturnConsumedCapacity = t.returnCapacity(in.ReturnConsumedCapacity)
+	if t.strictKeys {
+		if err := t.validateKeyAttributes(in.Key); err != nil {
+			log.Error("[DynamoDB] Error on key validation, table="+t.name, err)
+			return nil, err
+		}
+	}
+	res, err := t.db.client.UpdateItem(in)
+	t.invalidateCache(in.Key)
+	err = wrapError("UpdateItem", err)
+	t.notifyThrottle("UpdateItem", in.Key, err)
+	switch {
+	case isConditionalCheckFailed(err):
+		return nil, ErrConditionFailed
+	case err != nil:
+		log.Error("[DynamoDB] Error in `UpdateItem` operation, table="+t.name, err)
+		return nil, err
+	}
+	return res, nil
+}
+
+// create key map from the values of (HashKey [, RangeKey])
+func (t *DynamoTable) primaryKey(values []Any) map[string]interface{} {
+	key := map[string]interface{}{
+		t.GetHashKeyName(): values[0],
+	}
+	if len(values) > 1 && t.GetRangeKeyName() != "" {
+		key[t.GetRangeKeyName()] = values[1]
+	}
+	return key
+}
+
+// convert key map to DynamoDB Item data by the attribute types of the table schema
+func (t *DynamoTable) marshalKey(key map[string]interface{}) (*map[string]*SDK.AttributeValue, error) {
+	data := make(map[string]*SDK.AttributeValue, len(key))
+	for name, v := range key {
+		av, err := t.keyAttributeValue(name, v)
+		if err != nil {
+			return nil, err
+		}
+		data[name] = av
+	}
+	return &data, nil
+}
+
+// create AttributeValue of the key by the attribute type of the table schema,
+// string value for binary key is treated as base64 encoded data and returns error for the invalid base64 string
+func (t *DynamoTable) keyAttributeValue(name string, v Any) (*SDK.AttributeValue, error) {
+	switch t.attributeType(name) {
+	case "B":
+		if s, ok := v.(string); ok {
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("[DynamoDB] invalid base64 string for the binary key, table=%s, name=%s, error=%s", t.name, name, err.Error())
+			}
+			return &SDK.AttributeValue{
+				B: b,
+			}, nil
+		}
+	case "S":
+		if b, ok := v.([]byte); ok {
+			return &SDK.AttributeValue{
+				S: String(string(b)),
+			}, nil
+		}
+	case "N":
+		if s, ok := v.(string); ok {
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return &SDK.AttributeValue{
+					N: String(s),
+				}, nil
+			}
+		}
+	}
+	return t.options().createAttributeValue(v), nil
+}
+
+// get the current time from the clock of the client, the table without the client uses the system clock
+func (t *DynamoTable) currentTime() time.Time {
+	if t.db == nil {
+		return time.Now()
+	}
+	return t.db.currentTime()
+}
+
+// get the marshal options of the client, the table without the client uses the default options
+func (t *DynamoTable) options() *marshalOptions {
+	if t.db == nil {
+		return defaultMarshalOptions
+	}
+	return t.db.options()
+}
+
+// get the type of the attribute from the table schema
+func (t *DynamoTable) attributeType(name string) string {
+	if t.table == nil {
+		return ""
+	}
+	for _, def := range t.table.AttributeDefinitions {
+		if def.AttributeName != nil && *def.AttributeName == name && def.AttributeType != nil {
+			return *def.AttributeType
+		}
+	}
+	return ""
+}
+
+// check if the error is caused by the condition of the conditional write
+func isConditionalCheckFailed(err error) bool {
+	return awserror.Code(err) == errCodeConditionalCheckFailed
 }
 
 // convert from dynamodb values to map
 func (t *DynamoTable) ConvertItemsToMapArray(items []*map[string]*SDK.AttributeValue) []map[string]interface{} {
 	var m []map[string]interface{}
 	for _, item := range items {
-		m = append(m, Unmarshal(item))
+		m = append(m, t.options().unmarshal(item))
 	}
 	return m
 }
@@ -215,6 +1261,64 @@ func (t *DynamoTable) GetRangeKeyName() string {
 	}
 }
 
+// get the names of primary keys
+func (t *DynamoTable) keyNames() []string {
+	names := []string{t.GetHashKeyName()}
+	if rangeKey := t.GetRangeKeyName(); rangeKey != "" {
+		names = append(names, rangeKey)
+	}
+	return names
+}
+
+// check if the item has all of the key attributes with the types in the attribute definitions
+func (t *DynamoTable) validateKeyAttributes(item *map[string]*SDK.AttributeValue) error {
+	var attrs map[string]*SDK.AttributeValue
+	if item != nil {
+		attrs = *item
+	}
+	for _, name := range t.keyNames() {
+		v, ok := attrs[name]
+		if !ok || v == nil {
+			return errors.New("[DynamoDB] missing key attribute, table=" + t.name + ", name=" + name)
+		}
+		expected := t.attributeType(name)
+		if expected == "" {
+			continue
+		}
+		if actual := keyAttributeType(v); actual != expected {
+			return errors.New("[DynamoDB] mistyped key attribute, table=" + t.name + ", name=" + name + ", expected=" + expected + ", actual=" + actual)
+		}
+	}
+	return nil
+}
+
+// get the type of the attribute value to compare with the attribute definition
+func keyAttributeType(v *SDK.AttributeValue) string {
+	switch {
+	case v.S != nil:
+		return "S"
+	case v.N != nil:
+		return "N"
+	case v.B != nil:
+		return "B"
+	case v.BOOL != nil:
+		return "BOOL"
+	case v.NULL != nil:
+		return "NULL"
+	case v.SS != nil:
+		return "SS"
+	case v.NS != nil:
+		return "NS"
+	case v.BS != nil:
+		return "BS"
+	case v.L != nil:
+		return "L"
+	case v.M != nil:
+		return "M"
+	}
+	return "unknown"
+}
+
 // check if exists all primary keys in the item to write it.
 func (t *DynamoTable) isExistPrimaryKeys(item *SDK.PutItemInput) bool {
 	hashKey := t.GetHashKeyName()
@@ -232,34 +1336,3 @@ func (t *DynamoTable) isExistPrimaryKeys(item *SDK.PutItemInput) bool {
 	}
 	return true
 }
-
-// [CAUTION]
-// only used this for developing, this performs scan all item and delete it each one by one
-func (t *DynamoTable) DeleteAll() error {
-	hashkey := t.GetHashKeyName()
-	rangekey := t.GetRangeKeyName()
-
-	result, err := t.Scan()
-	if err != nil {
-		return err
-	}
-
-	errStr := ""
-	for _, item := range result {
-		var e error
-		if rangekey != "" {
-			e = t.Delete(item[hashkey], item[rangekey])
-		} else {
-			e = t.Delete(item[hashkey])
-		}
-		if e != nil {
-			errStr = errStr + "," + e.Error()
-		}
-	}
-
-	if errStr != "" {
-		err = errors.New(errStr)
-	}
-
-	return err
-}
@@ -1,7 +1,14 @@
 package dynamodb
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
 	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
 )
 
 func TestAddItem(t *testing.T) {
@@ -57,6 +64,64 @@ func TestPut(t *testing.T) {
 	}
 }
 
+func TestReturnItemCollectionMetrics(t *testing.T) {
+	tbl := getTestTable()
+	tbl.ReturnItemCollectionMetrics(true)
+	defer tbl.ReturnItemCollectionMetrics(false)
+
+	item := NewItem()
+	item.AddAttribute("id", 100)
+	item.AddAttribute("time", 1)
+	item.AddAttribute("lsi_key", "lsi_value")
+	tbl.AddItem(item)
+	if len(tbl.writeItems) != 1 || tbl.writeItems[0].ReturnItemCollectionMetrics == nil || *tbl.writeItems[0].ReturnItemCollectionMetrics != "SIZE" {
+		t.Fatalf("error on ReturnItemCollectionMetrics, %v", tbl.writeItems)
+	}
+	metrics, err := tbl.PutWithResult()
+	if err != nil {
+		t.Errorf("error on PutWithResult, %s", err.Error())
+	}
+	if len(metrics) != 1 || metrics[0].Key["id"] != 100 {
+		t.Fatalf("error on PutWithResult, %v", metrics)
+	}
+	if len(metrics[0].SizeEstimateRangeGB) != 2 {
+		t.Errorf("error on PutWithResult, %v", metrics[0])
+	}
+
+	b := NewUpdateBuilder()
+	b.Set("name", "foo")
+	m, err := tbl.UpdateItemWithResult(map[string]interface{}{"id": 100, "time": 1}, b)
+	if err != nil {
+		t.Errorf("error on UpdateItemWithResult, %s", err.Error())
+	}
+	if m == nil || m.Key["id"] != 100 {
+		t.Errorf("error on UpdateItemWithResult, %v", m)
+	}
+
+	m, err = tbl.DeleteWithResult(100, 1)
+	if err != nil {
+		t.Errorf("error on DeleteWithResult, %s", err.Error())
+	}
+	if m == nil || m.Key["id"] != 100 {
+		t.Errorf("error on DeleteWithResult, %v", m)
+	}
+}
+
+func TestNewItemCollectionMetrics(t *testing.T) {
+	tbl := getTestCacheTable()
+	if m := tbl.newItemCollectionMetrics(nil); m != nil {
+		t.Errorf("error on newItemCollectionMetrics, %v", m)
+	}
+	lo, hi := 0.5, 1.0
+	m := tbl.newItemCollectionMetrics(&SDK.ItemCollectionMetrics{
+		ItemCollectionKey:   Marshal(map[string]interface{}{"id": 100}),
+		SizeEstimateRangeGB: []*float64{&lo, &hi},
+	})
+	if m == nil || m.Key["id"] != 100 || len(m.SizeEstimateRangeGB) != 2 || m.SizeEstimateRangeGB[1] != 1.0 {
+		t.Errorf("error on newItemCollectionMetrics, %v", m)
+	}
+}
+
 func TestGetOne(t *testing.T) {
 	tbl := getTestTable()
 	putTestTable(tbl, 100, 1)
@@ -151,6 +216,354 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestQueryWithExpression(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 5; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	keyCond := NewFilterBuilder()
+	keyCond.AddEQ("id", 100)
+	keyCond.AddGE("time", 2)
+	filter := NewSharedFilterBuilder(keyCond)
+	filter.AddEQ("lsi_key", "lsi_value")
+	filter.AddLT("time", 5)
+
+	results, err := tbl.QueryWithExpression(keyCond, filter)
+	if err != nil {
+		t.Errorf("error on QueryWithExpression, %s", err.Error())
+	}
+	if len(results) != 3 {
+		t.Errorf("error on QueryWithExpression, %v", results)
+	}
+}
+
+func TestQueryPrefix(t *testing.T) {
+	tbl := getTestStringRangeTable()
+	tbl.DeleteAll()
+	for _, name := range []string{"foo1", "foo2", "bar1"} {
+		item := NewItem()
+		item.AddAttribute("id", 100)
+		item.AddAttribute("name", name)
+		tbl.AddItem(item)
+	}
+	tbl.Put()
+
+	results, err := tbl.QueryPrefix(100, "name", "foo")
+	if err != nil {
+		t.Errorf("error on QueryPrefix, %s", err.Error())
+	}
+	if len(results) != 2 {
+		t.Errorf("error on QueryPrefix, %v", results)
+	}
+	for _, r := range results {
+		if !strings.HasPrefix(r["name"].(string), "foo") {
+			t.Errorf("error on QueryPrefix, %v", r)
+		}
+	}
+}
+
+func TestQueryBetween(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 5; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	results, err := tbl.QueryBetween(100, "time", 2, 4)
+	if err != nil {
+		t.Errorf("error on QueryBetween, %s", err.Error())
+	}
+	if len(results) != 3 {
+		t.Errorf("error on QueryBetween, %v", results)
+	}
+
+	if _, err := tbl.QueryBetween(100, "time", 4, 2); err == nil {
+		t.Errorf("error on QueryBetween, reversed range is accepted")
+	}
+}
+
+func TestValidateBetween(t *testing.T) {
+	valid := [][2]interface{}{
+		{1, 2},
+		{2, 2},
+		{1.5, int64(2)},
+		{"2015-01-01", "2015-12-31"},
+		{[]byte{0x01}, []byte{0x02}},
+		{0.001, 0.01},
+	}
+	for _, v := range valid {
+		if err := defaultMarshalOptions.validateBetween(v[0], v[1]); err != nil {
+			t.Errorf("error on validateBetween, %v, %s", v, err.Error())
+		}
+	}
+
+	invalid := [][2]interface{}{
+		{2, 1},
+		{"b", "a"},
+		{[]byte{0x02}, []byte{0x01}},
+		{1, "2"},
+		{true, false},
+	}
+	for _, v := range invalid {
+		if err := defaultMarshalOptions.validateBetween(v[0], v[1]); err == nil {
+			t.Errorf("error on validateBetween, %v is accepted", v)
+		}
+	}
+}
+
+func TestQueryGrouped(t *testing.T) {
+	tbl := getTestStringRangeTable()
+	tbl.DeleteAll()
+	for _, name := range []string{"USER#1", "USER#2", "ORDER#1"} {
+		item := NewItem()
+		item.AddAttribute("id", 100)
+		item.AddAttribute("name", name)
+		tbl.AddItem(item)
+	}
+	tbl.Put()
+
+	groups, err := tbl.QueryGrouped(100, "name")
+	if err != nil {
+		t.Errorf("error on QueryGrouped, %s", err.Error())
+	}
+	if len(groups) != 2 || len(groups["USER"]) != 2 || len(groups["ORDER"]) != 1 {
+		t.Errorf("error on QueryGrouped, %v", groups)
+	}
+}
+
+func TestGroupBySortPrefix(t *testing.T) {
+	items := []map[string]interface{}{
+		{"sk": "USER#1"},
+		{"sk": "USER#2#a"},
+		{"sk": "ORDER#1"},
+		{"sk": "META"},
+		{"sk": 10},
+		{"other": "x"},
+	}
+	groups := groupBySortPrefix(items, "sk")
+	expected := map[string]int{
+		"USER":  2,
+		"ORDER": 1,
+		"META":  1,
+		"10":    1,
+		"":      1,
+	}
+	if len(groups) != len(expected) {
+		t.Errorf("error on groupBySortPrefix, %v", groups)
+	}
+	for prefix, n := range expected {
+		if len(groups[prefix]) != n {
+			t.Errorf("error on groupBySortPrefix, prefix=%s, %v", prefix, groups[prefix])
+		}
+	}
+}
+
+func TestQueryDesc(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 5; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	results, err := tbl.QueryDesc(100, 2)
+	if err != nil {
+		t.Errorf("error on QueryDesc, %s", err.Error())
+	}
+	if len(results) != 2 || results[0]["time"] != 5 || results[1]["time"] != 4 {
+		t.Errorf("error on QueryDesc, %v", results)
+	}
+
+	results, err = tbl.QueryDesc(100, 0)
+	if err != nil {
+		t.Errorf("error on QueryDesc, %s", err.Error())
+	}
+	if len(results) != 5 || results[0]["time"] != 5 || results[4]["time"] != 1 {
+		t.Errorf("error on QueryDesc, %v", results)
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 10; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	keyCond := NewFilterBuilder()
+	keyCond.AddEQ("id", 100)
+	filter := NewSharedFilterBuilder(keyCond)
+	filter.AddGT("time", 3)
+	in, _ := newExpressionQueryInput(tbl.name, keyCond, filter)
+	in.Limit = Long(2)
+
+	results, err := tbl.QueryLimit(in, 4)
+	if err != nil {
+		t.Errorf("error on QueryLimit, %s", err.Error())
+	}
+	if len(results) != 4 || results[0]["time"] != 4 || results[3]["time"] != 7 {
+		t.Errorf("error on QueryLimit, %v", results)
+	}
+	if in.ExclusiveStartKey != nil {
+		t.Errorf("error on QueryLimit, input must not be changed, %v", in)
+	}
+
+	// exhausted
+	results, err = tbl.QueryLimit(in, 100)
+	if err != nil {
+		t.Errorf("error on QueryLimit, %s", err.Error())
+	}
+	if len(results) != 7 {
+		t.Errorf("error on QueryLimit, %v", results)
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 5; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	keyCond := NewFilterBuilder()
+	keyCond.AddEQ("id", 100)
+	in, _ := newExpressionQueryInput(tbl.name, keyCond, nil)
+	in.Limit = Long(2)
+
+	results, err := tbl.QueryAll(in, 0)
+	if err != nil || len(results) != 5 {
+		t.Errorf("error on QueryAll, results=%v, err=%v", results, err)
+	}
+
+	results, err = tbl.QueryAll(in, 3)
+	if err != ErrResultTruncated || len(results) != 3 {
+		t.Errorf("error on QueryAll, results=%v, err=%v", results, err)
+	}
+
+	results, err = tbl.QueryAll(in, 10)
+	if err != nil || len(results) != 5 {
+		t.Errorf("error on QueryAll, results=%v, err=%v", results, err)
+	}
+}
+
+func TestScanAll(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i := 1; i <= 5; i++ {
+		putTestTable(tbl, 100, i)
+	}
+
+	results, err := tbl.ScanAll(0)
+	if err != nil || len(results) != 5 {
+		t.Errorf("error on ScanAll, results=%v, err=%v", results, err)
+	}
+
+	results, err = tbl.ScanAll(2)
+	if err != ErrResultTruncated || len(results) != 2 {
+		t.Errorf("error on ScanAll, results=%v, err=%v", results, err)
+	}
+}
+
+func TestQueryIndex(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+	putTestTable(tbl, 101, 1)
+
+	keyCond := NewFilterBuilder()
+	keyCond.AddEQ("time", 1)
+	in, _ := newExpressionQueryInput(tbl.name, keyCond, nil)
+	results, err := tbl.QueryIndex("gsi-index", in)
+	if err != nil {
+		t.Errorf("error on QueryIndex, %s", err.Error())
+	}
+	if len(results) != 2 {
+		t.Errorf("error on QueryIndex, %v", results)
+	}
+	if in.IndexName != nil {
+		t.Errorf("error on QueryIndex, input must not be changed, %v", in)
+	}
+
+	_, err = tbl.QueryIndex("unknown-index", in)
+	if err == nil {
+		t.Errorf("error on QueryIndex, unknown index must return error")
+	}
+
+	in.ConsistentRead = Boolean(true)
+	_, err = tbl.QueryIndex("gsi-index", in)
+	if err == nil {
+		t.Errorf("error on QueryIndex, ConsistentRead on GSI must return error")
+	}
+}
+
+func TestQueryIndexPrefix(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	for i, lsiKey := range []string{"foo1", "foo2", "bar1"} {
+		item := NewItem()
+		item.AddAttribute("id", 100)
+		item.AddAttribute("time", i)
+		item.AddAttribute("lsi_key", lsiKey)
+		tbl.AddItem(item)
+	}
+	tbl.Put()
+
+	results, err := tbl.QueryIndexPrefix("lsi-index", 100, "foo")
+	if err != nil {
+		t.Errorf("error on QueryIndexPrefix, %s", err.Error())
+	}
+	if len(results) != 2 {
+		t.Errorf("error on QueryIndexPrefix, %v", results)
+	}
+	for _, r := range results {
+		if !strings.HasPrefix(r["lsi_key"].(string), "foo") {
+			t.Errorf("error on QueryIndexPrefix, %v", r)
+		}
+	}
+}
+
+func TestQueryChan(t *testing.T) {
+	tbl := getTestTable()
+	putTestTable(tbl, 100, 1)
+	putTestTable(tbl, 100, 2)
+
+	keys := map[string]*SDK.Condition{
+		"id": &SDK.Condition{
+			AttributeValueList: []*SDK.AttributeValue{createAttributeValue(100)},
+			ComparisonOperator: String(ComparisonOperatorEQ),
+		},
+	}
+	in := &SDK.QueryInput{
+		TableName:     String(tbl.name),
+		KeyConditions: &keys,
+		Limit:         Long(1),
+	}
+	items, errs := tbl.QueryChan(context.Background(), in)
+	var results []map[string]interface{}
+	for item := range items {
+		results = append(results, item)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("error on QueryChan, %s", err.Error())
+	}
+	if len(results) != 2 || results[0]["id"] != 100 {
+		t.Errorf("error on QueryChan, %v", results)
+	}
+	if in.ExclusiveStartKey != nil {
+		t.Errorf("error on QueryChan, input is modified: %v", in)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	items, errs = tbl.QueryChan(ctx, in)
+	for range items {
+	}
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("error on QueryChan, %v", err)
+	}
+}
+
 func TestScan(t *testing.T) {
 	tbl := getTestTable()
 	putTestTable(tbl, 100, 1)
@@ -167,6 +580,92 @@ func TestScan(t *testing.T) {
 	}
 }
 
+func TestUpdateItem(t *testing.T) {
+	tbl := getTestTable()
+	putTestTable(tbl, 100, 1)
+
+	key := map[string]interface{}{"id": 100, "time": 1}
+	b := NewUpdateBuilder()
+	b.SetIfNotExists("created", 10)
+	b.Add("count", 1)
+	err := tbl.UpdateItem(key, b)
+	if err != nil {
+		t.Errorf("error on UpdateItem, %s", err.Error())
+	}
+
+	b = NewUpdateBuilder()
+	b.SetIfNotExists("created", 20)
+	b.Add("count", 1)
+	err = tbl.UpdateItem(key, b)
+	if err != nil {
+		t.Errorf("error on UpdateItem, %s", err.Error())
+	}
+
+	result, err := tbl.GetOne(100, 1)
+	if err != nil {
+		t.Errorf("error on GetOne, %s", err.Error())
+	}
+	if result["created"] != 10 || result["count"] != 2 {
+		t.Errorf("error on UpdateItem, %v", result)
+	}
+}
+
+func TestUpdateItemIf(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+
+	// append only if the list has fewer than 100 elements
+	key := map[string]interface{}{"id": 100, "time": 1}
+	appendItem := func(i int) error {
+		b := NewUpdateBuilder()
+		b.AppendToList("items", []interface{}{i})
+		cond := b.NewCondition()
+		notExists := NewSharedFilterBuilder(cond)
+		notExists.AddNotExists("items")
+		below := NewSharedFilterBuilder(cond)
+		below.AddSize("items", ComparisonOperatorLT, 100)
+		cond.Or(notExists, below)
+		return tbl.UpdateItemIf(key, b, cond)
+	}
+	for i := 1; i <= 100; i++ {
+		if err := appendItem(i); err != nil {
+			t.Errorf("error on UpdateItemIf, i=%d, %s", i, err.Error())
+		}
+	}
+	if err := appendItem(101); err != ErrConditionFailed {
+		t.Errorf("error on UpdateItemIf, the 101st append must be rejected, %v", err)
+	}
+
+	result, err := tbl.GetOne(100, 1)
+	if err != nil {
+		t.Errorf("error on GetOne, %s", err.Error())
+	}
+	if items, _ := result["items"].([]interface{}); len(items) != 100 {
+		t.Errorf("error on UpdateItemIf, %d", len(items))
+	}
+
+	// the condition of the other builder
+	other := NewFilterBuilder()
+	other.AddExists("items")
+	if err := tbl.UpdateItemIf(key, NewUpdateBuilder(), other); err == nil {
+		t.Errorf("error on UpdateItemIf, the condition of the other builder is accepted")
+	}
+}
+
+func TestUpdateItemIfWithoutCondition(t *testing.T) {
+	tbl := getTestCacheTable()
+	key := map[string]interface{}{"id": 100, "time": 1}
+	b := NewUpdateBuilder()
+	b.Set("name", "foo")
+	for i, cond := range []*FilterBuilder{nil, b.NewCondition()} {
+		err := tbl.UpdateItemIf(key, b, cond)
+		if err == nil || !strings.Contains(err.Error(), "[DynamoDB] condition is required") {
+			t.Errorf("error on UpdateItemIf, error must be returned without condition, #%d, %v", i, err)
+		}
+	}
+}
+
 func TestDelete(t *testing.T) {
 	tbl := getTestTable()
 	putTestTable(tbl, 100, 1)
@@ -190,6 +689,296 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestMerge(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+
+	key := map[string]interface{}{"id": 100, "time": 1}
+	err := tbl.Merge(key, map[string]interface{}{"id": 999, "name": "foo"})
+	if err != nil {
+		t.Errorf("error on Merge, %s", err.Error())
+	}
+	result, _ := tbl.GetOne(100, 1)
+	if result["name"] != "foo" || result["lsi_key"] != "lsi_value" {
+		t.Errorf("error on Merge, %v", result)
+	}
+
+	// create new item
+	err = tbl.Merge(map[string]interface{}{"id": 100, "time": 2}, map[string]interface{}{"name": "bar"})
+	if err != nil {
+		t.Errorf("error on Merge, %s", err.Error())
+	}
+	result, _ = tbl.GetOne(100, 2)
+	if result["name"] != "bar" {
+		t.Errorf("error on Merge, %v", result)
+	}
+}
+
+func TestGetAttribute(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+
+	v, err := tbl.GetAttribute(map[string]interface{}{"id": 100, "time": 1}, "lsi_key")
+	if err != nil || v != "lsi_value" {
+		t.Errorf("error on GetAttribute, v=%v, err=%v", v, err)
+	}
+
+	// absent attribute
+	v, err = tbl.GetAttribute(map[string]interface{}{"id": 100, "time": 1}, "status")
+	if err != nil || v != nil {
+		t.Errorf("error on GetAttribute, v=%v, err=%v", v, err)
+	}
+
+	// absent item
+	v, err = tbl.GetAttribute(map[string]interface{}{"id": 100, "time": 999}, "lsi_key")
+	if err != nil || v != nil {
+		t.Errorf("error on GetAttribute, v=%v, err=%v", v, err)
+	}
+}
+
+func TestGetItemLive(t *testing.T) {
+	tbl := getTestTable()
+	now := time.Unix(1435000000, 0)
+	tbl.db.setNow(func() time.Time { return now })
+	defer tbl.db.setNow(time.Now)
+	for i, ttl := range []int64{now.Unix() + 1, now.Unix() - 1} {
+		item := NewItem()
+		item.AddAttribute("id", 100)
+		item.AddAttribute("time", i+1)
+		item.AddAttribute("expires_at", ttl)
+		tbl.AddItem(item)
+	}
+	tbl.Put()
+
+	result, ok, err := tbl.GetItemLive(map[string]interface{}{"id": 100, "time": 1}, "expires_at")
+	if err != nil || !ok || result["id"] != 100 {
+		t.Errorf("error on GetItemLive, ok=%v, result=%v, err=%v", ok, result, err)
+	}
+
+	result, ok, err = tbl.GetItemLive(map[string]interface{}{"id": 100, "time": 2}, "expires_at")
+	if err != nil || ok || result != nil {
+		t.Errorf("error on GetItemLive, expired item must be absent, ok=%v, result=%v, err=%v", ok, result, err)
+	}
+
+	result, ok, err = tbl.GetItemLive(map[string]interface{}{"id": 100, "time": 999}, "expires_at")
+	if err != nil || ok {
+		t.Errorf("error on GetItemLive, ok=%v, result=%v, err=%v", ok, result, err)
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	now := time.Unix(1435000000, 0)
+	tests := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{1434999999, true},
+		{int64(1435000000), false},
+		{float64(1435000001), false},
+		{"1434999999", true},
+		{"foo", false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if isExpired(tt.value, now) != tt.expected {
+			t.Errorf("error on isExpired, value=%v, expected=%v", tt.value, tt.expected)
+		}
+	}
+}
+
+func TestMergeReturn(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+	putTestTable(tbl, 100, 1)
+
+	key := map[string]interface{}{"id": 100, "time": 1}
+	result, err := tbl.MergeReturn(key, map[string]interface{}{"name": "foo"})
+	if err != nil {
+		t.Errorf("error on MergeReturn, %s", err.Error())
+	}
+	if result["id"] != 100 || result["name"] != "foo" || result["lsi_key"] != "lsi_value" {
+		t.Errorf("error on MergeReturn, %v", result)
+	}
+}
+
+func TestAddToNumberIfBelow(t *testing.T) {
+	tbl := getTestTable()
+	tbl.Delete(100, 1)
+
+	key := map[string]interface{}{"id": 100, "time": 1}
+	// create the attribute
+	v, ok, err := tbl.AddToNumberIfBelow(key, "count", 2, 3)
+	if err != nil || !ok || v != 2 {
+		t.Errorf("error on AddToNumberIfBelow, v=%d, ok=%v, err=%v", v, ok, err)
+	}
+
+	v, ok, err = tbl.AddToNumberIfBelow(key, "count", 2, 3)
+	if err != nil || !ok || v != 4 {
+		t.Errorf("error on AddToNumberIfBelow, v=%d, ok=%v, err=%v", v, ok, err)
+	}
+
+	// over the limit
+	v, ok, err = tbl.AddToNumberIfBelow(key, "count", 2, 3)
+	if err != nil || ok || v != 0 {
+		t.Errorf("error on AddToNumberIfBelow, v=%d, ok=%v, err=%v", v, ok, err)
+	}
+	result, _ := tbl.GetOne(100, 1)
+	if result["count"] != 4 {
+		t.Errorf("error on AddToNumberIfBelow, %v", result)
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	tbl := getTestTable()
+	tbl.Delete(100, 1)
+
+	item := map[string]interface{}{"id": 100, "time": 1, "owner": "foo"}
+	created, err := tbl.Upsert(item)
+	if err != nil || !created {
+		t.Errorf("error on Upsert, created=%v, err=%v", created, err)
+	}
+
+	item["owner"] = "bar"
+	created, err = tbl.Upsert(item)
+	if err != nil || created {
+		t.Errorf("error on Upsert, created=%v, err=%v", created, err)
+	}
+	result, _ := tbl.GetOne(100, 1)
+	if result["owner"] != "bar" {
+		t.Errorf("error on Upsert, %v", result)
+	}
+
+	_, err = tbl.Upsert(map[string]interface{}{"owner": "foo"})
+	if err == nil {
+		t.Errorf("error on Upsert, item without primary key must return error")
+	}
+}
+
+func TestDeleteItemIf(t *testing.T) {
+	tbl := getTestTable()
+	item := NewItem()
+	item.AddAttribute("id", 100)
+	item.AddAttribute("time", 1)
+	item.AddAttribute("owner", "foo")
+	tbl.AddItem(item)
+	tbl.Put()
+
+	key := map[string]interface{}{"id": 100, "time": 1}
+	cond := NewFilterBuilder()
+	cond.AddEQ("owner", "bar")
+	err := tbl.DeleteItemIf(key, cond)
+	if err != ErrConditionFailed {
+		t.Errorf("error on DeleteItemIf, %v", err)
+	}
+	result, _ := tbl.GetOne(100, 1)
+	if len(result) == 0 {
+		t.Errorf("error on DeleteItemIf, item is deleted")
+	}
+
+	cond = NewFilterBuilder()
+	cond.AddEQ("owner", "foo")
+	err = tbl.DeleteItemIf(key, cond)
+	if err != nil {
+		t.Errorf("error on DeleteItemIf, %s", err.Error())
+	}
+	result, _ = tbl.GetOne(100, 1)
+	if len(result) != 0 {
+		t.Errorf("error on DeleteItemIf, %v", result)
+	}
+}
+
+func TestDeleteItemIfWithoutCondition(t *testing.T) {
+	tbl := getTestCacheTable()
+	key := map[string]interface{}{"id": 100, "time": 1}
+	for i, cond := range []*FilterBuilder{nil, NewFilterBuilder()} {
+		err := tbl.DeleteItemIf(key, cond)
+		if err == nil || !strings.Contains(err.Error(), "[DynamoDB] condition is required") {
+			t.Errorf("error on DeleteItemIf, error must be returned without condition, #%d, %v", i, err)
+		}
+	}
+}
+
+func TestKeyAttributeValue(t *testing.T) {
+	tbl := &DynamoTable{
+		table: &SDK.TableDescription{
+			AttributeDefinitions: NewAttributeDefinitions(
+				NewStringAttribute("s"),
+				NewNumberAttribute("n"),
+				NewByteAttribute("b")),
+		},
+	}
+
+	b, err := tbl.keyAttributeValue("b", base64.StdEncoding.EncodeToString([]byte("foo")))
+	if err != nil || !bytes.Equal(b.B, []byte("foo")) || b.S != nil {
+		t.Errorf("error on keyAttributeValue, actual=%+v, %v", b, err)
+	}
+	b, _ = tbl.keyAttributeValue("b", []byte("foo"))
+	if !bytes.Equal(b.B, []byte("foo")) {
+		t.Errorf("error on keyAttributeValue, actual=%+v", b)
+	}
+
+	// invalid base64 string for the binary key
+	if b, err = tbl.keyAttributeValue("b", "not base64!"); err == nil {
+		t.Errorf("error on keyAttributeValue, invalid base64 string is accepted, actual=%+v", b)
+	}
+	if k, err := tbl.marshalKey(map[string]interface{}{"s": "foo", "b": "not base64!"}); err == nil {
+		t.Errorf("error on marshalKey, invalid base64 string is accepted, actual=%+v", k)
+	}
+	if s := tbl.KeyString(map[string]interface{}{"b": "not base64!"}); s != "" {
+		t.Errorf("error on KeyString, actual=%s", s)
+	}
+
+	s, _ := tbl.keyAttributeValue("s", []byte("foo"))
+	if s.S == nil || *s.S != "foo" || s.B != nil {
+		t.Errorf("error on keyAttributeValue, actual=%+v", s)
+	}
+
+	n, _ := tbl.keyAttributeValue("n", "100")
+	if n.N == nil || *n.N != "100" {
+		t.Errorf("error on keyAttributeValue, actual=%+v", n)
+	}
+
+	// not in the schema
+	other, _ := tbl.keyAttributeValue("other", "foo")
+	if other.S == nil || *other.S != "foo" {
+		t.Errorf("error on keyAttributeValue, actual=%+v", other)
+	}
+}
+
+func TestBinaryRangeKey(t *testing.T) {
+	tbl := getTestBinaryRangeTable()
+	tbl.DeleteAll()
+
+	item := NewItem()
+	item.AddAttribute("id", 100)
+	item.AddAttribute("data", []byte{0, 1, 2, 255})
+	tbl.AddItem(item)
+	err := tbl.Put()
+	if err != nil {
+		t.Errorf("error on Put, %s", err.Error())
+	}
+
+	key := base64.StdEncoding.EncodeToString([]byte{0, 1, 2, 255})
+	result, err := tbl.GetOne(100, key)
+	if err != nil {
+		t.Errorf("error on GetOne, %s", err.Error())
+	}
+	if !bytes.Equal(result["data"].([]byte), []byte{0, 1, 2, 255}) {
+		t.Errorf("error on GetOne, %v", result)
+	}
+
+	err = tbl.Delete(100, key)
+	if err != nil {
+		t.Errorf("error on Delete, %s", err.Error())
+	}
+	result, _ = tbl.GetOne(100, key)
+	if len(result) != 0 {
+		t.Errorf("error on Delete, %v", result)
+	}
+}
+
 func TestDeleteAll(t *testing.T) {
 	tbl := getTestTable()
 	putTestTable(tbl, 100, 1)
@@ -262,6 +1051,127 @@ func TestIsExistPrimaryKeys(t *testing.T) {
 	}
 }
 
+func TestValidateKeyAttributes(t *testing.T) {
+	tbl := getTestCacheTable()
+	tbl.table.AttributeDefinitions = NewAttributeDefinitions(
+		NewNumberAttribute("id"),
+		NewStringAttribute("time"),
+	)
+
+	tests := []struct {
+		item map[string]interface{}
+		ok   bool
+	}{
+		{map[string]interface{}{"id": 1, "time": "a"}, true},
+		{map[string]interface{}{"id": 1, "time": "a", "foo": true}, true},
+		{map[string]interface{}{"id": 1}, false},
+		{map[string]interface{}{"time": "a"}, false},
+		{map[string]interface{}{"id": "1", "time": "a"}, false},
+		{map[string]interface{}{"id": 1, "time": 2}, false},
+		{map[string]interface{}{"id": 1, "time": true}, false},
+	}
+	for _, tt := range tests {
+		err := tbl.validateKeyAttributes(Marshal(tt.item))
+		if (err == nil) != tt.ok {
+			t.Errorf("error on validateKeyAttributes, item=%v, err=%v", tt.item, err)
+		}
+	}
+	if err := tbl.validateKeyAttributes(nil); err == nil {
+		t.Errorf("error on validateKeyAttributes, nil item must be error")
+	}
+
+	err := tbl.validateKeyAttributes(Marshal(map[string]interface{}{"id": 1, "time": 2}))
+	if err == nil || !strings.Contains(err.Error(), "name=time") || !strings.Contains(err.Error(), "expected=S, actual=N") {
+		t.Errorf("error on validateKeyAttributes, the error must name the mistyped key, %v", err)
+	}
+
+	// the key without the attribute definition is checked only for the existence
+	tbl.table.AttributeDefinitions = nil
+	if err := tbl.validateKeyAttributes(Marshal(map[string]interface{}{"id": "1", "time": 2})); err != nil {
+		t.Errorf("error on validateKeyAttributes, %v", err)
+	}
+}
+
+func TestStrictKeysPutItem(t *testing.T) {
+	tbl := getTestCacheTable()
+	tbl.table.AttributeDefinitions = NewAttributeDefinitions(
+		NewNumberAttribute("id"),
+		NewNumberAttribute("time"),
+	)
+	tbl.StrictKeys(true)
+
+	// the invalid item is rejected before sending the request
+	_, err := tbl.putItem(&SDK.PutItemInput{
+		TableName: String(tbl.name),
+		Item:      Marshal(map[string]interface{}{"id": 1}),
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing key attribute") {
+		t.Errorf("error on putItem with StrictKeys, %v", err)
+	}
+	_, err = tbl.updateItem(&SDK.UpdateItemInput{
+		TableName: String(tbl.name),
+		Key:       Marshal(map[string]interface{}{"id": 1, "time": "1"}),
+	})
+	if err == nil || !strings.Contains(err.Error(), "mistyped key attribute") {
+		t.Errorf("error on updateItem with StrictKeys, %v", err)
+	}
+}
+
+func TestTableRequestDefaults(t *testing.T) {
+	tbl := getTestCacheTable()
+	tbl.indexes = map[string]*DynamoIndex{
+		"lsi": NewDynamoIndex("lsi", indexTypeLSI, NewKeySchema(NewHashKeyElement("id"), NewRangeKeyElement("name"))),
+		"gsi": NewDynamoIndex("gsi", indexTypeGSI, NewKeySchema(NewHashKeyElement("email"))),
+	}
+
+	// no default
+	q := &SDK.QueryInput{}
+	tbl.applyQueryDefaults(q)
+	if q.ConsistentRead != nil || q.ReturnConsumedCapacity != nil {
+		t.Errorf("error on applyQueryDefaults, %v", q)
+	}
+
+	tbl.SetDefaultConsistentRead(true)
+	tbl.SetDefaultReturnCapacity("TOTAL")
+	g := &SDK.GetItemInput{}
+	tbl.applyGetDefaults(g)
+	if g.ConsistentRead == nil || !*g.ConsistentRead || *g.ReturnConsumedCapacity != "TOTAL" {
+		t.Errorf("error on applyGetDefaults, %v", g)
+	}
+	q = &SDK.QueryInput{IndexName: String("lsi")}
+	tbl.applyQueryDefaults(q)
+	if q.ConsistentRead == nil || !*q.ConsistentRead || *q.ReturnConsumedCapacity != "TOTAL" {
+		t.Errorf("error on applyQueryDefaults, %v", q)
+	}
+
+	// GSI does not support ConsistentRead
+	for _, name := range []string{"gsi", "unknown"} {
+		q = &SDK.QueryInput{IndexName: String(name)}
+		tbl.applyQueryDefaults(q)
+		if q.ConsistentRead != nil || *q.ReturnConsumedCapacity != "TOTAL" {
+			t.Errorf("error on applyQueryDefaults, index=%s, %v", name, q)
+		}
+	}
+
+	// the value of the request is kept
+	q = &SDK.QueryInput{ConsistentRead: Boolean(false), ReturnConsumedCapacity: String("NONE")}
+	tbl.applyQueryDefaults(q)
+	if *q.ConsistentRead || *q.ReturnConsumedCapacity != "NONE" {
+		t.Errorf("error on applyQueryDefaults, %v", q)
+	}
+
+	tbl.db = &AmazonDynamoDB{writeTables: make(map[string]bool)}
+	tbl.AddItem(NewItem())
+	if *tbl.writeItems[0].ReturnConsumedCapacity != "TOTAL" {
+		t.Errorf("error on AddItem, %v", tbl.writeItems[0])
+	}
+	tbl.SetDefaultReturnCapacity("INDEXES")
+	tbl.AddItem(NewItem())
+	if *tbl.writeItems[1].ReturnConsumedCapacity != "INDEXES" {
+		t.Errorf("error on AddItem, %v", tbl.writeItems[1])
+	}
+}
+
 func putTestTable(tbl *DynamoTable, hValue, rValue Any) error {
 	item := NewItem()
 	item.AddAttribute("id", hValue)
@@ -292,3 +1202,64 @@ func getTestHashTable() *DynamoTable {
 	tbl, _ := c.GetTable(name)
 	return tbl
 }
+
+func getTestStringRangeTable() *DynamoTable {
+	setTestEnv()
+
+	c := NewClient()
+	name := "foo_stringtable"
+	in := getCreateStringRangeTableInput(GetTablePrefix() + name)
+	createTable(c, in)
+	tbl, _ := c.GetTable(name)
+	return tbl
+}
+
+func getTestBinaryRangeTable() *DynamoTable {
+	setTestEnv()
+
+	c := NewClient()
+	name := "foo_binarytable"
+	in := getCreateBinaryRangeTableInput(GetTablePrefix() + name)
+	createTable(c, in)
+	tbl, _ := c.GetTable(name)
+	return tbl
+}
+
+func TestIndexStatus(t *testing.T) {
+	tbl := getTestTable()
+	status, backfilling, err := tbl.IndexStatus("gsi-index")
+	if err != nil {
+		t.Errorf("error on IndexStatus, %s", err.Error())
+	}
+	if status != "ACTIVE" || backfilling {
+		t.Errorf("error on IndexStatus, status=%s, backfilling=%t", status, backfilling)
+	}
+	if err := tbl.WaitForIndexActive("gsi-index", time.Second); err != nil {
+		t.Errorf("error on WaitForIndexActive, %s", err.Error())
+	}
+
+	_, _, err = tbl.IndexStatus("lsi-index")
+	if err == nil {
+		t.Errorf("error on IndexStatus, local secondary index is found")
+	}
+}
+
+func TestGlobalIndexStatus(t *testing.T) {
+	desc := &SDK.TableDescription{
+		GlobalSecondaryIndexes: []*SDK.GlobalSecondaryIndexDescription{
+			{IndexName: String("gsi1"), IndexStatus: String("CREATING"), Backfilling: Boolean(true)},
+			{IndexName: String("gsi2"), IndexStatus: String("ACTIVE")},
+		},
+	}
+	status, backfilling, ok := globalIndexStatus(desc, "gsi1")
+	if !ok || status != "CREATING" || !backfilling {
+		t.Errorf("error on globalIndexStatus, status=%s, backfilling=%t", status, backfilling)
+	}
+	status, backfilling, ok = globalIndexStatus(desc, "gsi2")
+	if !ok || status != "ACTIVE" || backfilling {
+		t.Errorf("error on globalIndexStatus, status=%s, backfilling=%t", status, backfilling)
+	}
+	if _, _, ok = globalIndexStatus(desc, "gsi3"); ok {
+		t.Errorf("error on globalIndexStatus, unknown index is found")
+	}
+}
@@ -0,0 +1,59 @@
+// DynamoDB throttling handler
+
+package dynamodb
+
+import (
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
+)
+
+const errCodeProvisionedThroughputExceeded = "ProvisionedThroughputExceededException"
+
+// ThrottleEvent is the information of the request throttled by DynamoDB after the retries of SDK
+type ThrottleEvent struct {
+	Table string
+	Op    string
+
+	// Key is the primary key of the single item request,
+	// nil for the request over the multiple partitions (Query, Scan)
+	Key map[string]interface{}
+	Err error
+}
+
+// IsPartition reports if the throttled request targets the single partition key.
+// DynamoDB does not tell which partition is throttled, the repeated events on the same key imply the hot partition,
+// and the events without key imply the table-level throttling
+func (e ThrottleEvent) IsPartition() bool {
+	return e.Key != nil
+}
+
+// ThrottleHandler is called when the request is throttled
+type ThrottleHandler func(ThrottleEvent)
+
+// SetThrottleHandler sets the handler called on ProvisionedThroughputExceededException, nil handler disables it
+func (d *AmazonDynamoDB) SetThrottleHandler(fn ThrottleHandler) {
+	d.throttleHandler = fn
+}
+
+// IsThrottled checks if the error is ProvisionedThroughputExceededException
+func IsThrottled(err error) bool {
+	return awserror.Code(err) == errCodeProvisionedThroughputExceeded
+}
+
+// call the throttle handler with the primary key in the item when the error is throttling
+func (t *DynamoTable) notifyThrottle(op string, item *map[string]*SDK.AttributeValue, err error) {
+	fn := t.db.throttleHandler
+	if fn == nil || !IsThrottled(err) {
+		return
+	}
+	ev := ThrottleEvent{
+		Table: t.name,
+		Op:    op,
+		Err:   err,
+	}
+	if item != nil {
+		ev.Key = t.options().unmarshalSubset(item, t.keyNames()...)
+	}
+	fn(ev)
+}
@@ -0,0 +1,72 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+type testAWSError struct {
+	code string
+	msg  string
+}
+
+func (e testAWSError) Error() string   { return e.code + ": " + e.msg }
+func (e testAWSError) Code() string    { return e.code }
+func (e testAWSError) Message() string { return e.msg }
+func (e testAWSError) OrigErr() error  { return nil }
+
+func TestIsThrottled(t *testing.T) {
+	if !IsThrottled(wrapError("PutItem", testAWSError{errCodeProvisionedThroughputExceeded, "test error"})) {
+		t.Errorf("error on IsThrottled, throttling error is not detected")
+	}
+	if IsThrottled(wrapError("PutItem", testAWSError{errCodeConditionalCheckFailed, "test error"})) {
+		t.Errorf("error on IsThrottled, conditional check error is detected")
+	}
+	if IsThrottled(errors.New("error")) || IsThrottled(nil) {
+		t.Errorf("error on IsThrottled, non-AWS error is detected")
+	}
+}
+
+func TestNotifyThrottle(t *testing.T) {
+	tbl := &DynamoTable{
+		name: "foo",
+		db:   &AmazonDynamoDB{},
+		table: &SDK.TableDescription{
+			KeySchema: NewKeySchema(NewHashKeyElement("id"), NewRangeKeyElement("time")),
+		},
+	}
+	item := Marshal(map[string]interface{}{
+		"id":   "a",
+		"time": 1,
+		"body": "text",
+	})
+	throttled := wrapError("PutItem", testAWSError{errCodeProvisionedThroughputExceeded, "test error"})
+
+	// no handler
+	tbl.notifyThrottle("PutItem", item, throttled)
+
+	var events []ThrottleEvent
+	tbl.db.SetThrottleHandler(func(ev ThrottleEvent) {
+		events = append(events, ev)
+	})
+	tbl.notifyThrottle("PutItem", item, throttled)
+	tbl.notifyThrottle("Scan", nil, throttled)
+	tbl.notifyThrottle("PutItem", item, errors.New("error"))
+	tbl.notifyThrottle("PutItem", item, nil)
+	if len(events) != 2 {
+		t.Fatalf("error on notifyThrottle, events=%+v", events)
+	}
+
+	ev := events[0]
+	if ev.Table != "foo" || ev.Op != "PutItem" || ev.Err != throttled || !ev.IsPartition() {
+		t.Errorf("error on notifyThrottle, event=%+v", ev)
+	}
+	if len(ev.Key) != 2 || ev.Key["id"] != "a" || ev.Key["time"] != 1 {
+		t.Errorf("error on notifyThrottle, key=%+v", ev.Key)
+	}
+	if ev := events[1]; ev.Op != "Scan" || ev.IsPartition() {
+		t.Errorf("error on notifyThrottle, event=%+v", ev)
+	}
+}
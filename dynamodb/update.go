@@ -0,0 +1,332 @@
+// DynamoDB UpdateExpression builder
+
+package dynamodb
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// UpdateBuilder is a builder for UpdateExpression on UpdateItem operation
+type UpdateBuilder struct {
+	attrs  *expressionAttributes
+	set    []string
+	remove []string
+	add    []string
+	del    []string
+	err    error
+}
+
+// Create new UpdateBuilder struct
+func NewUpdateBuilder() *UpdateBuilder {
+	return defaultMarshalOptions.newUpdateBuilder()
+}
+
+// NewUpdateBuilder creates new UpdateBuilder struct which converts the values by the options of the client (e.g. SetNumberCodec)
+func (t *DynamoTable) NewUpdateBuilder() *UpdateBuilder {
+	return t.options().newUpdateBuilder()
+}
+
+// Create new UpdateBuilder struct which converts the values by the options
+func (o *marshalOptions) newUpdateBuilder() *UpdateBuilder {
+	return &UpdateBuilder{
+		attrs: o.newExpressionAttributes(),
+	}
+}
+
+// Create new UpdateBuilder which sets all of the attributes except the keys
+func (o *marshalOptions) newMergeBuilder(updates map[string]interface{}, keys []string) *UpdateBuilder {
+	skip := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		skip[k] = true
+	}
+	var names []string
+	for name, v := range updates {
+		if skip[name] || (v == nil && o.omitNilValue) {
+			continue
+		}
+		names = append(names, name)
+	}
+	// sort the names to create same expression for same updates
+	sort.Strings(names)
+
+	b := o.newUpdateBuilder()
+	for _, name := range names {
+		b.Set(name, updates[name])
+	}
+	return b
+}
+
+// MarshalStructForUpdate creates UpdateBuilder which sets all of the tagged fields of the struct except the keys,
+// the fields tagged with hash or range and the attributes of keyAttrs are not set because the key attributes cannot be updated.
+// the nil pointer field is set as NULL, or skipped on the client with SetOmitNilValue(true).
+// the zero value of the field with `default=` option is replaced to the default value even if it has omitempty,
+// and the zero value (including nil pointer) of the field with omitempty is skipped.
+// time.Time of the field with `format=date` is set as Date, and the value out of the `enum=` option returns error
+func MarshalStructForUpdate(v interface{}, keyAttrs ...string) (*UpdateBuilder, error) {
+	return defaultMarshalOptions.marshalStructForUpdate(v, keyAttrs...)
+}
+
+// MarshalStructForUpdate creates UpdateBuilder from the struct by the options of the client (see the package-level MarshalStructForUpdate)
+func (d *AmazonDynamoDB) MarshalStructForUpdate(v interface{}, keyAttrs ...string) (*UpdateBuilder, error) {
+	return d.options().marshalStructForUpdate(v, keyAttrs...)
+}
+
+// create UpdateBuilder from the struct by the options
+func (o *marshalOptions) marshalStructForUpdate(v interface{}, keyAttrs ...string) (*UpdateBuilder, error) {
+	fields, err := parseStructFields(v)
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("[DynamoDB] the value must be struct")
+		}
+		rv = rv.Elem()
+	}
+
+	skip := make(map[string]bool, len(keyAttrs))
+	for _, k := range keyAttrs {
+		skip[k] = true
+	}
+	values := make(map[string]interface{}, len(fields))
+	var names []string
+	for _, f := range fields {
+		if f.keyType != "" || skip[f.name] {
+			continue
+		}
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue
+		}
+		var value interface{}
+		switch {
+		case f.defaultValue != nil && fv.IsZero():
+			value = f.defaultValue
+		case f.omitEmpty && fv.IsZero():
+			continue
+		default:
+			if err := f.validateEnum(fv); err != nil {
+				return nil, err
+			}
+			value = structFieldValue(fv)
+		}
+		if tv, ok := value.(time.Time); ok && f.dateFormat {
+			value = NewDate(tv)
+		}
+		if value == nil && o.omitNilValue {
+			continue
+		}
+		if _, ok := values[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		values[f.name] = value
+	}
+	// sort the names to create same expression for same struct
+	sort.Strings(names)
+
+	b := o.newUpdateBuilder()
+	for _, name := range names {
+		b.Set(name, values[name])
+	}
+	return b, b.Error()
+}
+
+// get the value of the struct field, the pointer is dereferenced and nil pointer returns nil
+func structFieldValue(fv reflect.Value) interface{} {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	return fv.Interface()
+}
+
+// DiffUpdate creates UpdateBuilder which persists only the changes from the old item to the updated item,
+// SET for the changed or added attributes and REMOVE for the deleted attributes.
+// the values are compared as the marshaled AttributeValue (e.g. int and int64 of the same number are equal),
+// and the unchanged key attributes are not contained in the expression
+func DiffUpdate(old, updated map[string]interface{}) *UpdateBuilder {
+	return defaultMarshalOptions.diffUpdate(old, updated)
+}
+
+// DiffUpdate creates UpdateBuilder for the changes from the old item to the updated item by the options of the client (see the package-level DiffUpdate)
+func (d *AmazonDynamoDB) DiffUpdate(old, updated map[string]interface{}) *UpdateBuilder {
+	return d.options().diffUpdate(old, updated)
+}
+
+// create UpdateBuilder for the changes from the old item to the updated item by the options
+func (o *marshalOptions) diffUpdate(old, updated map[string]interface{}) *UpdateBuilder {
+	var setNames, removeNames []string
+	for name, v := range updated {
+		if v == nil && o.omitNilValue {
+			continue
+		}
+		prev, ok := old[name]
+		if ok && !(prev == nil && o.omitNilValue) && o.isSameAttributeValue(prev, v) {
+			continue
+		}
+		setNames = append(setNames, name)
+	}
+	for name, v := range old {
+		if v == nil && o.omitNilValue {
+			continue
+		}
+		if next, ok := updated[name]; !ok || (next == nil && o.omitNilValue) {
+			removeNames = append(removeNames, name)
+		}
+	}
+	// sort the names to create same expression for same diff
+	sort.Strings(setNames)
+	sort.Strings(removeNames)
+
+	b := o.newUpdateBuilder()
+	for _, name := range setNames {
+		b.Set(name, updated[name])
+	}
+	for _, name := range removeNames {
+		b.Remove(name)
+	}
+	return b
+}
+
+// check if the values are same as the marshaled AttributeValue
+func (o *marshalOptions) isSameAttributeValue(a, b Any) bool {
+	return reflect.DeepEqual(o.createAttributeValue(a), o.createAttributeValue(b))
+}
+
+// Set adds SET clause, `#n = :v`
+func (b *UpdateBuilder) Set(attr string, value Any) {
+	b.set = append(b.set, b.attrs.name(attr)+" = "+b.attrs.value(value))
+}
+
+// SetIfNotExists adds SET clause only for an absent attribute, `#n = if_not_exists(#n, :v)`
+func (b *UpdateBuilder) SetIfNotExists(attr string, value Any) {
+	n := b.attrs.name(attr)
+	b.set = append(b.set, n+" = if_not_exists("+n+", "+b.attrs.value(value)+")")
+}
+
+// AppendToList adds SET clause to append values to the end of the list attribute
+// `#n = list_append(if_not_exists(#n, :empty), :v)`
+func (b *UpdateBuilder) AppendToList(attr string, values []interface{}) {
+	n := b.attrs.name(attr)
+	list := b.attrs.attributeValue(b.attrs.opts.newListAttributeValue(values))
+	b.set = append(b.set, n+" = list_append("+b.listOrEmpty(n)+", "+list+")")
+}
+
+// PrependToList adds SET clause to insert values to the beginning of the list attribute
+// `#n = list_append(:v, if_not_exists(#n, :empty))`
+func (b *UpdateBuilder) PrependToList(attr string, values []interface{}) {
+	n := b.attrs.name(attr)
+	list := b.attrs.attributeValue(b.attrs.opts.newListAttributeValue(values))
+	b.set = append(b.set, n+" = list_append("+list+", "+b.listOrEmpty(n)+")")
+}
+
+// get the operand for the list attribute which is treated as empty list when it does not exist
+func (b *UpdateBuilder) listOrEmpty(name string) string {
+	empty := b.attrs.attributeValue(b.attrs.opts.newListAttributeValue(nil))
+	return "if_not_exists(" + name + ", " + empty + ")"
+}
+
+// Add adds ADD clause, it increments the number attribute (atomic counter)
+func (b *UpdateBuilder) Add(attr string, value Any) {
+	b.add = append(b.add, b.attrs.name(attr)+" "+b.attrs.value(value))
+}
+
+// AddToSet adds ADD clause to add members into the set attribute (SS or NS)
+func (b *UpdateBuilder) AddToSet(attr string, members []interface{}) {
+	set, err := b.attrs.opts.newSetAttributeValue(members)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.add = append(b.add, b.attrs.name(attr)+" "+b.attrs.attributeValue(set))
+}
+
+// RemoveFromSet adds DELETE clause to remove members from the set attribute (SS or NS)
+func (b *UpdateBuilder) RemoveFromSet(attr string, members []interface{}) {
+	set, err := b.attrs.opts.newSetAttributeValue(members)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.del = append(b.del, b.attrs.name(attr)+" "+b.attrs.attributeValue(set))
+}
+
+// Create new SS or NS AttributeValue from set members
+func (o *marshalOptions) newSetAttributeValue(members []interface{}) (*SDK.AttributeValue, error) {
+	if len(members) == 0 {
+		return nil, errors.New("[DynamoDB] set members must not be empty")
+	}
+	set := &SDK.AttributeValue{}
+	for _, m := range members {
+		v := o.createAttributeValue(m)
+		switch {
+		case v.S != nil && len(set.NS) == 0:
+			set.SS = append(set.SS, v.S)
+		case v.N != nil && len(set.SS) == 0:
+			set.NS = append(set.NS, v.N)
+		default:
+			return nil, errors.New("[DynamoDB] set members must be all strings or all numbers")
+		}
+	}
+	return set, nil
+}
+
+// Remove adds REMOVE clause
+func (b *UpdateBuilder) Remove(attr string) {
+	b.remove = append(b.remove, b.attrs.name(attr))
+}
+
+// Expression returns UpdateExpression
+func (b *UpdateBuilder) Expression() string {
+	var exp []string
+	if len(b.set) > 0 {
+		exp = append(exp, "SET "+strings.Join(b.set, ", "))
+	}
+	if len(b.remove) > 0 {
+		exp = append(exp, "REMOVE "+strings.Join(b.remove, ", "))
+	}
+	if len(b.add) > 0 {
+		exp = append(exp, "ADD "+strings.Join(b.add, ", "))
+	}
+	if len(b.del) > 0 {
+		exp = append(exp, "DELETE "+strings.Join(b.del, ", "))
+	}
+	return strings.Join(exp, " ")
+}
+
+// Error returns the error occurred while building the expression
+func (b *UpdateBuilder) Error() error {
+	return b.err
+}
+
+// NewCondition creates new FilterBuilder for ConditionExpression of UpdateItemIf,
+// which shares the placeholders with the update expression
+func (b *UpdateBuilder) NewCondition() *FilterBuilder {
+	return &FilterBuilder{
+		attrs: b.attrs,
+	}
+}
+
+// Create new UpdateItemInput from the builder
+func (b *UpdateBuilder) newUpdateItemInput(table string, key *map[string]*SDK.AttributeValue) *SDK.UpdateItemInput {
+	in := &SDK.UpdateItemInput{
+		TableName:                 String(table),
+		Key:                       key,
+		ExpressionAttributeNames:  b.attrs.expressionNames(),
+		ExpressionAttributeValues: b.attrs.expressionValues(),
+	}
+	// UpdateItem without expression creates the item with key attributes only
+	if exp := b.Expression(); exp != "" {
+		in.UpdateExpression = String(exp)
+	}
+	return in
+}
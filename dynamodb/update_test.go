@@ -0,0 +1,305 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateBuilderSet(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.Set("status", 1)
+	b.Set("name", "foo")
+	exp := b.Expression()
+	if exp != "SET #n0 = :v0, #n1 = :v1" {
+		t.Errorf("error on Set, %s", exp)
+	}
+	if *b.attrs.names["#n0"] != "status" || *b.attrs.values[":v0"].N != "1" {
+		t.Errorf("error on Set, %v", b.attrs)
+	}
+	if *b.attrs.names["#n1"] != "name" || *b.attrs.values[":v1"].S != "foo" {
+		t.Errorf("error on Set, %v", b.attrs)
+	}
+}
+
+func TestUpdateBuilderSetIfNotExists(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.SetIfNotExists("created", 99)
+	b.Add("count", 1)
+	exp := b.Expression()
+	if exp != "SET #n0 = if_not_exists(#n0, :v0) ADD #n1 :v1" {
+		t.Errorf("error on SetIfNotExists, %s", exp)
+	}
+	if len(b.attrs.names) != 2 || len(b.attrs.values) != 2 {
+		t.Errorf("error on SetIfNotExists, %v", b.attrs)
+	}
+}
+
+func TestUpdateBuilderRemove(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.Remove("foo")
+	b.Set("status", 1)
+	exp := b.Expression()
+	if exp != "SET #n1 = :v0 REMOVE #n0" {
+		t.Errorf("error on Remove, %s", exp)
+	}
+	if b.attrs.expressionNames() == nil || len(*b.attrs.expressionNames()) != 2 {
+		t.Errorf("error on Remove, %v", b.attrs)
+	}
+
+	b = NewUpdateBuilder()
+	b.Remove("foo")
+	if b.attrs.expressionValues() != nil {
+		t.Errorf("error on Remove, %v", b.attrs)
+	}
+}
+
+func TestUpdateBuilderAppendToList(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.AppendToList("items", []interface{}{"foo", 1})
+	exp := b.Expression()
+	if exp != "SET #n0 = list_append(if_not_exists(#n0, :v1), :v0)" {
+		t.Errorf("error on AppendToList, %s", exp)
+	}
+	list := b.attrs.values[":v0"]
+	if len(list.L) != 2 || *list.L[0].S != "foo" || *list.L[1].N != "1" {
+		t.Errorf("error on AppendToList, %v", list)
+	}
+	empty := b.attrs.values[":v1"]
+	if empty.L == nil || len(empty.L) != 0 {
+		t.Errorf("error on AppendToList, %v", empty)
+	}
+}
+
+func TestUpdateBuilderPrependToList(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.PrependToList("items", []interface{}{"foo"})
+	exp := b.Expression()
+	if exp != "SET #n0 = list_append(:v0, if_not_exists(#n0, :v1))" {
+		t.Errorf("error on PrependToList, %s", exp)
+	}
+}
+
+func TestUpdateBuilderAddToSet(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.AddToSet("tags", []interface{}{"foo", "bar"})
+	b.AddToSet("ids", []interface{}{1, 2})
+	exp := b.Expression()
+	if exp != "ADD #n0 :v0, #n1 :v1" {
+		t.Errorf("error on AddToSet, %s", exp)
+	}
+	if len(b.attrs.values[":v0"].SS) != 2 || len(b.attrs.values[":v1"].NS) != 2 {
+		t.Errorf("error on AddToSet, %v", b.attrs.values)
+	}
+	if b.Error() != nil {
+		t.Errorf("error on AddToSet, %s", b.Error().Error())
+	}
+
+	b = NewUpdateBuilder()
+	b.AddToSet("tags", []interface{}{"foo", 1})
+	if b.Error() == nil {
+		t.Errorf("error on AddToSet, mixed members must be error")
+	}
+	if b.Expression() != "" {
+		t.Errorf("error on AddToSet, %s", b.Expression())
+	}
+}
+
+func TestUpdateBuilderRemoveFromSet(t *testing.T) {
+	b := NewUpdateBuilder()
+	b.RemoveFromSet("tags", []interface{}{"foo"})
+	exp := b.Expression()
+	if exp != "DELETE #n0 :v0" {
+		t.Errorf("error on RemoveFromSet, %s", exp)
+	}
+
+	b = NewUpdateBuilder()
+	b.RemoveFromSet("tags", []interface{}{1, "foo"})
+	if b.Error() == nil {
+		t.Errorf("error on RemoveFromSet, mixed members must be error")
+	}
+
+	b = NewUpdateBuilder()
+	b.RemoveFromSet("tags", nil)
+	if b.Error() == nil {
+		t.Errorf("error on RemoveFromSet, empty members must be error")
+	}
+}
+
+func TestNewMergeBuilder(t *testing.T) {
+	updates := map[string]interface{}{
+		"id":    100,
+		"time":  1,
+		"name":  "foo",
+		"count": 5,
+	}
+	b := defaultMarshalOptions.newMergeBuilder(updates, []string{"id", "time"})
+	exp := b.Expression()
+	if exp != "SET #n0 = :v0, #n1 = :v1" {
+		t.Errorf("error on newMergeBuilder, %s", exp)
+	}
+	if *b.attrs.names["#n0"] != "count" || *b.attrs.names["#n1"] != "name" {
+		t.Errorf("error on newMergeBuilder, %v", b.attrs.names)
+	}
+
+	// only keys
+	b = defaultMarshalOptions.newMergeBuilder(map[string]interface{}{"id": 100}, []string{"id", "time"})
+	in := b.newUpdateItemInput("foo_table", Marshal(map[string]interface{}{"id": 100}))
+	if in.UpdateExpression != nil || in.ExpressionAttributeNames != nil || in.ExpressionAttributeValues != nil {
+		t.Errorf("error on newMergeBuilder, %v", in)
+	}
+}
+
+func TestMarshalStructForUpdate(t *testing.T) {
+	type user struct {
+		ID      int     `dynamodb:"id,hash"`
+		Time    int     `dynamodb:"time,range"`
+		Name    string  `dynamodb:"name"`
+		Email   *string `dynamodb:"email"`
+		Tenant  string  `dynamodb:"tenant"`
+		Ignored string  `dynamodb:"-"`
+	}
+	name := "foo@example.com"
+	b, err := MarshalStructForUpdate(&user{ID: 100, Time: 1, Name: "foo", Email: &name, Tenant: "bar"}, "tenant")
+	if err != nil {
+		t.Errorf("error on MarshalStructForUpdate, %s", err.Error())
+	}
+	exp := b.Expression()
+	if exp != "SET #n0 = :v0, #n1 = :v1" {
+		t.Errorf("error on MarshalStructForUpdate, %s", exp)
+	}
+	if *b.attrs.names["#n0"] != "email" || *b.attrs.values[":v0"].S != name {
+		t.Errorf("error on MarshalStructForUpdate, %v", b.attrs)
+	}
+	if *b.attrs.names["#n1"] != "name" || *b.attrs.values[":v1"].S != "foo" {
+		t.Errorf("error on MarshalStructForUpdate, %v", b.attrs)
+	}
+
+	// nil pointer
+	b, _ = MarshalStructForUpdate(user{ID: 100, Time: 1})
+	if b.Expression() != "SET #n0 = :v0, #n1 = :v1, #n2 = :v2" || b.attrs.values[":v0"].NULL == nil {
+		t.Errorf("error on MarshalStructForUpdate, %s, %v", b.Expression(), b.attrs)
+	}
+	o := &marshalOptions{omitNilValue: true}
+	b, _ = o.marshalStructForUpdate(user{ID: 100, Time: 1})
+	if b.Expression() != "SET #n0 = :v0, #n1 = :v1" {
+		t.Errorf("error on MarshalStructForUpdate, %s", b.Expression())
+	}
+
+	if _, err := MarshalStructForUpdate(map[string]interface{}{"id": 1}); err == nil {
+		t.Errorf("error on MarshalStructForUpdate, non-struct value is accepted")
+	}
+	if _, err := MarshalStructForUpdate((*user)(nil)); err == nil {
+		t.Errorf("error on MarshalStructForUpdate, nil pointer is accepted")
+	}
+}
+
+func TestMarshalStructForUpdateDefault(t *testing.T) {
+	type user struct {
+		ID     int     `dynamodb:"id,hash"`
+		Status string  `dynamodb:"status,omitempty,default=active"`
+		Level  *int    `dynamodb:"level,default=1"`
+		Memo   string  `dynamodb:"memo,omitempty"`
+		Email  *string `dynamodb:"email,omitempty"`
+	}
+	b, err := MarshalStructForUpdate(user{ID: 100})
+	if err != nil {
+		t.Errorf("error on MarshalStructForUpdate, %s", err.Error())
+	}
+	if b.Expression() != "SET #n0 = :v0, #n1 = :v1" {
+		t.Errorf("error on MarshalStructForUpdate, %s", b.Expression())
+	}
+	if *b.attrs.names["#n0"] != "level" || *b.attrs.values[":v0"].N != "1" {
+		t.Errorf("error on MarshalStructForUpdate, %v", b.attrs)
+	}
+	if *b.attrs.names["#n1"] != "status" || *b.attrs.values[":v1"].S != "active" {
+		t.Errorf("error on MarshalStructForUpdate, %v", b.attrs)
+	}
+
+	// the non-zero value is used
+	level := 5
+	b, _ = MarshalStructForUpdate(user{ID: 100, Status: "banned", Level: &level, Memo: "foo"})
+	if b.Expression() != "SET #n0 = :v0, #n1 = :v1, #n2 = :v2" {
+		t.Errorf("error on MarshalStructForUpdate, %s", b.Expression())
+	}
+	if *b.attrs.values[":v0"].N != "5" || *b.attrs.values[":v1"].S != "foo" || *b.attrs.values[":v2"].S != "banned" {
+		t.Errorf("error on MarshalStructForUpdate, %v", b.attrs)
+	}
+}
+
+func TestMarshalStructForUpdateDateFormat(t *testing.T) {
+	type user struct {
+		ID       int        `dynamodb:"id,hash"`
+		Birthday time.Time  `dynamodb:"dob,format=date"`
+		Joined   *time.Time `dynamodb:"joined,omitempty,format=date"`
+	}
+	b, err := MarshalStructForUpdate(user{ID: 100, Birthday: time.Date(2000, 2, 29, 23, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Errorf("error on MarshalStructForUpdate, %s", err.Error())
+	}
+	if b.Expression() != "SET #n0 = :v0" {
+		t.Errorf("error on MarshalStructForUpdate, %s", b.Expression())
+	}
+	if *b.attrs.names["#n0"] != "dob" || *b.attrs.values[":v0"].S != "2000-02-29" {
+		t.Errorf("error on MarshalStructForUpdate, %v", b.attrs)
+	}
+
+	// format=date is only for time.Time
+	type invalid struct {
+		Name string `dynamodb:"name,format=date"`
+	}
+	if _, err := MarshalStructForUpdate(invalid{}); err == nil {
+		t.Errorf("error on MarshalStructForUpdate, format=date is accepted for string")
+	}
+}
+
+func TestMarshalStructForUpdateEnum(t *testing.T) {
+	type user struct {
+		ID   int    `dynamodb:"id,hash"`
+		Role string `dynamodb:"role,enum=admin|user|guest"`
+	}
+	b, err := MarshalStructForUpdate(user{ID: 100, Role: "guest"})
+	if err != nil {
+		t.Errorf("error on MarshalStructForUpdate, %s", err.Error())
+	}
+	if b.Expression() != "SET #n0 = :v0" || *b.attrs.values[":v0"].S != "guest" {
+		t.Errorf("error on MarshalStructForUpdate, %s, %v", b.Expression(), b.attrs)
+	}
+
+	if _, err := MarshalStructForUpdate(user{ID: 100, Role: "root"}); err == nil {
+		t.Errorf("error on MarshalStructForUpdate, the value out of enum is accepted")
+	}
+}
+
+func TestDiffUpdate(t *testing.T) {
+	old := map[string]interface{}{
+		"id":    100,
+		"name":  "foo",
+		"count": 5,
+		"tags":  []string{"a", "b"},
+		"memo":  "bar",
+	}
+	updated := map[string]interface{}{
+		"id":     100,
+		"name":   "foo",
+		"count":  int64(6),
+		"tags":   []string{"a", "b"},
+		"status": 1,
+	}
+	b := DiffUpdate(old, updated)
+	exp := b.Expression()
+	if exp != "SET #n0 = :v0, #n1 = :v1 REMOVE #n2" {
+		t.Errorf("error on DiffUpdate, %s", exp)
+	}
+	if *b.attrs.names["#n0"] != "count" || *b.attrs.names["#n1"] != "status" || *b.attrs.names["#n2"] != "memo" {
+		t.Errorf("error on DiffUpdate, %v", b.attrs.names)
+	}
+	if *b.attrs.values[":v0"].N != "6" {
+		t.Errorf("error on DiffUpdate, %v", b.attrs.values)
+	}
+
+	// same number of the different types
+	b = DiffUpdate(map[string]interface{}{"count": 6}, map[string]interface{}{"count": int64(6)})
+	if exp := b.Expression(); exp != "" {
+		t.Errorf("error on DiffUpdate, %s", exp)
+	}
+}
@@ -0,0 +1,91 @@
+// DynamoDB validation error translation
+
+package dynamodb
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
+)
+
+const errCodeValidation = "ValidationException"
+
+// kinds of ValidationError
+const (
+	ValidationKindEmptyValue   = "empty_value"
+	ValidationKindTypeMismatch = "type_mismatch"
+	ValidationKindMissingKey   = "missing_key"
+	ValidationKindOther        = "other"
+)
+
+var (
+	// e.g.) "Type mismatch for key id expected: S actual: N"
+	validationTypeMismatchPattern = regexp.MustCompile(`Type mismatch for key (\S+) expected: (\S+) actual: (\S+)`)
+	// e.g.) "Missing the key id in the item"
+	validationMissingKeyPattern = regexp.MustCompile(`Missing the key (\S+) in the item`)
+	// e.g.) "... cannot contain an empty string value. Key: id", "... may not contain an empty string for key :v0"
+	validationEmptyKeyPattern = regexp.MustCompile(`(?:Key: |for key )(\S+)`)
+)
+
+// ValidationError is the field-level information parsed from ValidationException
+type ValidationError struct {
+	Kind string
+
+	// Attribute is the attribute name in the message, empty when the message does not have it.
+	// (it may be the placeholder of the expression, like ":v0")
+	Attribute string
+
+	// Expected and Actual are the attribute types on type mismatch
+	Expected string
+	Actual   string
+
+	Err error
+}
+
+// Error returns the error message with the kind and attribute
+func (e *ValidationError) Error() string {
+	msg := "[DynamoDB] validation error, kind=" + e.Kind
+	if e.Attribute != "" {
+		msg += ", attribute=" + e.Attribute
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the original error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// AsValidationError parses the message of ValidationException into ValidationError,
+// returns false when the error is not ValidationException
+func AsValidationError(err error) (*ValidationError, bool) {
+	if awserror.Code(err) != errCodeValidation {
+		return nil, false
+	}
+
+	msg := awserror.Message(err)
+	e := &ValidationError{
+		Kind: ValidationKindOther,
+		Err:  err,
+	}
+	switch {
+	case strings.Contains(msg, "empty string"):
+		e.Kind = ValidationKindEmptyValue
+		if m := validationEmptyKeyPattern.FindStringSubmatch(msg); m != nil {
+			e.Attribute = strings.TrimRight(m[1], ".,")
+		}
+	case validationTypeMismatchPattern.MatchString(msg):
+		m := validationTypeMismatchPattern.FindStringSubmatch(msg)
+		e.Kind = ValidationKindTypeMismatch
+		e.Attribute = m[1]
+		e.Expected = m[2]
+		e.Actual = m[3]
+	case strings.Contains(msg, "Type mismatch"):
+		e.Kind = ValidationKindTypeMismatch
+	case validationMissingKeyPattern.MatchString(msg):
+		e.Kind = ValidationKindMissingKey
+		e.Attribute = validationMissingKeyPattern.FindStringSubmatch(msg)[1]
+	}
+	return e, true
+}
@@ -0,0 +1,48 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAsValidationError(t *testing.T) {
+	tests := []struct {
+		msg       string
+		kind      string
+		attribute string
+	}{
+		{"One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty string value. Key: id", ValidationKindEmptyValue, "id"},
+		{"ExpressionAttributeValues contains invalid value: One or more parameter values were invalid: An AttributeValue may not contain an empty string for key :v0", ValidationKindEmptyValue, ":v0"},
+		{"One or more parameter values were invalid: An AttributeValue may not contain an empty string", ValidationKindEmptyValue, ""},
+		{"One or more parameter values were invalid: Type mismatch for key id expected: S actual: N", ValidationKindTypeMismatch, "id"},
+		{"Type mismatch for attribute to update", ValidationKindTypeMismatch, ""},
+		{"One or more parameter values were invalid: Missing the key time in the item", ValidationKindMissingKey, "time"},
+		{"The provided key element does not match the schema", ValidationKindOther, ""},
+	}
+	for _, tt := range tests {
+		orig := wrapError("PutItem", testAWSError{errCodeValidation, tt.msg})
+		e, ok := AsValidationError(orig)
+		if !ok {
+			t.Errorf("error on AsValidationError, not detected: %s", tt.msg)
+			continue
+		}
+		if e.Kind != tt.kind || e.Attribute != tt.attribute {
+			t.Errorf("error on AsValidationError, msg=%s, actual=%+v", tt.msg, e)
+		}
+		if e.Unwrap() != orig {
+			t.Errorf("error on AsValidationError, original error is not kept, actual=%v", e.Unwrap())
+		}
+	}
+
+	e, _ := AsValidationError(wrapError("PutItem", testAWSError{errCodeValidation, "Type mismatch for key id expected: S actual: N"}))
+	if e.Expected != "S" || e.Actual != "N" {
+		t.Errorf("error on AsValidationError, actual=%+v", e)
+	}
+
+	if _, ok := AsValidationError(wrapError("PutItem", testAWSError{errCodeConditionalCheckFailed, "The conditional request failed"})); ok {
+		t.Errorf("error on AsValidationError, non-validation error is detected")
+	}
+	if _, ok := AsValidationError(errors.New("empty string")); ok {
+		t.Errorf("error on AsValidationError, non-AWS error is detected")
+	}
+}
@@ -0,0 +1,93 @@
+// DynamoDB batched and rate-limited writer for the sustained ingestion
+
+package dynamodb
+
+import (
+	"sync"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/dynamodb"
+)
+
+// BatchWriter buffers the items and writes them with BatchWriteItem for every 25 items,
+// the writes are throttled to the max rate and the unprocessed items are retried up to the limits of SetBatchRetry
+type BatchWriter struct {
+	table   *DynamoTable
+	maxRate int
+
+	mu    sync.Mutex
+	items []*map[string]*SDK.AttributeValue
+	next  time.Time
+	err   error
+}
+
+// NewBatchWriter creates BatchWriter for the table, maxRate is the max number of written items per second
+// (maxRate <= 0 means no limit)
+func NewBatchWriter(t *DynamoTable, maxRate int) *BatchWriter {
+	return &BatchWriter{
+		table:   t,
+		maxRate: maxRate,
+	}
+}
+
+// Add adds the item to the buffer, and writes the buffered items when the buffer reaches 25 items.
+// the error of the write is returned on Flush or Close
+func (w *BatchWriter) Add(item map[string]interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.items = append(w.items, w.table.options().marshal(item))
+	if len(w.items) >= batchWriteMaxItems {
+		w.flush()
+	}
+}
+
+// Flush writes all of the buffered items, and returns the first error of the writes since the last Flush
+func (w *BatchWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+	err := w.err
+	w.err = nil
+	return err
+}
+
+// Close writes all of the buffered items same as Flush
+func (w *BatchWriter) Close() error {
+	return w.Flush()
+}
+
+// write the buffered items for every 25 items with the rate limit, and keep the first error
+func (w *BatchWriter) flush() {
+	for len(w.items) > 0 {
+		end := batchWriteMaxItems
+		if end > len(w.items) {
+			end = len(w.items)
+		}
+		chunk := w.items[:end]
+		w.items = w.items[end:]
+
+		w.wait(len(chunk))
+		if err := w.table.batchPutItems(chunk); err != nil && w.err == nil {
+			w.err = err
+		}
+	}
+	w.items = nil
+}
+
+// sleep until the write of n items is allowed by the max rate
+func (w *BatchWriter) wait(n int) {
+	if w.maxRate <= 0 {
+		return
+	}
+	now := w.table.currentTime()
+	if w.next.After(now) {
+		time.Sleep(w.next.Sub(now))
+		now = w.next
+	}
+	w.next = now.Add(rateInterval(n, w.maxRate))
+}
+
+// get the interval to write n items at the rate of items per second
+func rateInterval(n, rate int) time.Duration {
+	return time.Duration(n) * time.Second / time.Duration(rate)
+}
@@ -0,0 +1,49 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchWriter(t *testing.T) {
+	tbl := getTestTable()
+	tbl.DeleteAll()
+
+	w := NewBatchWriter(tbl, 0)
+	for i := 1; i <= 30; i++ {
+		w.Add(map[string]interface{}{"id": 600, "time": i})
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("error on BatchWriter, %s", err.Error())
+	}
+
+	items, err := tbl.Get(600)
+	if err != nil || len(items) != 30 {
+		t.Errorf("error on BatchWriter, items=%d, err=%v", len(items), err)
+	}
+}
+
+func TestRateInterval(t *testing.T) {
+	if d := rateInterval(25, 50); d != 500*time.Millisecond {
+		t.Errorf("error on rateInterval, %s", d)
+	}
+	if d := rateInterval(5, 100); d != 50*time.Millisecond {
+		t.Errorf("error on rateInterval, %s", d)
+	}
+}
+
+func TestBatchWriterWait(t *testing.T) {
+	w := NewBatchWriter(&DynamoTable{}, 100)
+	start := time.Now()
+	w.wait(5)
+	w.wait(5)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("error on BatchWriter wait, elapsed=%s", elapsed)
+	}
+
+	w = NewBatchWriter(&DynamoTable{}, 0)
+	w.wait(1000)
+	if !w.next.IsZero() {
+		t.Errorf("error on BatchWriter wait without limit")
+	}
+}
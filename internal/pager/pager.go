@@ -0,0 +1,31 @@
+// Pagination helper for the operations with continuation token
+
+package pager
+
+import (
+	"context"
+)
+
+// Fetch retrieves the page of the token (nil token for the first page),
+// and returns the token of the next page and true when it's the last page
+type Fetch func(token interface{}) (next interface{}, done bool, err error)
+
+// Paginate calls fetch from the first page until the last page or an error
+func Paginate(fetch Fetch) error {
+	return PaginateContext(context.Background(), fetch)
+}
+
+// PaginateContext calls fetch from the first page until the last page, an error or the context is done
+func PaginateContext(ctx context.Context, fetch Fetch) error {
+	var token interface{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next, done, err := fetch(token)
+		if err != nil || done {
+			return err
+		}
+		token = next
+	}
+}
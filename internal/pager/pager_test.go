@@ -0,0 +1,48 @@
+package pager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	var tokens []interface{}
+	err := Paginate(func(token interface{}) (interface{}, bool, error) {
+		tokens = append(tokens, token)
+		n := len(tokens)
+		return n, n == 3, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{nil, 1, 2}, tokens)
+}
+
+func TestPaginateError(t *testing.T) {
+	count := 0
+	e := errors.New("fetch error")
+	err := Paginate(func(token interface{}) (interface{}, bool, error) {
+		count++
+		if count == 2 {
+			return nil, false, e
+		}
+		return count, false, nil
+	})
+	assert.Equal(t, e, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestPaginateContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	err := PaginateContext(ctx, func(token interface{}) (interface{}, bool, error) {
+		count++
+		if count == 2 {
+			cancel()
+		}
+		return count, false, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,147 @@
+// S3 archive download of the objects under the prefix
+
+package s3
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// formats of ArchivePrefix
+const (
+	ArchiveFormatTar = "tar"
+	ArchiveFormatZip = "zip"
+)
+
+// the writer of the archive entries
+type archiveWriter interface {
+	// create the entry of the object and returns the writer of the data
+	create(obj *SDK.Object) (io.Writer, error)
+	close() error
+}
+
+// ArchivePrefix streams the objects under the prefix into w as the tar or zip archive,
+// the objects are listed page by page and each object is downloaded to the entry by Download without buffering the data.
+// the name of the entry is the key without the leading slash, and the keys ending with slash (folders) are skipped.
+// tar uses the size of the listing for the entry header, and fails when the object is changed during the download.
+// zip is written with the data descriptors and keeps only the entry headers (name, size and CRC32) in memory
+// for the central directory at the end of the archive
+func (b *Bucket) ArchivePrefix(prefix string, w io.Writer, format string) error {
+	var aw archiveWriter
+	switch format {
+	case ArchiveFormatTar:
+		aw = &tarArchiveWriter{w: tar.NewWriter(w)}
+	case ArchiveFormatZip:
+		aw = &zipArchiveWriter{w: zip.NewWriter(w)}
+	default:
+		return errors.New("[S3] unsupported archive format, format=" + format)
+	}
+
+	in := &SDK.ListObjectsInput{
+		Bucket: String(b.name),
+	}
+	if prefix != "" {
+		in.Prefix = String(prefix)
+	}
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.Marker, _ = token.(*string)
+		out, err := b.client.ListObjects(in)
+		if err != nil {
+			err = wrapError("ListObjects", err)
+			log.Error("[S3] error on `ListObjects` operation, bucket="+b.name, err.Error())
+			return nil, false, err
+		}
+		for _, obj := range out.Contents {
+			if err := b.archiveObject(aw, obj); err != nil {
+				return nil, false, err
+			}
+		}
+
+		if out.IsTruncated == nil || !*out.IsTruncated || len(out.Contents) == 0 {
+			return nil, true, nil
+		}
+		// NextMarker is returned only with the delimiter
+		next := out.NextMarker
+		if next == nil {
+			next = out.Contents[len(out.Contents)-1].Key
+		}
+		return next, false, nil
+	})
+	if closeErr := aw.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// download the object into the entry of the archive
+func (b *Bucket) archiveObject(aw archiveWriter, obj *SDK.Object) error {
+	key := stringValue(obj.Key)
+	if archiveEntryName(key) == "" || strings.HasSuffix(key, "/") {
+		return nil
+	}
+	ew, err := aw.create(obj)
+	if err != nil {
+		return err
+	}
+	_, err = b.Download(key, ew)
+	return err
+}
+
+// get the name of the archive entry from the key
+func archiveEntryName(key string) string {
+	return strings.TrimLeft(key, "/")
+}
+
+func objectModTime(obj *SDK.Object) time.Time {
+	if obj.LastModified == nil {
+		return time.Time{}
+	}
+	return *obj.LastModified
+}
+
+type tarArchiveWriter struct {
+	w *tar.Writer
+}
+
+func (aw *tarArchiveWriter) create(obj *SDK.Object) (io.Writer, error) {
+	var size int64
+	if obj.Size != nil {
+		size = *obj.Size
+	}
+	err := aw.w.WriteHeader(&tar.Header{
+		Name:    archiveEntryName(stringValue(obj.Key)),
+		Mode:    0644,
+		Size:    size,
+		ModTime: objectModTime(obj),
+	})
+	return aw.w, err
+}
+
+func (aw *tarArchiveWriter) close() error {
+	return aw.w.Close()
+}
+
+type zipArchiveWriter struct {
+	w *zip.Writer
+}
+
+func (aw *zipArchiveWriter) create(obj *SDK.Object) (io.Writer, error) {
+	return aw.w.CreateHeader(&zip.FileHeader{
+		Name:     archiveEntryName(stringValue(obj.Key)),
+		Method:   zip.Deflate,
+		Modified: objectModTime(obj),
+	})
+}
+
+func (aw *zipArchiveWriter) close() error {
+	return aw.w.Close()
+}
@@ -0,0 +1,84 @@
+package s3
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchivePrefix(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+	data1, data2 := "data1", "data2"
+	b.AddSecretObject(NewS3ObjectString(&data1), "/archive/a.txt")
+	b.AddSecretObject(NewS3ObjectString(&data2), "/archive/b.txt")
+	assert.Nil(t, b.Put())
+
+	buf := new(bytes.Buffer)
+	assert.Nil(t, b.ArchivePrefix("/archive/", buf, ArchiveFormatTar))
+	r := tar.NewReader(buf)
+	h, err := r.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "archive/a.txt", h.Name)
+	data, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "data1", string(data))
+
+	buf.Reset()
+	assert.Nil(t, b.ArchivePrefix("/archive/", buf, ArchiveFormatZip))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Len(t, zr.File, 2)
+
+	assert.NotNil(t, b.ArchivePrefix("/archive/", buf, "rar"))
+}
+
+func TestTarArchiveWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	aw := &tarArchiveWriter{w: tar.NewWriter(buf)}
+	size := int64(5)
+	now := time.Unix(1435000000, 0)
+	w, err := aw.create(&SDK.Object{Key: String("/foo/bar.txt"), Size: &size, LastModified: &now})
+	assert.Nil(t, err)
+	w.Write([]byte("hello"))
+	assert.Nil(t, aw.close())
+
+	r := tar.NewReader(buf)
+	h, err := r.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "foo/bar.txt", h.Name)
+	assert.Equal(t, size, h.Size)
+	assert.Equal(t, now.Unix(), h.ModTime.Unix())
+	data, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestZipArchiveWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	aw := &zipArchiveWriter{w: zip.NewWriter(buf)}
+	w, err := aw.create(&SDK.Object{Key: String("foo/bar.txt")})
+	assert.Nil(t, err)
+	w.Write([]byte("hello"))
+	assert.Nil(t, aw.close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	assert.Len(t, zr.File, 1)
+	assert.Equal(t, "foo/bar.txt", zr.File[0].Name)
+	f, err := zr.File[0].Open()
+	assert.Nil(t, err)
+	data, _ := ioutil.ReadAll(f)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestArchiveEntryName(t *testing.T) {
+	assert.Equal(t, "foo/bar.txt", archiveEntryName("/foo/bar.txt"))
+	assert.Equal(t, "foo/bar.txt", archiveEntryName("foo/bar.txt"))
+	assert.Equal(t, "", archiveEntryName("/"))
+}
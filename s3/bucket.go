@@ -4,11 +4,16 @@ package s3
 
 import (
 	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"io"
+	"net/http"
+	"strings"
 	"time"
 )
 
@@ -20,7 +25,18 @@ const (
 )
 
 const (
-	defaultExpireSecond = 180
+	LifecycleStatusEnabled  = "Enabled"
+	LifecycleStatusDisabled = "Disabled"
+)
+
+const (
+	defaultExpireSecond    = 180
+	errCodeNoSuchLifecycle = "NoSuchLifecycleConfiguration"
+	errCodeNoSuchKey       = "NoSuchKey"
+
+	// backoff of GetWithRetry
+	getRetryWait    = 100 * time.Millisecond
+	getRetryMaxWait = 2 * time.Second
 )
 
 // struct for bucket
@@ -28,6 +44,15 @@ type Bucket struct {
 	name    string
 	objects []*SDK.PutObjectInput
 
+	// skip decoding of Content-Encoding on DownloadDecoded
+	rawEncoding bool
+
+	// max number of resuming the interrupted download
+	downloadRetries int
+
+	// backoff of GetWithRetry, nil means the default backoff
+	backoff *auth.Backoff
+
 	client *SDK.S3
 }
 
@@ -63,6 +88,7 @@ func (b *Bucket) Put() error {
 	for _, obj := range b.objects {
 		_, e := b.client.PutObject(obj)
 		if e != nil {
+			e = wrapError("PutObject", e)
 			log.Error("[S3] error on `PutObject` operation, bucket="+b.name, e.Error())
 			errStr = errStr + "," + e.Error()
 		}
@@ -81,6 +107,7 @@ func (b *Bucket) getObject(path string) (io.Reader, error) {
 	}
 	out, err := b.client.GetObject(&req)
 	if err != nil {
+		err = wrapError("GetObject", err)
 		log.Error("[S3] error on `GetObject` operation, bucket="+b.name, err.Error())
 		return nil, err
 	}
@@ -98,6 +125,157 @@ func (b *Bucket) GetObjectByte(path string) ([]byte, error) {
 	return buf.Bytes(), err
 }
 
+// GetWithRetry fetches bytes of object from target S3 path, and retries NoSuchKey error with a short backoff
+// up to attempts times in total (attempts <= 1 means no retry).
+// this is the workaround for the read-after-write of the eventual consistency, and it does not guarantee
+// that the object is found within the attempts
+func (b *Bucket) GetWithRetry(path string, attempts int) ([]byte, error) {
+	for i := 1; ; i++ {
+		out, err := b.client.GetObject(&SDK.GetObjectInput{
+			Bucket: String(b.name),
+			Key:    String(path),
+		})
+		if err == nil {
+			defer out.Body.Close()
+			buf := new(bytes.Buffer)
+			_, err = buf.ReadFrom(out.Body)
+			return buf.Bytes(), err
+		}
+
+		err = wrapError("GetObject", err)
+		if i >= attempts || !isNoSuchKey(err) {
+			log.Error("[S3] error on `GetObject` operation, bucket="+b.name, err.Error())
+			return nil, err
+		}
+		time.Sleep(b.retryBackoff(i))
+	}
+}
+
+// check if the error is NoSuchKey
+func isNoSuchKey(err error) bool {
+	return awserror.Code(err) == errCodeNoSuchKey
+}
+
+// get the wait time before the next attempt from the backoff of the client
+func (b *Bucket) retryBackoff(attempt int) time.Duration {
+	if b.backoff == nil {
+		return getRetryBackoff(attempt)
+	}
+	return b.backoff.Duration(attempt)
+}
+
+// get the default wait time before the next attempt, it doubles on every attempt up to the max wait
+func getRetryBackoff(attempt int) time.Duration {
+	wait := getRetryWait
+	for i := 1; i < attempt && wait < getRetryMaxWait; i++ {
+		wait *= 2
+	}
+	if wait > getRetryMaxWait {
+		return getRetryMaxWait
+	}
+	return wait
+}
+
+// fetch ETag, Last-Modified and size of target S3 object
+func (b *Bucket) Head(path string) (etag string, lastModified time.Time, size int64, err error) {
+	out, err := b.client.HeadObject(&SDK.HeadObjectInput{
+		Bucket: String(b.name),
+		Key:    String(path),
+	})
+	if err != nil {
+		err = wrapError("HeadObject", err)
+		log.Error("[S3] error on `HeadObject` operation, bucket="+b.name, err.Error())
+		return "", time.Time{}, 0, err
+	}
+	if out.ETag != nil {
+		etag = *out.ETag
+	}
+	if out.LastModified != nil {
+		lastModified = *out.LastModified
+	}
+	if out.ContentLength != nil {
+		size = *out.ContentLength
+	}
+	return etag, lastModified, size, nil
+}
+
+// write object data of target S3 path to w, only when the object's ETag is different from etag.
+// notModified is true when the object is not changed and nothing is written
+func (b *Bucket) DownloadIfModified(path, etag string, w io.Writer) (notModified bool, err error) {
+	req := &SDK.GetObjectInput{
+		Bucket: String(b.name),
+		Key:    String(path),
+	}
+	if etag != "" {
+		req.IfNoneMatch = String(etag)
+	}
+	out, err := b.client.GetObject(req)
+	if err != nil {
+		err = wrapError("GetObject", err)
+		if isNotModified(err) {
+			return true, nil
+		}
+		log.Error("[S3] error on `GetObject` operation, bucket="+b.name, err.Error())
+		return false, err
+	}
+	defer out.Body.Close()
+
+	_, err = io.Copy(w, out.Body)
+	return false, err
+}
+
+// RawContentEncoding sets if DownloadDecoded writes the raw object data without decoding Content-Encoding
+func (b *Bucket) RawContentEncoding(raw bool) {
+	b.rawEncoding = raw
+}
+
+// write object data of target S3 path to w,
+// the data is decompressed when the Content-Encoding of the object is gzip
+func (b *Bucket) DownloadDecoded(path string, w io.Writer) error {
+	out, err := b.client.GetObject(&SDK.GetObjectInput{
+		Bucket: String(b.name),
+		Key:    String(path),
+	})
+	if err != nil {
+		err = wrapError("GetObject", err)
+		log.Error("[S3] error on `GetObject` operation, bucket="+b.name, err.Error())
+		return err
+	}
+	defer out.Body.Close()
+
+	var r io.Reader = out.Body
+	if !b.rawEncoding && isGzipEncoding(out.ContentEncoding) {
+		gz, err := gzip.NewReader(out.Body)
+		if err != nil {
+			log.Error("[S3] error on decoding gzip object, bucket="+b.name, err.Error())
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// check if the Content-Encoding contains gzip
+func isGzipEncoding(enc *string) bool {
+	if enc == nil {
+		return false
+	}
+	for _, e := range strings.Split(*enc, ",") {
+		switch strings.ToLower(strings.TrimSpace(e)) {
+		case "gzip", "x-gzip":
+			return true
+		}
+	}
+	return false
+}
+
+// check if the error is 304 response
+func isNotModified(err error) bool {
+	return awserror.StatusCode(err) == http.StatusNotModified
+}
+
 // fetch url of target S3 object
 // (this is same as secret at this time, since it is no method for public url)
 func (b *Bucket) GetURL(path string) (string, error) {
@@ -119,6 +297,62 @@ func (b *Bucket) GetSecretURLWithExpire(path string, expire uint64) (string, err
 	return req.Presign(time.Duration(expire) * time.Second)
 }
 
+// set expiration days for the objects under the prefix.
+// the rule of same prefix is replaced and other rules are preserved
+func (b *Bucket) SetExpiration(prefix string, days int64) error {
+	rules, err := b.getLifecycleRules()
+	if err != nil {
+		return err
+	}
+
+	rule := &SDK.LifecycleRule{
+		ID:     String("expiration-" + prefix),
+		Prefix: String(prefix),
+		Status: String(LifecycleStatusEnabled),
+		Expiration: &SDK.LifecycleExpiration{
+			Days: &days,
+		},
+	}
+	replaced := false
+	for i, r := range rules {
+		if r.Prefix != nil && *r.Prefix == prefix {
+			rules[i] = rule
+			replaced = true
+		}
+	}
+	if !replaced {
+		rules = append(rules, rule)
+	}
+
+	_, err = b.client.PutBucketLifecycle(&SDK.PutBucketLifecycleInput{
+		Bucket: String(b.name),
+		LifecycleConfiguration: &SDK.LifecycleConfiguration{
+			Rules: rules,
+		},
+	})
+	if err != nil {
+		err = wrapError("PutBucketLifecycle", err)
+		log.Error("[S3] error on `PutBucketLifecycle` operation, bucket="+b.name, err.Error())
+	}
+	return err
+}
+
+// fetch current lifecycle rules of the bucket
+func (b *Bucket) getLifecycleRules() ([]*SDK.LifecycleRule, error) {
+	out, err := b.client.GetBucketLifecycle(&SDK.GetBucketLifecycleInput{
+		Bucket: String(b.name),
+	})
+	if err != nil {
+		err = wrapError("GetBucketLifecycle", err)
+		if awserror.Code(err) == errCodeNoSuchLifecycle {
+			return nil, nil
+		}
+		log.Error("[S3] error on `GetBucketLifecycle` operation, bucket="+b.name, err.Error())
+		return nil, err
+	}
+	return out.Rules, nil
+}
+
 // delete object of target path
 func (b *Bucket) DeleteObject(path string) error {
 	_, err := b.client.DeleteObject(&SDK.DeleteObjectInput{
@@ -126,6 +360,7 @@ func (b *Bucket) DeleteObject(path string) error {
 		Key:    String(path),
 	})
 	if err != nil {
+		err = wrapError("DeleteObject", err)
 		log.Error("[S3] error on `DeleteObject` operation, bucket="+b.name, err.Error())
 	}
 	return err
@@ -1,9 +1,16 @@
 package s3
 
 import (
+	"bytes"
+	"compress/gzip"
 	"testing"
+	"time"
 
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 )
 
 var testS3Path = "/test_path"
@@ -87,6 +94,142 @@ func TestGetObjectByte(t *testing.T) {
 	assert.Equal(t, []byte{}, data)
 }
 
+func TestGetWithRetry(t *testing.T) {
+	setTestEnv()
+	TestPut(t)
+
+	f := openFile(t)
+	fs, _ := f.Stat()
+	defer f.Close()
+
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	data, err := b.GetWithRetry(testS3Path, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, int(fs.Size()), len(data))
+
+	// get from non existed path
+	data, err = b.GetWithRetry("/non_exist/path", 2)
+	assert.NotNil(t, err)
+	assert.Equal(t, errCodeNoSuchKey, awserror.Code(err))
+	assert.Nil(t, data)
+}
+
+func TestGetRetryBackoff(t *testing.T) {
+	assert.Equal(t, getRetryWait, getRetryBackoff(1))
+	assert.Equal(t, 2*getRetryWait, getRetryBackoff(2))
+	assert.Equal(t, 4*getRetryWait, getRetryBackoff(3))
+	assert.Equal(t, getRetryMaxWait, getRetryBackoff(100))
+}
+
+func TestBucketRetryBackoff(t *testing.T) {
+	b := &Bucket{}
+	assert.Equal(t, getRetryBackoff(2), b.retryBackoff(2))
+
+	b.backoff = &auth.Backoff{Base: time.Millisecond}
+	assert.Equal(t, 2*time.Millisecond, b.retryBackoff(2))
+}
+
+func TestHead(t *testing.T) {
+	setTestEnv()
+	TestPut(t)
+
+	f := openFile(t)
+	fs, _ := f.Stat()
+	defer f.Close()
+
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	// get existed data
+	etag, lastModified, size, err := b.Head(testS3Path)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", etag)
+	assert.False(t, lastModified.IsZero())
+	assert.Equal(t, fs.Size(), size)
+
+	// get from non existed path
+	etag, _, size, err = b.Head("/non_exist/path")
+	assert.NotNil(t, err)
+	assert.Equal(t, "", etag)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestDownloadDecoded(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	data := []byte("gzip encoded data")
+	gzData := new(bytes.Buffer)
+	gz := gzip.NewWriter(gzData)
+	gz.Write(data)
+	gz.Close()
+
+	size := int64(gzData.Len())
+	_, err := b.client.PutObject(&SDK.PutObjectInput{
+		Bucket:          String(testBucketName),
+		Key:             String("/test_gzip"),
+		Body:            bytes.NewReader(gzData.Bytes()),
+		ContentLength:   &size,
+		ContentEncoding: String("gzip"),
+	})
+	assert.Nil(t, err)
+
+	buf := new(bytes.Buffer)
+	err = b.DownloadDecoded("/test_gzip", buf)
+	assert.Nil(t, err)
+	assert.Equal(t, data, buf.Bytes())
+
+	// raw data
+	b.RawContentEncoding(true)
+	buf = new(bytes.Buffer)
+	err = b.DownloadDecoded("/test_gzip", buf)
+	assert.Nil(t, err)
+	assert.Equal(t, gzData.Bytes(), buf.Bytes())
+}
+
+func TestIsGzipEncoding(t *testing.T) {
+	assert.True(t, isGzipEncoding(String("gzip")))
+	assert.True(t, isGzipEncoding(String("x-gzip")))
+	assert.True(t, isGzipEncoding(String("identity, GZIP")))
+	assert.False(t, isGzipEncoding(String("br")))
+	assert.False(t, isGzipEncoding(nil))
+}
+
+func TestDownloadIfModified(t *testing.T) {
+	setTestEnv()
+	TestPut(t)
+
+	f := openFile(t)
+	fs, _ := f.Stat()
+	defer f.Close()
+
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+	etag, _, _, _ := b.Head(testS3Path)
+
+	// modified
+	buf := new(bytes.Buffer)
+	notModified, err := b.DownloadIfModified(testS3Path, "old-etag", buf)
+	assert.Nil(t, err)
+	assert.False(t, notModified)
+	assert.Equal(t, int(fs.Size()), buf.Len())
+
+	// not modified
+	buf = new(bytes.Buffer)
+	notModified, err = b.DownloadIfModified(testS3Path, etag, buf)
+	assert.Nil(t, err)
+	assert.True(t, notModified)
+	assert.Equal(t, 0, buf.Len())
+
+	// get from non existed path
+	notModified, err = b.DownloadIfModified("/non_exist/path", etag, buf)
+	assert.NotNil(t, err)
+	assert.False(t, notModified)
+}
+
 func TestGetURL(t *testing.T) {
 	setTestEnv()
 	s := NewClient()
@@ -145,6 +288,38 @@ func TestGetSecretURLWithExpire(t *testing.T) {
 	assert.Contains(t, data, "X-Amz-Expires=10")
 }
 
+func TestSetExpiration(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	err := b.SetExpiration("logs/", 30)
+	assert.Nil(t, err)
+	err = b.SetExpiration("tmp/", 1)
+	assert.Nil(t, err)
+
+	rules, err := b.getLifecycleRules()
+	assert.Nil(t, err)
+	assert.Len(t, rules, 2)
+
+	// replace the rule of same prefix
+	err = b.SetExpiration("logs/", 7)
+	assert.Nil(t, err)
+	rules, err = b.getLifecycleRules()
+	assert.Nil(t, err)
+	assert.Len(t, rules, 2)
+	for _, r := range rules {
+		switch *r.Prefix {
+		case "logs/":
+			assert.Equal(t, int64(7), *r.Expiration.Days)
+		case "tmp/":
+			assert.Equal(t, int64(1), *r.Expiration.Days)
+		default:
+			t.Errorf("unexpected rule, %v", r)
+		}
+	}
+}
+
 func TestDeleteObject(t *testing.T) {
 	setTestEnv()
 	TestPut(t)
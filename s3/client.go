@@ -3,30 +3,44 @@
 package s3
 
 import (
+	AWS "github.com/awslabs/aws-sdk-go/aws"
 	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"golang.org/x/time/rate"
 
 	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/evalphobia/aws-sdk-go-wrapper/config"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 )
 
 const (
+	serviceName         = "S3"
 	s3ConfigSectionName = "s3"
 	defaultRegion       = "us-east-1"
 	defaultEndpoint     = "http://localhost:4567"
 	defaultBucketPrefix = "dev-"
+
+	aclPrivate    = "private"
+	aclPublicRead = "public-read"
+
+	errCodeBucketAlreadyOwnedByYou = "BucketAlreadyOwnedByYou"
 )
 
 // wrapped struct for S3
 type AmazonS3 struct {
 	buckets map[string]*Bucket
 	client  *SDK.S3
+
+	// backoff of the retries, nil means the default backoff of each operation
+	backoff *auth.Backoff
+
+	// client-side rate limit of the requests
+	limiter auth.RateLimiterHolder
 }
 
 // Create new AmazonS3 struct
 func NewClient() *AmazonS3 {
-	s := &AmazonS3{}
-	s.buckets = make(map[string]*Bucket)
-	region := config.GetConfigValue(s3ConfigSectionName, "region", "")
+	region, regionErr := auth.ResolveRegion(s3ConfigSectionName)
 	awsConf := auth.NewConfig(region)
 	endpoint := config.GetConfigValue(s3ConfigSectionName, "endpoint", "")
 	switch {
@@ -34,15 +48,43 @@ func NewClient() *AmazonS3 {
 		awsConf.Endpoint = endpoint
 		awsConf.S3ForcePathStyle = true
 	case region == "":
+		log.Warn("[S3] "+regionErr.Error()+", use the local endpoint", defaultEndpoint)
 		awsConf.Region = defaultRegion
 		awsConf.Endpoint = defaultEndpoint
 		awsConf.S3ForcePathStyle = true
 	}
+	return NewClientWithConfig(awsConf)
+}
 
+// NewClientWithConfig creates new AmazonS3 struct with the AWS config,
+// the region, the endpoint and the credentials of the config file are not used
+func NewClientWithConfig(awsConf *AWS.Config) *AmazonS3 {
+	s := &AmazonS3{}
+	s.buckets = make(map[string]*Bucket)
 	s.client = SDK.New(awsConf)
+	auth.AddUserAgentSuffix(&s.client.Handlers, auth.UserAgentSuffix(s3ConfigSectionName))
+	s.SetBackoff(auth.DefaultBackoff())
+	auth.AddRateLimiter(&s.client.Handlers, s.limiter.Get)
+	s.SetRateLimiter(auth.ConfigRateLimiter(s3ConfigSectionName))
 	return s
 }
 
+// SetBackoff sets the backoff of the retries by the SDK and GetWithRetry of the buckets,
+// nil restores the default backoff of GetWithRetry (the SDK keeps the current rules)
+func (s *AmazonS3) SetBackoff(b *auth.Backoff) {
+	s.backoff = b
+	auth.SetRetryRules(s.client.Service, b)
+	for _, bucket := range s.buckets {
+		bucket.backoff = b
+	}
+}
+
+// SetRateLimiter sets the limiter to wait before sending every request of the client, nil means no limit.
+// share the limiter between the clients to limit the total requests
+func (s *AmazonS3) SetRateLimiter(l *rate.Limiter) {
+	s.limiter.Set(l)
+}
+
 // get bucket
 func (s *AmazonS3) GetBucket(bucket string) *Bucket {
 	prefix := config.GetConfigValue(s3ConfigSectionName, "prefix", defaultBucketPrefix)
@@ -57,6 +99,47 @@ func (s *AmazonS3) GetBucket(bucket string) *Bucket {
 	b = &Bucket{}
 	b.client = s.client
 	b.name = bucketName
+	b.downloadRetries = defaultDownloadRetries
+	b.backoff = s.backoff
 	s.buckets[bucketName] = b
 	return b
 }
+
+// CreateBucket creates the bucket with the prefix in the region, and returns the bucket.
+// the bucket is private by default and public-read when public is true,
+// the existing bucket owned by the same account is returned without error
+func (s *AmazonS3) CreateBucket(name, region string, public bool) (*Bucket, error) {
+	prefix := config.GetConfigValue(s3ConfigSectionName, "prefix", defaultBucketPrefix)
+	bucketName := prefix + name
+
+	_, err := s.client.CreateBucket(newCreateBucketInput(bucketName, region, public))
+	if err != nil && awserror.Code(err) != errCodeBucketAlreadyOwnedByYou {
+		err = wrapError("CreateBucket", err)
+		log.Error("[S3] error on `CreateBucket` operation, bucket="+bucketName, err.Error())
+		return nil, err
+	}
+	return s.GetBucket(name), nil
+}
+
+// create CreateBucketInput with the ACL and location,
+// LocationConstraint is omitted for us-east-1 because it returns error
+func newCreateBucketInput(bucketName, region string, public bool) *SDK.CreateBucketInput {
+	in := &SDK.CreateBucketInput{
+		Bucket: String(bucketName),
+		ACL:    String(aclPrivate),
+	}
+	if public {
+		in.ACL = String(aclPublicRead)
+	}
+	if region != "" && region != "us-east-1" {
+		in.CreateBucketConfiguration = &SDK.CreateBucketConfiguration{
+			LocationConstraint: String(region),
+		}
+	}
+	return in
+}
+
+// wrap the error of the operation with the request ID
+func wrapError(op string, err error) error {
+	return awserror.New(serviceName, op, err)
+}
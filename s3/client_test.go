@@ -39,3 +39,31 @@ func TestGetBucket(t *testing.T) {
 	b2 := s.GetBucket("test")
 	assert.Equal(t, b, b2)
 }
+
+func TestCreateBucket(t *testing.T) {
+	setTestEnv()
+
+	s := NewClient()
+	b, err := s.CreateBucket("test-create", "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, defaultBucketPrefix+"test-create", b.name)
+
+	// existing bucket
+	b2, err := s.CreateBucket("test-create", "", false)
+	assert.Nil(t, err)
+	assert.Equal(t, b, b2)
+}
+
+func TestNewCreateBucketInput(t *testing.T) {
+	in := newCreateBucketInput("foo", "us-east-1", false)
+	assert.Equal(t, "foo", *in.Bucket)
+	assert.Equal(t, aclPrivate, *in.ACL)
+	assert.Nil(t, in.CreateBucketConfiguration)
+
+	in = newCreateBucketInput("foo", "", true)
+	assert.Equal(t, aclPublicRead, *in.ACL)
+	assert.Nil(t, in.CreateBucketConfiguration)
+
+	in = newCreateBucketInput("foo", "ap-northeast-1", false)
+	assert.Equal(t, "ap-northeast-1", *in.CreateBucketConfiguration.LocationConstraint)
+}
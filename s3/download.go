@@ -0,0 +1,194 @@
+// S3 concurrent and resumable download
+
+package s3
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+const (
+	defaultDownloadPartSize    = 5 * 1024 * 1024
+	defaultDownloadConcurrency = 5
+
+	// default max number of resuming the interrupted download
+	defaultDownloadRetries = 3
+)
+
+// DownloadRetries sets the max number of resuming the interrupted download from the last offset,
+// 0 disables the resuming
+func (b *Bucket) DownloadRetries(n int) {
+	b.downloadRetries = n
+}
+
+// Download writes the object to w, and returns the written size.
+// when the connection is dropped in the middle of the body, the rest is requested by ranged GET from the last offset
+func (b *Bucket) Download(path string, w io.Writer) (int64, error) {
+	return b.downloadRange(path, w, 0, -1, nil)
+}
+
+// DownloadRange writes the range [first, last] of the object to w, and returns the written size,
+// the interrupted download is resumed as same as Download
+func (b *Bucket) DownloadRange(path string, w io.Writer, first, last int64) (int64, error) {
+	return b.downloadRange(path, w, first, last, nil)
+}
+
+// DownloadConcurrent downloads the object with parallel ranged GET requests,
+// and writes each part to its offset. returns the size of the object.
+// when a part is failed, outstanding requests are canceled and the first error is returned.
+func (b *Bucket) DownloadConcurrent(path string, w io.WriterAt, partSize int64, concurrency int) (int64, error) {
+	if partSize <= 0 {
+		partSize = defaultDownloadPartSize
+	}
+	if concurrency <= 0 {
+		concurrency = defaultDownloadConcurrency
+	}
+
+	_, _, size, err := b.Head(path)
+	if err != nil {
+		return 0, err
+	}
+
+	offsets := make(chan int64)
+	cancel := make(chan struct{})
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			close(cancel)
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				last := offset + partSize - 1
+				if last >= size {
+					last = size - 1
+				}
+				if err := b.downloadPart(path, w, offset, last, cancel); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for offset := int64(0); offset < size; offset += partSize {
+		select {
+		case offsets <- offset:
+		case <-cancel:
+			break dispatch
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if firstErr != nil {
+		return 0, firstErr
+	}
+	return size, nil
+}
+
+// download the range of the object and write it to the offset
+func (b *Bucket) downloadPart(path string, w io.WriterAt, first, last int64, cancel <-chan struct{}) error {
+	_, err := b.downloadRange(path, &offsetWriter{w: w, offset: first}, first, last, cancel)
+	return err
+}
+
+// download the range of the object (last=-1 means the end of the object),
+// and resume from the last offset when the body is interrupted
+func (b *Bucket) downloadRange(path string, w io.Writer, first, last int64, cancel <-chan struct{}) (int64, error) {
+	var written int64
+	for retry := 0; ; retry++ {
+		n, retryable, err := b.getRange(path, w, first+written, last, cancel)
+		written += n
+		if err == nil {
+			return written, nil
+		}
+		if !retryable || retry >= b.downloadRetries {
+			log.Error("[S3] error on downloading the object, bucket="+b.name, err.Error())
+			return written, err
+		}
+		log.Info("[S3] resume the interrupted download, bucket="+b.name+", path="+path, first+written)
+	}
+}
+
+// get the range of the object from the offset and copy the body to w,
+// returns true when the error can be resumed (the interrupted read or the error without 4xx response)
+func (b *Bucket) getRange(path string, w io.Writer, offset, last int64, cancel <-chan struct{}) (n int64, retryable bool, err error) {
+	in := &SDK.GetObjectInput{
+		Bucket: String(b.name),
+		Key:    String(path),
+	}
+	switch {
+	case last >= 0:
+		in.Range = String(fmt.Sprintf("bytes=%d-%d", offset, last))
+	case offset > 0:
+		in.Range = String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	req, out := b.client.GetObjectRequest(in)
+	req.HTTPRequest.Cancel = cancel
+	if err := req.Send(); err != nil {
+		err = wrapError("GetObject", err)
+		status := awserror.StatusCode(err)
+		return 0, !isCanceled(cancel) && (status < 400 || status >= 500), err
+	}
+	defer out.Body.Close()
+
+	r := &readErrorReader{r: out.Body}
+	n, err = io.Copy(w, r)
+	switch {
+	case err != nil:
+		// only the read error is resumable, not the write error
+		return n, err == r.err && !isCanceled(cancel), err
+	case out.ContentLength != nil && n < *out.ContentLength:
+		return n, !isCanceled(cancel), io.ErrUnexpectedEOF
+	}
+	return n, false, nil
+}
+
+// check if the download is canceled
+func isCanceled(cancel <-chan struct{}) bool {
+	select {
+	case <-cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// io.Reader which keeps the error of the read
+type readErrorReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *readErrorReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// io.Writer which writes to the offset of io.WriterAt sequentially
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
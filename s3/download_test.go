@@ -0,0 +1,120 @@
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadConcurrent(t *testing.T) {
+	setTestEnv()
+	TestPut(t)
+
+	f := openFile(t)
+	defer f.Close()
+	expected, _ := ioutil.ReadAll(f)
+
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	out, err := ioutil.TempFile("", "s3_download")
+	assert.Nil(t, err)
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	// download with small parts
+	size, err := b.DownloadConcurrent(testS3Path, out, 100, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(expected)), size)
+
+	actual, _ := ioutil.ReadFile(out.Name())
+	assert.True(t, bytes.Equal(expected, actual))
+
+	// get from non existed path
+	size, err = b.DownloadConcurrent("/non_exist/path", out, 100, 3)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestDownload(t *testing.T) {
+	setTestEnv()
+	TestPut(t)
+
+	f := openFile(t)
+	defer f.Close()
+	expected, _ := ioutil.ReadAll(f)
+
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	var buf bytes.Buffer
+	size, err := b.Download(testS3Path, &buf)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(expected)), size)
+	assert.True(t, bytes.Equal(expected, buf.Bytes()))
+
+	buf.Reset()
+	size, err = b.DownloadRange(testS3Path, &buf, 10, 19)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), size)
+	assert.True(t, bytes.Equal(expected[10:20], buf.Bytes()))
+
+	// get from non existed path, 4xx is not retried
+	buf.Reset()
+	size, err = b.Download("/non_exist/path", &buf)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestReadErrorReader(t *testing.T) {
+	errRead := errors.New("connection reset")
+	r := &readErrorReader{r: io.MultiReader(bytes.NewBufferString("abc"), &errorReader{errRead})}
+
+	n, err := io.Copy(ioutil.Discard, r)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, errRead, err)
+	assert.Equal(t, errRead, r.err)
+
+	r = &readErrorReader{r: bytes.NewBufferString("abc")}
+	_, err = io.Copy(ioutil.Discard, r)
+	assert.Nil(t, err)
+	assert.Nil(t, r.err)
+}
+
+func TestOffsetWriter(t *testing.T) {
+	f, err := ioutil.TempFile("", "s3_offset")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	f.Write([]byte("0123456789"))
+	w := &offsetWriter{w: f, offset: 3}
+	w.Write([]byte("ab"))
+	w.Write([]byte("cd"))
+	assert.Equal(t, int64(7), w.offset)
+
+	actual, _ := ioutil.ReadFile(f.Name())
+	assert.Equal(t, "012abcd789", string(actual))
+}
+
+func TestIsCanceled(t *testing.T) {
+	assert.False(t, isCanceled(nil))
+
+	cancel := make(chan struct{})
+	assert.False(t, isCanceled(cancel))
+	close(cancel)
+	assert.True(t, isCanceled(cancel))
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,188 @@
+// S3 resumable multipart upload
+
+package s3
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+const maxPartNumber = 10000
+
+// MultipartUpload is the multipart upload of the object,
+// persist Key and UploadID to resume the upload by ResumeUpload after the crash
+type MultipartUpload struct {
+	Key      string
+	UploadID string
+
+	bucket *Bucket
+
+	mu    sync.Mutex
+	parts map[int64]*string
+}
+
+// UploadedPart is the part already uploaded to the multipart upload
+type UploadedPart struct {
+	Number       int
+	ETag         string
+	Size         int64
+	LastModified time.Time
+}
+
+// StartUpload starts the multipart upload of the key
+func (b *Bucket) StartUpload(key string) (*MultipartUpload, error) {
+	out, err := b.client.CreateMultipartUpload(&SDK.CreateMultipartUploadInput{
+		Bucket: String(b.name),
+		Key:    String(key),
+	})
+	if err != nil {
+		err = wrapError("CreateMultipartUpload", err)
+		log.Error("[S3] error on `CreateMultipartUpload` operation, bucket="+b.name, err.Error())
+		return nil, err
+	}
+	return b.newMultipartUpload(key, stringValue(out.UploadID)), nil
+}
+
+// ResumeUpload restores the multipart upload from the key and the upload ID,
+// the parts already uploaded are listed and used on Complete
+func (b *Bucket) ResumeUpload(key, uploadID string) (*MultipartUpload, error) {
+	u := b.newMultipartUpload(key, uploadID)
+	if _, err := u.ListParts(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (b *Bucket) newMultipartUpload(key, uploadID string) *MultipartUpload {
+	return &MultipartUpload{
+		Key:      key,
+		UploadID: uploadID,
+		bucket:   b,
+		parts:    make(map[int64]*string),
+	}
+}
+
+// UploadPart uploads the data of r as the n-th part (1 to 10000) with Content-MD5,
+// the part except the last one must be 5MB or larger. uploading the same number again replaces the part
+func (u *MultipartUpload) UploadPart(n int, r io.Reader) error {
+	if n < 1 || n > maxPartNumber {
+		return fmt.Errorf("[S3] part number must be between 1 and %d, number=%d", maxPartNumber, n)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_, etag, err := u.bucket.uploadPart(u.Key, String(u.UploadID), int64(n), data)
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.parts[int64(n)] = etag
+	return nil
+}
+
+// ListParts lists the parts already uploaded to S3 in order of the part number,
+// use this to skip the uploaded parts on resuming
+func (u *MultipartUpload) ListParts() ([]UploadedPart, error) {
+	b := u.bucket
+	in := &SDK.ListPartsInput{
+		Bucket:   String(b.name),
+		Key:      String(u.Key),
+		UploadID: String(u.UploadID),
+	}
+
+	var parts []UploadedPart
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.PartNumberMarker, _ = token.(*int64)
+		out, err := b.client.ListParts(in)
+		if err != nil {
+			err = wrapError("ListParts", err)
+			log.Error("[S3] error on `ListParts` operation, bucket="+b.name, err.Error())
+			return nil, false, err
+		}
+		for _, p := range out.Parts {
+			parts = append(parts, newUploadedPart(p))
+		}
+		if out.IsTruncated == nil || !*out.IsTruncated {
+			return nil, true, nil
+		}
+		return out.NextPartNumberMarker, false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, p := range parts {
+		u.parts[int64(p.Number)] = String(p.ETag)
+	}
+	return parts, nil
+}
+
+// Complete completes the multipart upload with all of the uploaded parts in order of the part number
+func (u *MultipartUpload) Complete() error {
+	b := u.bucket
+	_, err := b.client.CompleteMultipartUpload(&SDK.CompleteMultipartUploadInput{
+		Bucket:          String(b.name),
+		Key:             String(u.Key),
+		UploadID:        String(u.UploadID),
+		MultipartUpload: &SDK.CompletedMultipartUpload{Parts: u.completedParts()},
+	})
+	if err != nil {
+		err = wrapError("CompleteMultipartUpload", err)
+		log.Error("[S3] error on `CompleteMultipartUpload` operation, bucket="+b.name, err.Error())
+		return err
+	}
+	return nil
+}
+
+// Abort aborts the multipart upload to discard the uploaded parts
+func (u *MultipartUpload) Abort() error {
+	return u.bucket.abortMultipart(u.Key, String(u.UploadID))
+}
+
+// get the parts for CompleteMultipartUpload in order of the part number
+func (u *MultipartUpload) completedParts() []*SDK.CompletedPart {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	parts := make([]*SDK.CompletedPart, 0, len(u.parts))
+	for num, etag := range u.parts {
+		n := num
+		parts = append(parts, &SDK.CompletedPart{
+			ETag:       etag,
+			PartNumber: &n,
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return *parts[i].PartNumber < *parts[j].PartNumber
+	})
+	return parts
+}
+
+func newUploadedPart(p *SDK.Part) UploadedPart {
+	part := UploadedPart{
+		ETag: stringValue(p.ETag),
+	}
+	if p.PartNumber != nil {
+		part.Number = int(*p.PartNumber)
+	}
+	if p.Size != nil {
+		part.Size = *p.Size
+	}
+	if p.LastModified != nil {
+		part.LastModified = *p.LastModified
+	}
+	return part
+}
@@ -0,0 +1,86 @@
+package s3
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultipartUpload(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	u, err := b.StartUpload("/test_multipart")
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", u.UploadID)
+
+	first := bytes.Repeat([]byte("a"), defaultUploadPartSize)
+	assert.Nil(t, u.UploadPart(1, bytes.NewReader(first)))
+
+	// resume from the persisted upload ID
+	resumed, err := b.ResumeUpload(u.Key, u.UploadID)
+	assert.Nil(t, err)
+	parts, err := resumed.ListParts()
+	assert.Nil(t, err)
+	assert.Len(t, parts, 1)
+	assert.Equal(t, 1, parts[0].Number)
+
+	assert.Nil(t, resumed.UploadPart(2, strings.NewReader("last part")))
+	assert.Nil(t, resumed.Complete())
+
+	data, err := b.GetObjectByte("/test_multipart")
+	assert.Nil(t, err)
+	assert.Equal(t, append(first, []byte("last part")...), data)
+}
+
+func TestMultipartUploadAbort(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	u, err := b.StartUpload("/test_multipart_abort")
+	assert.Nil(t, err)
+	assert.Nil(t, u.UploadPart(1, strings.NewReader("data")))
+	assert.Nil(t, u.Abort())
+}
+
+func TestUploadPartNumber(t *testing.T) {
+	u := (&Bucket{}).newMultipartUpload("key", "upload-id")
+	assert.NotNil(t, u.UploadPart(0, strings.NewReader("data")))
+	assert.NotNil(t, u.UploadPart(maxPartNumber+1, strings.NewReader("data")))
+}
+
+func TestCompletedParts(t *testing.T) {
+	u := (&Bucket{}).newMultipartUpload("key", "upload-id")
+	u.parts[3] = String(`"c"`)
+	u.parts[1] = String(`"a"`)
+	u.parts[2] = String(`"b"`)
+
+	parts := u.completedParts()
+	assert.Len(t, parts, 3)
+	for i, p := range parts {
+		assert.Equal(t, int64(i+1), *p.PartNumber)
+	}
+	assert.Equal(t, `"a"`, *parts[0].ETag)
+	assert.Equal(t, `"c"`, *parts[2].ETag)
+}
+
+func TestNewUploadedPart(t *testing.T) {
+	num := int64(2)
+	size := int64(100)
+	now := time.Now()
+	p := newUploadedPart(&SDK.Part{
+		ETag:         String(`"etag"`),
+		PartNumber:   &num,
+		Size:         &size,
+		LastModified: &now,
+	})
+	assert.Equal(t, UploadedPart{Number: 2, ETag: `"etag"`, Size: 100, LastModified: now}, p)
+
+	assert.Equal(t, UploadedPart{}, newUploadedPart(&SDK.Part{}))
+}
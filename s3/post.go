@@ -0,0 +1,139 @@
+// S3 presigned POST policy for browser form uploads
+
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/awslabs/aws-sdk-go/aws/credentials"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+const (
+	postPolicyAlgorithm = "AWS4-HMAC-SHA256"
+	postPolicyService   = "s3"
+
+	iso8601BasicFormat      = "20060102T150405Z"
+	iso8601BasicDateFormat  = "20060102"
+	postPolicyExpiredFormat = "2006-01-02T15:04:05.000Z"
+)
+
+// PostPolicy is the form of the presigned POST upload,
+// the browser sends the multipart form with Fields, Content-Type and the file (must be the last field) to URL
+type PostPolicy struct {
+	URL     string
+	Fields  map[string]string
+	Expires time.Time
+
+	// Conditions is the conditions of the policy document
+	Conditions []interface{}
+}
+
+// PresignedPost creates the presigned POST policy to upload the object of the key from the browser,
+// the size of the file is limited up to maxSize bytes and Content-Type must start with contentTypePrefix (empty means any type).
+// the policy is signed by Signature Version 4 with the credentials of the client
+func (b *Bucket) PresignedPost(key string, maxSize int64, contentTypePrefix string, expires time.Duration) (*PostPolicy, error) {
+	switch {
+	case key == "":
+		return nil, errors.New("[S3] key must not be empty")
+	case maxSize <= 0:
+		return nil, errors.New("[S3] maxSize must be greater than 0")
+	case expires <= 0:
+		return nil, errors.New("[S3] expires must be greater than 0")
+	}
+
+	conf := b.client.Config
+	if conf == nil || conf.Credentials == nil {
+		return nil, errors.New("[S3] credentials are not set")
+	}
+	cred, err := conf.Credentials.Get()
+	if err != nil {
+		log.Error("[S3] error on getting the credentials, bucket="+b.name, err.Error())
+		return nil, err
+	}
+	region := conf.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	p, err := newPostPolicy(b.name, key, maxSize, contentTypePrefix, cred, region, time.Now().UTC(), expires)
+	if err != nil {
+		return nil, err
+	}
+	p.URL = postURL(b.name, region, conf.Endpoint)
+	return p, nil
+}
+
+// create and sign the policy document of the upload
+func newPostPolicy(bucket, key string, maxSize int64, contentTypePrefix string, cred credentials.Value, region string, now time.Time, expires time.Duration) (*PostPolicy, error) {
+	date := now.Format(iso8601BasicFormat)
+	credential := strings.Join([]string{cred.AccessKeyID, now.Format(iso8601BasicDateFormat), region, postPolicyService, "aws4_request"}, "/")
+
+	fields := map[string]string{
+		"key":              key,
+		"x-amz-algorithm":  postPolicyAlgorithm,
+		"x-amz-credential": credential,
+		"x-amz-date":       date,
+	}
+	conditions := []interface{}{
+		map[string]string{"bucket": bucket},
+		[]interface{}{"eq", "$key", key},
+		[]interface{}{"content-length-range", 0, maxSize},
+		[]interface{}{"starts-with", "$Content-Type", contentTypePrefix},
+		map[string]string{"x-amz-algorithm": postPolicyAlgorithm},
+		map[string]string{"x-amz-credential": credential},
+		map[string]string{"x-amz-date": date},
+	}
+	if cred.SessionToken != "" {
+		fields["x-amz-security-token"] = cred.SessionToken
+		conditions = append(conditions, map[string]string{"x-amz-security-token": cred.SessionToken})
+	}
+
+	expiration := now.Add(expires)
+	doc, err := json.Marshal(map[string]interface{}{
+		"expiration": expiration.Format(postPolicyExpiredFormat),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	policy := base64.StdEncoding.EncodeToString(doc)
+	sk := signingKey(cred.SecretAccessKey, now.Format(iso8601BasicDateFormat), region, postPolicyService)
+	fields["policy"] = policy
+	fields["x-amz-signature"] = hex.EncodeToString(hmacSHA256(sk, []byte(policy)))
+
+	return &PostPolicy{
+		Fields:     fields,
+		Expires:    expiration,
+		Conditions: conditions,
+	}, nil
+}
+
+// get the URL of the form action, the endpoint of the config uses path-style
+func postURL(bucket, region, endpoint string) string {
+	if endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + "/" + bucket
+	}
+	return "https://" + bucket + ".s3." + region + ".amazonaws.com/"
+}
+
+// derive the signing key of Signature Version 4
+func signingKey(secret, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), []byte(date))
+	k = hmacSHA256(k, []byte(region))
+	k = hmacSHA256(k, []byte(service))
+	return hmacSHA256(k, []byte("aws4_request"))
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,61 @@
+package s3
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/awslabs/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresignedPostError(t *testing.T) {
+	b := &Bucket{}
+	_, err := b.PresignedPost("", 100, "image/", time.Minute)
+	assert.NotNil(t, err)
+	_, err = b.PresignedPost("foo.png", 0, "image/", time.Minute)
+	assert.NotNil(t, err)
+	_, err = b.PresignedPost("foo.png", 100, "image/", 0)
+	assert.NotNil(t, err)
+}
+
+func TestNewPostPolicy(t *testing.T) {
+	now := time.Date(2015, 5, 1, 12, 0, 0, 0, time.UTC)
+	cred := credentials.Value{AccessKeyID: "AKID", SecretAccessKey: "SECRET", SessionToken: "TOKEN"}
+	p, err := newPostPolicy("test-bucket", "uploads/foo.png", 1024, "image/", cred, "ap-northeast-1", now, 10*time.Minute)
+	assert.Nil(t, err)
+	assert.Equal(t, now.Add(10*time.Minute), p.Expires)
+	assert.Equal(t, "uploads/foo.png", p.Fields["key"])
+	assert.Equal(t, "AWS4-HMAC-SHA256", p.Fields["x-amz-algorithm"])
+	assert.Equal(t, "AKID/20150501/ap-northeast-1/s3/aws4_request", p.Fields["x-amz-credential"])
+	assert.Equal(t, "20150501T120000Z", p.Fields["x-amz-date"])
+	assert.Equal(t, "TOKEN", p.Fields["x-amz-security-token"])
+
+	doc, err := base64.StdEncoding.DecodeString(p.Fields["policy"])
+	assert.Nil(t, err)
+	var policy struct {
+		Expiration string        `json:"expiration"`
+		Conditions []interface{} `json:"conditions"`
+	}
+	assert.Nil(t, json.Unmarshal(doc, &policy))
+	assert.Equal(t, "2015-05-01T12:10:00.000Z", policy.Expiration)
+	assert.Contains(t, policy.Conditions, []interface{}{"content-length-range", float64(0), float64(1024)})
+	assert.Contains(t, policy.Conditions, []interface{}{"starts-with", "$Content-Type", "image/"})
+	assert.Contains(t, policy.Conditions, map[string]interface{}{"bucket": "test-bucket"})
+
+	sk := signingKey("SECRET", "20150501", "ap-northeast-1", "s3")
+	assert.Equal(t, hex.EncodeToString(hmacSHA256(sk, []byte(p.Fields["policy"]))), p.Fields["x-amz-signature"])
+}
+
+func TestSigningKey(t *testing.T) {
+	// the example of Signature Version 4 document
+	k := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(k))
+}
+
+func TestPostURL(t *testing.T) {
+	assert.Equal(t, "https://test-bucket.s3.us-west-2.amazonaws.com/", postURL("test-bucket", "us-west-2", ""))
+	assert.Equal(t, "http://localhost:4567/test-bucket", postURL("test-bucket", "us-east-1", "http://localhost:4567/"))
+}
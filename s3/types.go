@@ -5,3 +5,11 @@ package s3
 func String(v string) *string {
 	return &v
 }
+
+// get the string of the pointer, nil is converted to empty string
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
@@ -0,0 +1,189 @@
+// S3 verified upload
+
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+const defaultUploadPartSize = 5 * 1024 * 1024
+
+// PutVerified uploads the object from the stream with Content-MD5, and verifies the ETag of the response.
+// returns hex encoded MD5 and SHA256 of the whole stream.
+// the stream larger than or equal to the part size (5MB) is uploaded by multipart,
+// and MD5 is the composite value same as the ETag of multipart upload (e.g. `<md5 of part md5s>-<number of parts>`).
+func (b *Bucket) PutVerified(key string, r io.Reader) (md5sum, sha256sum string, err error) {
+	sha := sha256.New()
+	tee := io.TeeReader(r, sha)
+
+	buf := make([]byte, defaultUploadPartSize)
+	n, err := io.ReadFull(tee, buf)
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF:
+		md5sum, err = b.putSingle(key, buf[:n])
+	case nil:
+		md5sum, err = b.putMultipart(key, buf, tee)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return md5sum, hex.EncodeToString(sha.Sum(nil)), nil
+}
+
+// upload the data by single PutObject request
+func (b *Bucket) putSingle(key string, data []byte) (string, error) {
+	sum := md5.Sum(data)
+	size := int64(len(data))
+	out, err := b.client.PutObject(&SDK.PutObjectInput{
+		Bucket:        String(b.name),
+		Key:           String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: &size,
+		ContentMD5:    String(base64.StdEncoding.EncodeToString(sum[:])),
+		ContentType:   String(http.DetectContentType(data)),
+	})
+	if err != nil {
+		err = wrapError("PutObject", err)
+		log.Error("[S3] error on `PutObject` operation, bucket="+b.name, err.Error())
+		return "", err
+	}
+
+	md5sum := hex.EncodeToString(sum[:])
+	if err := verifyETag(out.ETag, md5sum); err != nil {
+		log.Error("[S3] error on verifying the object, bucket="+b.name, err.Error())
+		return "", err
+	}
+	return md5sum, nil
+}
+
+// upload the data by multipart upload, the first part is already read into buf.
+// the upload is aborted when a part is failed
+func (b *Bucket) putMultipart(key string, buf []byte, r io.Reader) (string, error) {
+	created, err := b.client.CreateMultipartUpload(&SDK.CreateMultipartUploadInput{
+		Bucket:      String(b.name),
+		Key:         String(key),
+		ContentType: String(http.DetectContentType(buf)),
+	})
+	if err != nil {
+		err = wrapError("CreateMultipartUpload", err)
+		log.Error("[S3] error on `CreateMultipartUpload` operation, bucket="+b.name, err.Error())
+		return "", err
+	}
+	uploadID := created.UploadID
+
+	var sums [][]byte
+	var parts []*SDK.CompletedPart
+	data := buf
+	for num := int64(1); ; num++ {
+		sum, etag, err := b.uploadPart(key, uploadID, num, data)
+		if err != nil {
+			b.abortMultipart(key, uploadID)
+			return "", err
+		}
+		partNumber := num
+		sums = append(sums, sum)
+		parts = append(parts, &SDK.CompletedPart{
+			ETag:       etag,
+			PartNumber: &partNumber,
+		})
+
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			b.abortMultipart(key, uploadID)
+			return "", err
+		}
+		data = buf[:n]
+	}
+
+	out, err := b.client.CompleteMultipartUpload(&SDK.CompleteMultipartUploadInput{
+		Bucket:          String(b.name),
+		Key:             String(key),
+		UploadID:        uploadID,
+		MultipartUpload: &SDK.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		err = wrapError("CompleteMultipartUpload", err)
+		log.Error("[S3] error on `CompleteMultipartUpload` operation, bucket="+b.name, err.Error())
+		b.abortMultipart(key, uploadID)
+		return "", err
+	}
+
+	md5sum := compositeETag(sums)
+	if err := verifyETag(out.ETag, md5sum); err != nil {
+		log.Error("[S3] error on verifying the object, bucket="+b.name, err.Error())
+		return "", err
+	}
+	return md5sum, nil
+}
+
+// upload the part with Content-MD5 and returns MD5 of the part and the ETag returned by S3
+func (b *Bucket) uploadPart(key string, uploadID *string, num int64, data []byte) (md5sum []byte, etag *string, err error) {
+	sum := md5.Sum(data)
+	size := int64(len(data))
+	out, err := b.client.UploadPart(&SDK.UploadPartInput{
+		Bucket:        String(b.name),
+		Key:           String(key),
+		UploadID:      uploadID,
+		PartNumber:    &num,
+		Body:          bytes.NewReader(data),
+		ContentLength: &size,
+		ContentMD5:    String(base64.StdEncoding.EncodeToString(sum[:])),
+	})
+	if err != nil {
+		err = wrapError("UploadPart", err)
+		log.Error("[S3] error on `UploadPart` operation, bucket="+b.name, err.Error())
+		return nil, nil, err
+	}
+	return sum[:], out.ETag, nil
+}
+
+// abort the multipart upload to discard the uploaded parts
+func (b *Bucket) abortMultipart(key string, uploadID *string) error {
+	_, err := b.client.AbortMultipartUpload(&SDK.AbortMultipartUploadInput{
+		Bucket:   String(b.name),
+		Key:      String(key),
+		UploadID: uploadID,
+	})
+	if err != nil {
+		err = wrapError("AbortMultipartUpload", err)
+		log.Error("[S3] error on `AbortMultipartUpload` operation, bucket="+b.name, err.Error())
+		return err
+	}
+	return nil
+}
+
+// get the ETag equivalent of multipart upload from MD5 of the parts
+func compositeETag(sums [][]byte) string {
+	h := md5.New()
+	for _, sum := range sums {
+		h.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(h.Sum(nil)), len(sums))
+}
+
+// check the ETag of the response is same as the computed MD5
+func verifyETag(etag *string, md5sum string) error {
+	if etag == nil {
+		return nil
+	}
+	actual := strings.Trim(*etag, `"`)
+	if actual != md5sum {
+		return errors.New("[S3] ETag mismatch, etag=" + actual + ", md5=" + md5sum)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutVerified(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	data := []byte("verified upload data")
+	md5sum, sha256sum, err := b.PutVerified("/test_verified", bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	expectedMD5 := md5.Sum(data)
+	expectedSHA := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(expectedMD5[:]), md5sum)
+	assert.Equal(t, hex.EncodeToString(expectedSHA[:]), sha256sum)
+
+	actual, err := b.GetObjectByte("/test_verified")
+	assert.Nil(t, err)
+	assert.Equal(t, data, actual)
+}
+
+func TestPutVerifiedMultipart(t *testing.T) {
+	setTestEnv()
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	data := bytes.Repeat([]byte("a"), defaultUploadPartSize+1)
+	md5sum, sha256sum, err := b.PutVerified("/test_verified_multipart", bytes.NewReader(data))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(md5sum, "-2"))
+
+	expectedSHA := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(expectedSHA[:]), sha256sum)
+}
+
+func TestCompositeETag(t *testing.T) {
+	part1 := md5.Sum([]byte("foo"))
+	part2 := md5.Sum([]byte("bar"))
+	expected := md5.Sum(append(part1[:], part2[:]...))
+
+	etag := compositeETag([][]byte{part1[:], part2[:]})
+	assert.Equal(t, hex.EncodeToString(expected[:])+"-2", etag)
+}
+
+func TestVerifyETag(t *testing.T) {
+	assert.Nil(t, verifyETag(String(`"abc"`), "abc"))
+	assert.Nil(t, verifyETag(String("abc-2"), "abc-2"))
+	assert.Nil(t, verifyETag(nil, "abc"))
+	assert.NotNil(t, verifyETag(String(`"abc"`), "def"))
+}
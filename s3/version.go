@@ -0,0 +1,138 @@
+// S3 object versioning
+
+package s3
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
+	"github.com/evalphobia/aws-sdk-go-wrapper/log"
+)
+
+// ObjectVersion is the version of the object in the versioning-enabled bucket
+type ObjectVersion struct {
+	Key          string
+	VersionID    string
+	IsLatest     bool
+	Size         int64
+	LastModified time.Time
+
+	// DeleteMarker is true when the version is the delete marker, which has no data
+	DeleteMarker bool
+}
+
+// the markers of the next page of ListObjectVersions
+type versionMarker struct {
+	key       *string
+	versionID *string
+}
+
+// GetObjectVersion writes the data of the version of the object to w
+func (b *Bucket) GetObjectVersion(path, versionID string, w io.Writer) error {
+	out, err := b.client.GetObject(&SDK.GetObjectInput{
+		Bucket:    String(b.name),
+		Key:       String(path),
+		VersionID: String(versionID),
+	})
+	if err != nil {
+		err = wrapError("GetObject", err)
+		log.Error("[S3] error on `GetObject` operation, bucket="+b.name, err.Error())
+		return err
+	}
+	defer out.Body.Close()
+
+	_, err = io.Copy(w, out.Body)
+	return err
+}
+
+// DeleteObjectVersion deletes the version of the object permanently
+func (b *Bucket) DeleteObjectVersion(path, versionID string) error {
+	_, err := b.client.DeleteObject(&SDK.DeleteObjectInput{
+		Bucket:    String(b.name),
+		Key:       String(path),
+		VersionID: String(versionID),
+	})
+	if err != nil {
+		err = wrapError("DeleteObject", err)
+		log.Error("[S3] error on `DeleteObject` operation, bucket="+b.name, err.Error())
+	}
+	return err
+}
+
+// ListObjectVersions retrieves all of the versions and the delete markers of the objects with the prefix until the last page,
+// the versions are ordered by the key and the newest version comes first for each key
+func (b *Bucket) ListObjectVersions(prefix string) ([]ObjectVersion, error) {
+	in := &SDK.ListObjectVersionsInput{
+		Bucket: String(b.name),
+	}
+	if prefix != "" {
+		in.Prefix = String(prefix)
+	}
+
+	var versions []ObjectVersion
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		if m, ok := token.(versionMarker); ok {
+			in.KeyMarker = m.key
+			in.VersionIDMarker = m.versionID
+		}
+		out, err := b.client.ListObjectVersions(in)
+		if err != nil {
+			err = wrapError("ListObjectVersions", err)
+			log.Error("[S3] error on `ListObjectVersions` operation, bucket="+b.name, err.Error())
+			return nil, false, err
+		}
+		versions = append(versions, newObjectVersions(out)...)
+
+		if out.IsTruncated == nil || !*out.IsTruncated {
+			return nil, true, nil
+		}
+		return versionMarker{key: out.NextKeyMarker, versionID: out.NextVersionIDMarker}, false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// create ObjectVersion list from the versions and the delete markers of the page
+func newObjectVersions(out *SDK.ListObjectVersionsOutput) []ObjectVersion {
+	list := make([]ObjectVersion, 0, len(out.Versions)+len(out.DeleteMarkers))
+	for _, v := range out.Versions {
+		ov := ObjectVersion{
+			Key:       stringValue(v.Key),
+			VersionID: stringValue(v.VersionID),
+			IsLatest:  v.IsLatest != nil && *v.IsLatest,
+		}
+		if v.Size != nil {
+			ov.Size = *v.Size
+		}
+		if v.LastModified != nil {
+			ov.LastModified = *v.LastModified
+		}
+		list = append(list, ov)
+	}
+	for _, m := range out.DeleteMarkers {
+		ov := ObjectVersion{
+			Key:          stringValue(m.Key),
+			VersionID:    stringValue(m.VersionID),
+			IsLatest:     m.IsLatest != nil && *m.IsLatest,
+			DeleteMarker: true,
+		}
+		if m.LastModified != nil {
+			ov.LastModified = *m.LastModified
+		}
+		list = append(list, ov)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Key != list[j].Key {
+			return list[i].Key < list[j].Key
+		}
+		return list[i].LastModified.After(list[j].LastModified)
+	})
+	return list
+}
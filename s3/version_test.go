@@ -0,0 +1,51 @@
+package s3
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectVersion(t *testing.T) {
+	setTestEnv()
+	TestPut(t)
+
+	s := NewClient()
+	b := s.GetBucket(testBucketName)
+
+	versions, err := b.ListObjectVersions(testS3Path)
+	assert.Nil(t, err)
+	if assert.NotEmpty(t, versions) {
+		v := versions[0]
+		assert.Equal(t, testS3Path, v.Key)
+
+		var buf bytes.Buffer
+		err = b.GetObjectVersion(v.Key, v.VersionID, &buf)
+		assert.Nil(t, err)
+		assert.Equal(t, v.Size, int64(buf.Len()))
+	}
+
+	err = b.GetObjectVersion("/non_exist/path", "null", &bytes.Buffer{})
+	assert.NotNil(t, err)
+}
+
+func TestNewObjectVersions(t *testing.T) {
+	now := time.Now()
+	out := &SDK.ListObjectVersionsOutput{
+		Versions: []*SDK.ObjectVersion{
+			{Key: String("b"), VersionID: String("b1"), IsLatest: &[]bool{true}[0], Size: &[]int64{10}[0], LastModified: &now},
+			{Key: String("a"), VersionID: String("a1"), LastModified: &[]time.Time{now.Add(-time.Hour)}[0]},
+		},
+		DeleteMarkers: []*SDK.DeleteMarkerEntry{
+			{Key: String("a"), VersionID: String("a2"), IsLatest: &[]bool{true}[0], LastModified: &now},
+		},
+	}
+	versions := newObjectVersions(out)
+	assert.Len(t, versions, 3)
+	assert.Equal(t, ObjectVersion{Key: "a", VersionID: "a2", IsLatest: true, LastModified: now, DeleteMarker: true}, versions[0])
+	assert.Equal(t, "a1", versions[1].VersionID)
+	assert.Equal(t, ObjectVersion{Key: "b", VersionID: "b1", IsLatest: true, Size: 10, LastModified: now}, versions[2])
+}
@@ -2,12 +2,6 @@
 
 package sns
 
-import (
-	SDK "github.com/awslabs/aws-sdk-go/service/sns"
-
-	"github.com/evalphobia/aws-sdk-go-wrapper/log"
-)
-
 type SNSApp struct {
 	svc      *AmazonSNS
 	platform string
@@ -24,16 +18,7 @@ func NewApp(arn, pf string, svc *AmazonSNS) *SNSApp {
 
 // Create Endpoint(add device) and return `EndpointARN`
 func (a *SNSApp) createEndpoint(token string) (string, error) {
-	in := &SDK.CreatePlatformEndpointInput{
-		PlatformApplicationARN: String(a.arn),
-		Token: String(token),
-	}
-	resp, err := a.svc.Client.CreatePlatformEndpoint(in)
-	if err != nil {
-		log.Error("[SNS] error on `CreatePlatformEndpoint` operation, token="+token, err.Error())
-		return "", err
-	}
-	return *resp.EndpointARN, nil
+	return a.svc.CreatePlatformEndpoint(a.arn, token)
 }
 
 // Create Endpoint(add device) and return `EndpointARN`
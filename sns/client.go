@@ -6,18 +6,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
 	"sync"
 	"time"
 	"unicode/utf8"
 
+	AWS "github.com/awslabs/aws-sdk-go/aws"
 	SDK "github.com/awslabs/aws-sdk-go/service/sns"
+	"golang.org/x/time/rate"
 
 	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/evalphobia/aws-sdk-go-wrapper/config"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 )
 
 const (
+	serviceName          = "SNS"
 	snsConfigSectionName = "sns"
 	defaultRegion        = "us-west-1"
 	defaultEndpoint      = "http://localhost:9292"
@@ -29,32 +34,50 @@ const (
 
 	topicMaxDeviceNumber = 10000
 	MessageBodyLimit     = 2000
+
+	errCodeInvalidParameter = "InvalidParameter"
 )
 
+var existingEndpointPattern = regexp.MustCompile(`Endpoint (arn:\S+) already exists`)
+
 var isProduction bool
 
 type AmazonSNS struct {
 	apps   map[string]*SNSApp
 	topics map[string]*SNSTopic
 	Client *SDK.SNS
+
+	// client-side rate limit of the requests
+	limiter auth.RateLimiterHolder
 }
 
 // Create new AmazonSQS struct
 func NewClient() *AmazonSNS {
-	svc := &AmazonSNS{}
-	svc.apps = make(map[string]*SNSApp)
-	svc.topics = make(map[string]*SNSTopic)
-	region := config.GetConfigValue(snsConfigSectionName, "region", auth.EnvRegion())
+	region, regionErr := auth.ResolveRegion(snsConfigSectionName)
 	awsConf := auth.NewConfig(region)
 	endpoint := config.GetConfigValue(snsConfigSectionName, "endpoint", "")
 	switch {
 	case endpoint != "":
 		awsConf.Endpoint = endpoint
 	case region == "":
+		log.Warn("[SNS] "+regionErr.Error()+", use the local endpoint", defaultEndpoint)
 		awsConf.Region = defaultRegion
 		awsConf.Endpoint = defaultEndpoint
 	}
+	return NewClientWithConfig(awsConf)
+}
+
+// NewClientWithConfig creates new AmazonSNS struct with the AWS config,
+// the region, the endpoint and the credentials of the config file are not used
+func NewClientWithConfig(awsConf *AWS.Config) *AmazonSNS {
+	svc := &AmazonSNS{}
+	svc.apps = make(map[string]*SNSApp)
+	svc.topics = make(map[string]*SNSTopic)
 	svc.Client = SDK.New(awsConf)
+	auth.AddUserAgentSuffix(&svc.Client.Handlers, auth.UserAgentSuffix(snsConfigSectionName))
+	svc.SetBackoff(auth.DefaultBackoff())
+	auth.AddRateLimiter(&svc.Client.Handlers, svc.limiter.Get)
+	svc.SetRateLimiter(auth.ConfigRateLimiter(snsConfigSectionName))
 	if config.GetConfigValue(snsConfigSectionName, "app.production", "false") != "false" {
 		isProduction = true
 	} else {
@@ -63,6 +86,17 @@ func NewClient() *AmazonSNS {
 	return svc
 }
 
+// SetBackoff sets the backoff of the retries by the SDK, nil keeps the current rules
+func (svc *AmazonSNS) SetBackoff(b *auth.Backoff) {
+	auth.SetRetryRules(svc.Client.Service, b)
+}
+
+// SetRateLimiter sets the limiter to wait before sending every request of the client, nil means no limit.
+// share the limiter between the clients to limit the total requests
+func (svc *AmazonSNS) SetRateLimiter(l *rate.Limiter) {
+	svc.limiter.Set(l)
+}
+
 // Get SNSApp struct
 func (svc *AmazonSNS) GetApp(typ string) (*SNSApp, error) {
 	// get the app from cache
@@ -103,6 +137,7 @@ func (svc *AmazonSNS) createTopic(name string) (string, error) {
 	}
 	resp, err := svc.Client.CreateTopic(in)
 	if err != nil {
+		err = wrapError("CreateTopic", err)
 		log.Error("[SNS] error on `CreateTopic` operation, name="+name, err.Error())
 		return "", err
 	}
@@ -134,6 +169,7 @@ func (svc *AmazonSNS) Publish(arn string, msg string, opt map[string]interface{}
 		MessageStructure: String("json"),
 	})
 	if err != nil {
+		err = wrapError("Publish", err)
 		log.Error("[SNS] error on `Publish` operation, arn="+arn, err.Error())
 		return err
 	}
@@ -141,6 +177,61 @@ func (svc *AmazonSNS) Publish(arn string, msg string, opt map[string]interface{}
 	return nil
 }
 
+// PublishToEndpoint publishes the payload data to the platform endpoint,
+// the value of "message" in the payload is used for the default message and APNS alert
+func (svc *AmazonSNS) PublishToEndpoint(endpointARN string, payload map[string]string) error {
+	m := make(map[string]string)
+	m["default"] = payload[payloadMessageKey]
+	m["GCM"] = composePayloadGCM(payload)
+	m["APNS"] = composePayloadAPNS(payload)
+	m["APNS_SANDBOX"] = m["APNS"]
+	jsonString, _ := json.Marshal(m)
+	resp, err := svc.Client.Publish(&SDK.PublishInput{
+		TargetARN:        String(endpointARN),
+		Message:          String(string(jsonString)),
+		MessageStructure: String("json"),
+	})
+	if err != nil {
+		err = wrapError("Publish", err)
+		log.Error("[SNS] error on `Publish` operation, arn="+endpointARN, err.Error())
+		return err
+	}
+	log.Info("[SNS] publish message", *resp.MessageID)
+	return nil
+}
+
+// CreatePlatformEndpoint creates the endpoint of the device token on the platform application and returns `EndpointARN`,
+// the ARN of the existing endpoint is returned when the endpoint already exists with the different attributes
+func (svc *AmazonSNS) CreatePlatformEndpoint(platformAppARN, deviceToken string) (endpointARN string, err error) {
+	in := &SDK.CreatePlatformEndpointInput{
+		PlatformApplicationARN: String(platformAppARN),
+		Token:                  String(deviceToken),
+	}
+	resp, err := svc.Client.CreatePlatformEndpoint(in)
+	if err != nil {
+		err = wrapError("CreatePlatformEndpoint", err)
+		if arn, ok := existingEndpointARN(err); ok {
+			return arn, nil
+		}
+		log.Error("[SNS] error on `CreatePlatformEndpoint` operation, token="+deviceToken, err.Error())
+		return "", err
+	}
+	return *resp.EndpointARN, nil
+}
+
+// extract the ARN of the existing endpoint from the error message of CreatePlatformEndpoint,
+// e.g.) "Invalid parameter: Token Reason: Endpoint arn:aws:sns:... already exists with the same Token, but different attributes."
+func existingEndpointARN(err error) (string, bool) {
+	if awserror.Code(err) != errCodeInvalidParameter {
+		return "", false
+	}
+	m := existingEndpointPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
 // Limit message size to the allowed payload size
 func truncateMessage(msg string) string {
 	if len(msg) <= MessageBodyLimit {
@@ -224,3 +315,8 @@ func (svc *AmazonSNS) BulkPublish(tokens map[string][]string, msg string) error
 	}
 	return nil
 }
+
+// wrap the error of the operation with the request ID
+func wrapError(op string, err error) error {
+	return awserror.New(serviceName, op, err)
+}
@@ -117,6 +117,53 @@ func TestPublish(t *testing.T) {
 	_ = err
 }
 
+func TestPublishToEndpoint(t *testing.T) {
+	setTestEnv()
+
+	svc := NewClient()
+	err := svc.PublishToEndpoint("arn", map[string]string{"message": "msg", "id": "1"})
+
+	t.Skip("fakesns does not implement Publish() yet.")
+	_ = err
+}
+
+func TestCreatePlatformEndpoint(t *testing.T) {
+	setTestEnv()
+
+	svc := NewClient()
+	if svc.Client.Endpoint == defaultEndpoint {
+		t.Skip("fakesns does not implement CreatePlatformEndpoint() yet.")
+	}
+
+	arn, err := svc.CreatePlatformEndpoint("arn", "token")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, arn)
+}
+
+type testAWSError struct {
+	code string
+	msg  string
+}
+
+func (e testAWSError) Error() string   { return e.code + ": " + e.msg }
+func (e testAWSError) Code() string    { return e.code }
+func (e testAWSError) Message() string { return e.msg }
+func (e testAWSError) OrigErr() error  { return nil }
+
+func TestExistingEndpointARN(t *testing.T) {
+	arn := "arn:aws:sns:us-east-1:0000000000:endpoint/GCM/foo_gcm/0000-0000"
+	msg := "Invalid parameter: Token Reason: Endpoint " + arn + " already exists with the same Token, but different attributes."
+	v, ok := existingEndpointARN(wrapError("CreatePlatformEndpoint", testAWSError{errCodeInvalidParameter, msg}))
+	assert.True(t, ok)
+	assert.Equal(t, arn, v)
+
+	_, ok = existingEndpointARN(wrapError("CreatePlatformEndpoint", testAWSError{errCodeInvalidParameter, "Invalid parameter: Token"}))
+	assert.False(t, ok)
+
+	_, ok = existingEndpointARN(wrapError("CreatePlatformEndpoint", testAWSError{"NotFound", msg}))
+	assert.False(t, ok)
+}
+
 func TestTruncateMessage(t *testing.T) {
 	str := "foobar"
 	msg := truncateMessage(str)
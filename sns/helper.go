@@ -3,6 +3,7 @@
 package sns
 
 import (
+	"encoding/json"
 	"fmt"
 )
 
@@ -11,6 +12,9 @@ const (
 	messageTemplateGCM       = `{"data": {"message": "%s"}}`
 	messageTemplateAPNS      = `{"aps":{"alert": "%s", "sound": "%s"}}`
 	messageTemplateAPNSBadge = `{"aps":{"alert": "%s", "sound": "%s", "badge": %d}}`
+
+	// key of the payload used for the alert message
+	payloadMessageKey = "message"
 )
 
 // make sns message for Google Cloud Messaging
@@ -34,3 +38,28 @@ func composeMessageAPNS(msg string, opt map[string]interface{}) string {
 		return fmt.Sprintf(messageTemplateAPNS, msg, "default")
 	}
 }
+
+// make sns message for Google Cloud Messaging from the payload data
+func composePayloadGCM(payload map[string]string) string {
+	b, _ := json.Marshal(map[string]interface{}{
+		"data": payload,
+	})
+	return string(b)
+}
+
+// make sns message for Apple Push Notification Service from the payload data,
+// the message of the payload is used for the alert and the others are set as custom data
+func composePayloadAPNS(payload map[string]string) string {
+	m := make(map[string]interface{}, len(payload)+1)
+	for k, v := range payload {
+		if k != payloadMessageKey {
+			m[k] = v
+		}
+	}
+	m["aps"] = map[string]string{
+		"alert": payload[payloadMessageKey],
+		"sound": "default",
+	}
+	b, _ := json.Marshal(m)
+	return string(b)
+}
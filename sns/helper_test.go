@@ -31,3 +31,16 @@ func TestComposeMessageAPNS(t *testing.T) {
 	assert.Equal(t, `{"aps":{"alert": "test", "sound": "jazz", "badge": 5}}`, msg)
 
 }
+
+func TestComposePayloadGCM(t *testing.T) {
+	msg := composePayloadGCM(map[string]string{"message": "test", "id": "1"})
+	assert.Equal(t, `{"data":{"id":"1","message":"test"}}`, msg)
+}
+
+func TestComposePayloadAPNS(t *testing.T) {
+	msg := composePayloadAPNS(map[string]string{"message": "test", "id": "1"})
+	assert.Equal(t, `{"aps":{"alert":"test","sound":"default"},"id":"1"}`, msg)
+
+	msg = composePayloadAPNS(map[string]string{"message": `"quoted"`})
+	assert.Equal(t, `{"aps":{"alert":"\"quoted\"","sound":"default"}}`, msg)
+}
@@ -5,6 +5,7 @@ package sns
 import (
 	SDK "github.com/awslabs/aws-sdk-go/service/sns"
 
+	"github.com/evalphobia/aws-sdk-go-wrapper/internal/pager"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 )
 
@@ -32,12 +33,62 @@ func (t *SNSTopic) Subscribe(endpoint *SNSEndpoint) (string, error) {
 		TopicARN: String(t.arn),
 	})
 	if err != nil {
+		err = wrapError("Subscribe", err)
 		log.Error("[SNS] error on `Subscribe` operation, topic="+t.arn, err.Error())
 		return "", err
 	}
 	return *resp.SubscriptionARN, nil
 }
 
+// SNSSubscription is the subscription information of the topic
+type SNSSubscription struct {
+	ARN      string
+	Protocol string
+	Endpoint string
+}
+
+// ListSubscriptions gets all of the subscriptions of the topic
+func (t *SNSTopic) ListSubscriptions() ([]SNSSubscription, error) {
+	var subs []SNSSubscription
+	in := &SDK.ListSubscriptionsByTopicInput{
+		TopicARN: String(t.arn),
+	}
+	err := pager.Paginate(func(token interface{}) (interface{}, bool, error) {
+		in.NextToken, _ = token.(*string)
+		resp, err := t.svc.Client.ListSubscriptionsByTopic(in)
+		if err != nil {
+			err = wrapError("ListSubscriptionsByTopic", err)
+			log.Error("[SNS] error on `ListSubscriptionsByTopic` operation, topic="+t.arn, err.Error())
+			return nil, false, err
+		}
+		for _, s := range resp.Subscriptions {
+			subs = append(subs, SNSSubscription{
+				ARN:      stringValue(s.SubscriptionARN),
+				Protocol: stringValue(s.Protocol),
+				Endpoint: stringValue(s.Endpoint),
+			})
+		}
+		next := resp.NextToken
+		return next, next == nil || *next == "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// Unsubscribe deletes the subscription from the topic
+func (t *SNSTopic) Unsubscribe(arn string) error {
+	_, err := t.svc.Client.Unsubscribe(&SDK.UnsubscribeInput{
+		SubscriptionARN: String(arn),
+	})
+	if err != nil {
+		err = wrapError("Unsubscribe", err)
+		log.Error("[SNS] error on `Unsubscribe` operation, subscription="+arn, err.Error())
+	}
+	return err
+}
+
 // Publish notification to the topic
 func (t *SNSTopic) Publish(msg string) error {
 	return t.svc.Publish(t.arn, msg, nil)
@@ -48,5 +99,5 @@ func (t *SNSTopic) Delete() error {
 	_, err := t.svc.Client.DeleteTopic(&SDK.DeleteTopicInput{
 		TopicARN: String(t.arn),
 	})
-	return err
+	return wrapError("DeleteTopic", err)
 }
@@ -31,6 +31,48 @@ func TestSubscribe(t *testing.T) {
 	assert.Contains(t, res, topicName)
 }
 
+func TestListSubscriptions(t *testing.T) {
+	setTestEnv()
+
+	topicName := "fooTopic"
+	svc := NewClient()
+	topic, _ := svc.CreateTopic(topicName)
+
+	e := NewEndpoint("arn", "application", svc)
+	arn, err := topic.Subscribe(e)
+	assert.Nil(t, err)
+
+	subs, err := topic.ListSubscriptions()
+	assert.Nil(t, err)
+	var found bool
+	for _, s := range subs {
+		if s.ARN == arn {
+			found = true
+			assert.Equal(t, "application", s.Protocol)
+			assert.Equal(t, "arn", s.Endpoint)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	setTestEnv()
+
+	topicName := "fooTopic"
+	svc := NewClient()
+	topic, _ := svc.CreateTopic(topicName)
+
+	e := NewEndpoint("arn", "application", svc)
+	arn, _ := topic.Subscribe(e)
+	err := topic.Unsubscribe(arn)
+	assert.Nil(t, err)
+
+	subs, _ := topic.ListSubscriptions()
+	for _, s := range subs {
+		assert.NotEqual(t, arn, s.ARN)
+	}
+}
+
 func TestTopicPublish(t *testing.T) {
 	setTestEnv()
 
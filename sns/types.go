@@ -5,3 +5,10 @@ package sns
 func String(v string) *string {
 	return &v
 }
+
+func stringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
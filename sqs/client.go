@@ -3,43 +3,80 @@
 package sqs
 
 import (
+	"errors"
+
+	AWS "github.com/awslabs/aws-sdk-go/aws"
 	SDK "github.com/awslabs/aws-sdk-go/service/sqs"
+	"golang.org/x/time/rate"
 
 	"github.com/evalphobia/aws-sdk-go-wrapper/auth"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/evalphobia/aws-sdk-go-wrapper/config"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 )
 
 const (
+	serviceName          = "SQS"
 	sqsConfigSectionName = "sqs"
 	defaultRegion        = "us-east-1"
 	defaultEndpoint      = "http://localhost:4568"
 	defaultQueuePrefix   = "devfs_"
+
+	errCodeNonExistentQueue  = "AWS.SimpleQueueService.NonExistentQueue"
+	errCodeQueueDoesNotExist = "QueueDoesNotExist"
 )
 
+// ErrQueueNotFound is matched by errors.Is when the queue does not exist or is deleted
+var ErrQueueNotFound = errors.New("[SQS] the queue does not exist")
+
 type AmazonSQS struct {
 	queues map[string]*Queue
 	client *SDK.SQS
+
+	// client-side rate limit of the requests
+	limiter auth.RateLimiterHolder
 }
 
 // Create new AmazonSQS struct
 func NewClient() *AmazonSQS {
-	svc := &AmazonSQS{}
-	svc.queues = make(map[string]*Queue)
-	region := config.GetConfigValue(sqsConfigSectionName, "region", auth.EnvRegion())
+	region, regionErr := auth.ResolveRegion(sqsConfigSectionName)
 	awsConf := auth.NewConfig(region)
 	endpoint := config.GetConfigValue(sqsConfigSectionName, "endpoint", "")
 	switch {
 	case endpoint != "":
 		awsConf.Endpoint = endpoint
 	case region == "":
+		log.Warn("[SQS] "+regionErr.Error()+", use the local endpoint", defaultEndpoint)
 		awsConf.Region = defaultRegion
 		awsConf.Endpoint = defaultEndpoint
 	}
+	return NewClientWithConfig(awsConf)
+}
+
+// NewClientWithConfig creates new AmazonSQS struct with the AWS config,
+// the region, the endpoint and the credentials of the config file are not used
+func NewClientWithConfig(awsConf *AWS.Config) *AmazonSQS {
+	svc := &AmazonSQS{}
+	svc.queues = make(map[string]*Queue)
 	svc.client = SDK.New(awsConf)
+	auth.AddUserAgentSuffix(&svc.client.Handlers, auth.UserAgentSuffix(sqsConfigSectionName))
+	svc.SetBackoff(auth.DefaultBackoff())
+	auth.AddRateLimiter(&svc.client.Handlers, svc.limiter.Get)
+	svc.SetRateLimiter(auth.ConfigRateLimiter(sqsConfigSectionName))
 	return svc
 }
 
+// SetBackoff sets the backoff of the retries by the SDK, nil keeps the current rules
+func (svc *AmazonSQS) SetBackoff(b *auth.Backoff) {
+	auth.SetRetryRules(svc.client.Service, b)
+}
+
+// SetRateLimiter sets the limiter to wait before sending every request of the client, nil means no limit.
+// share the limiter between the clients to limit the total requests
+func (svc *AmazonSQS) SetRateLimiter(l *rate.Limiter) {
+	svc.limiter.Set(l)
+}
+
 // Get a queue
 func (svc *AmazonSQS) GetQueue(queue string) (*Queue, error) {
 	queueName := GetQueuePrefix() + queue
@@ -56,6 +93,7 @@ func (svc *AmazonSQS) GetQueue(queue string) (*Queue, error) {
 		QueueOwnerAWSAccountID: nil,
 	})
 	if err != nil {
+		err = wrapError("GetQueueURL", err)
 		log.Error("[SQS] error on `GetQueueURL` operation, queue="+queueName, err.Error())
 		return nil, err
 	}
@@ -68,6 +106,7 @@ func (svc *AmazonSQS) GetQueue(queue string) (*Queue, error) {
 func (svc *AmazonSQS) CreateQueue(in *SDK.CreateQueueInput) error {
 	data, err := svc.client.CreateQueue(in)
 	if err != nil {
+		err = wrapError("CreateQueue", err)
 		log.Error("[SQS] Error on `CreateQueue` operation, queue="+*in.QueueName, err)
 		return err
 	}
@@ -79,3 +118,22 @@ func (svc *AmazonSQS) CreateQueue(in *SDK.CreateQueueInput) error {
 func GetQueuePrefix() string {
 	return config.GetConfigValue(sqsConfigSectionName, "prefix", defaultQueuePrefix)
 }
+
+// wrap the error of the operation with the request ID,
+// the error of non-existent queue matches ErrQueueNotFound by errors.Is
+func wrapError(op string, err error) error {
+	err = awserror.New(serviceName, op, err)
+	if e, ok := err.(*awserror.AWSError); ok && isQueueNotFound(e) {
+		e.Sentinel = ErrQueueNotFound
+	}
+	return err
+}
+
+// check if the error is caused by non-existent queue
+func isQueueNotFound(err error) bool {
+	switch awserror.Code(err) {
+	case errCodeNonExistentQueue, errCodeQueueDoesNotExist:
+		return true
+	}
+	return false
+}
@@ -1,10 +1,12 @@
 package sqs
 
 import (
+	"errors"
 	"testing"
 	"os"
 
 	SDK "github.com/awslabs/aws-sdk-go/service/sqs"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -48,6 +50,7 @@ func TestGetQueue(t *testing.T) {
 
 	q, err = svc.GetQueue("non_exist")
 	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, ErrQueueNotFound))
 	assert.Nil(t, q)
 
 	// cache
@@ -56,6 +59,28 @@ func TestGetQueue(t *testing.T) {
 	assert.NotNil(t, q)
 }
 
+type testAWSError struct {
+	code string
+}
+
+func (e testAWSError) Error() string   { return e.code + ": test error" }
+func (e testAWSError) Code() string    { return e.code }
+func (e testAWSError) Message() string { return "test error" }
+func (e testAWSError) OrigErr() error  { return nil }
+
+func TestWrapError(t *testing.T) {
+	assert.Nil(t, wrapError("ReceiveMessage", nil))
+
+	for _, code := range []string{errCodeNonExistentQueue, errCodeQueueDoesNotExist} {
+		err := wrapError("ReceiveMessage", testAWSError{code})
+		assert.True(t, errors.Is(err, ErrQueueNotFound))
+		assert.Equal(t, code, awserror.Code(err))
+	}
+
+	err := wrapError("ReceiveMessage", testAWSError{"InvalidParameterValue"})
+	assert.False(t, errors.Is(err, ErrQueueNotFound))
+}
+
 func TestGetQueuePrefix(t *testing.T) {
 	assert.Equal(t, defaultQueuePrefix, GetQueuePrefix())
 }
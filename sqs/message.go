@@ -0,0 +1,53 @@
+// SQS received message attributes
+
+package sqs
+
+import (
+	"strconv"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/sqs"
+)
+
+const (
+	attributeNameAll = "All"
+
+	attributeApproximateReceiveCount = "ApproximateReceiveCount"
+	attributeSentTimestamp           = "SentTimestamp"
+)
+
+// ReceiveCount returns ApproximateReceiveCount of the received message, 0 when the attribute is not received.
+// use this to route the message which is retried too many times to the dead letter queue
+func ReceiveCount(msg *SDK.Message) int {
+	v, ok := messageAttribute(msg, attributeApproximateReceiveCount)
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.Atoi(v)
+	return n
+}
+
+// SentAt returns SentTimestamp of the received message, zero time when the attribute is not received
+func SentAt(msg *SDK.Message) time.Time {
+	v, ok := messageAttribute(msg, attributeSentTimestamp)
+	if !ok {
+		return time.Time{}
+	}
+	msec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, msec*int64(time.Millisecond))
+}
+
+// get the system attribute of the message
+func messageAttribute(msg *SDK.Message, name string) (string, bool) {
+	if msg == nil || msg.Attributes == nil {
+		return "", false
+	}
+	v, ok := (*msg.Attributes)[name]
+	if !ok || v == nil {
+		return "", false
+	}
+	return *v, true
+}
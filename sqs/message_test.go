@@ -0,0 +1,38 @@
+package sqs
+
+import (
+	"testing"
+	"time"
+
+	SDK "github.com/awslabs/aws-sdk-go/service/sqs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiveCount(t *testing.T) {
+	msg := &SDK.Message{
+		Attributes: &map[string]*string{
+			attributeApproximateReceiveCount: String("3"),
+		},
+	}
+	assert.Equal(t, 3, ReceiveCount(msg))
+	assert.Equal(t, 0, ReceiveCount(&SDK.Message{}))
+	assert.Equal(t, 0, ReceiveCount(nil))
+}
+
+func TestSentAt(t *testing.T) {
+	msg := &SDK.Message{
+		Attributes: &map[string]*string{
+			attributeSentTimestamp: String("1445000000123"),
+		},
+	}
+	assert.Equal(t, time.Unix(1445000000, 123*int64(time.Millisecond)), SentAt(msg))
+	assert.True(t, SentAt(&SDK.Message{}).IsZero())
+
+	msg = &SDK.Message{
+		Attributes: &map[string]*string{
+			attributeSentTimestamp: String("invalid"),
+		},
+	}
+	assert.True(t, SentAt(msg).IsZero())
+}
@@ -5,6 +5,7 @@ package sqs
 import (
 	AWS "github.com/awslabs/aws-sdk-go/aws"
 	SDK "github.com/awslabs/aws-sdk-go/service/sqs"
+	"github.com/evalphobia/aws-sdk-go-wrapper/awserror"
 	"github.com/evalphobia/aws-sdk-go-wrapper/log"
 
 	"encoding/json"
@@ -16,6 +17,8 @@ import (
 const (
 	defaultMessageIDPrefix = "msg_"
 	defaultExpireSecond    = 180
+
+	errCodePurgeQueueInProgress = "AWS.SimpleQueueService.PurgeQueueInProgress"
 )
 
 // SQS Queue wrapper struct
@@ -27,9 +30,10 @@ type Queue struct {
 	failedSend   []*SDK.BatchResultErrorEntry
 	failedDelete []*SDK.BatchResultErrorEntry
 
-	autoDel bool
-	expire  int
-	client  *SDK.SQS
+	autoDel    bool
+	forcePurge bool
+	expire     int
+	client     *SDK.SQS
 }
 
 func NewQueue(name string, url *string, cli *SDK.SQS) *Queue {
@@ -47,6 +51,12 @@ func (q *Queue) AutoDelete(b bool) {
 	q.autoDel = b
 }
 
+// Set force purge flag to this queue,
+// Purge() deletes the messages one by one when the previous purge is in progress
+func (q *Queue) ForcePurge(b bool) {
+	q.forcePurge = b
+}
+
 // Set visibility timeout for message
 func (q *Queue) SetExpire(sec int) {
 	q.expire = sec
@@ -107,8 +117,11 @@ func (q *Queue) send(msg []*SDK.SendMessageBatchRequestEntry) error {
 		Entries:  msg,
 		QueueURL: q.url,
 	})
+	if err != nil {
+		return wrapError("SendMessageBatch", err)
+	}
 	q.failedSend = append(q.failedSend, res.Failed...)
-	return err
+	return nil
 }
 
 // Get message from the queue with limit
@@ -127,8 +140,10 @@ func (q *Queue) Fetch(num int) (*SDK.ReceiveMessageOutput, error) {
 		WaitTimeSeconds:     Long(wait),
 		MaxNumberOfMessages: Long(num),
 		VisibilityTimeout:   Long(defaultExpireSecond),
+		AttributeNames:      []*string{String(attributeNameAll)},
 	})
 	if err != nil {
+		err = wrapError("ReceiveMessage", err)
 		log.Error("[SQS] error on `ReceiveMessage` operation, queue="+q.name, err.Error())
 	}
 
@@ -208,6 +223,7 @@ func (q *Queue) DeleteMessage(msg *SDK.Message) error {
 		ReceiptHandle: msg.ReceiptHandle,
 	})
 	if err != nil {
+		err = wrapError("DeleteMessage", err)
 		log.Error("[SQS] error on `DeleteMessage`, queue="+q.name, err.Error())
 	}
 	return err
@@ -252,6 +268,7 @@ func (q *Queue) delete(msg []*SDK.DeleteMessageBatchRequestEntry) error {
 		QueueURL: q.url,
 	})
 	if err != nil {
+		err = wrapError("DeleteMessageBatch", err)
 		log.Error("[SQS] error on `DeleteMessageBatch`, queue="+q.name, err.Error())
 		q.failedDelete = append(q.failedDelete, res.Failed...)
 	}
@@ -269,6 +286,7 @@ func (q *Queue) CountMessage() (int, int, error) {
 		},
 	})
 	if err != nil {
+		err = wrapError("GetQueueAttributes", err)
 		log.Error("[SQS] error on `GetQueueAttributes`, queue="+q.name, err.Error())
 		return 0, 0, err
 	}
@@ -278,14 +296,58 @@ func (q *Queue) CountMessage() (int, int, error) {
 	return visible, invisible, nil
 }
 
-// Delete all messages on the Queue
+// Delete all messages on the Queue,
+// PurgeQueue can be executed only once in 60 seconds for each queue
 func (q *Queue) Purge() error {
 	_, err := q.client.PurgeQueue(&SDK.PurgeQueueInput{
 		QueueURL: q.url,
 	})
-	if err != nil {
-		log.Error("[SQS] error on `PurgeQueue`, queue="+q.name, err.Error())
-		return err
+	err = wrapError("PurgeQueue", err)
+	switch {
+	case err == nil:
+		return nil
+	case isPurgeInProgress(err) && q.forcePurge:
+		log.Warn("[SQS] purge is in progress, delete messages one by one, queue="+q.name, err.Error())
+		return q.drain()
+	case isPurgeInProgress(err):
+		err = errors.New("[SQS] the queue can be purged only once in 60 seconds, queue=" + q.name + ", " + err.Error())
+	}
+	log.Error("[SQS] error on `PurgeQueue`, queue="+q.name, err.Error())
+	return err
+}
+
+// check if the error is caused by the previous purge in 60 seconds
+func isPurgeInProgress(err error) bool {
+	return awserror.Code(err) == errCodePurgeQueueInProgress
+}
+
+// Receive and delete all messages on the Queue
+func (q *Queue) drain() error {
+	for {
+		resp, err := q.client.ReceiveMessage(&SDK.ReceiveMessageInput{
+			QueueURL:            q.url,
+			WaitTimeSeconds:     Long(1),
+			MaxNumberOfMessages: Long(10),
+			VisibilityTimeout:   Long(defaultExpireSecond),
+		})
+		if err != nil {
+			err = wrapError("ReceiveMessage", err)
+			log.Error("[SQS] error on `ReceiveMessage` operation, queue="+q.name, err.Error())
+			return err
+		}
+		if len(resp.Messages) == 0 {
+			return nil
+		}
+
+		var entries []*SDK.DeleteMessageBatchRequestEntry
+		for _, m := range resp.Messages {
+			entries = append(entries, &SDK.DeleteMessageBatchRequestEntry{
+				ID:            m.MessageID,
+				ReceiptHandle: m.ReceiptHandle,
+			})
+		}
+		if err := q.delete(entries); err != nil {
+			return err
+		}
 	}
-	return nil
 }
@@ -317,3 +317,22 @@ func TestPurge(t *testing.T) {
 	assert.Equal(t, 0, visible2)
 	assert.Equal(t, 0, invisible2)
 }
+
+func TestForcePurge(t *testing.T) {
+	setTestEnv()
+	createQueue("test")
+
+	svc := NewClient()
+	q, _ := svc.GetQueue("test")
+	cleanQueue(q)
+	addTestMessage(q, 3)
+
+	// drain the messages
+	q.ForcePurge(true)
+	err := q.drain()
+	assert.Nil(t, err)
+
+	visible, invisible, _ := q.CountMessage()
+	assert.Equal(t, 0, visible)
+	assert.Equal(t, 0, invisible)
+}